@@ -7,6 +7,9 @@ import (
 
 func main() {
 	conf := config.Load()
-	app, _ := di.InitializeApiWebServer(conf)
+	app, err := di.InitializeApiWebServer(conf)
+	if err != nil {
+		panic(err.Error())
+	}
 	app.Serve()
 }
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"github.bumble.dev/shcherbanich/user-votes-storage/config"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/app/di"
 	"os/signal"
@@ -15,6 +16,19 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	// in the stream mode the counters are derived from the romances stream next to the messages
+	if conf.Counters.UpdateMode.IsStream() {
+		streamConsumer, err := di.InitializeCountersStreamConsumer(conf)
+		if err != nil {
+			panic(err.Error())
+		}
+		go func() {
+			if err := streamConsumer.Start(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				panic(err.Error())
+			}
+		}()
+	}
+
 	if err := worker.Start(ctx); err != nil {
 		panic(err.Error())
 	}
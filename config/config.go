@@ -1,25 +1,175 @@
 package config
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/timeutil"
 	env "github.com/caarlos0/env/v10"
+	"github.com/google/uuid"
+	"time"
 )
 
 const (
-	CountersTtlHours                    = 48
-	ProjectName                         = "User Votes Storage"
-	ProjectVersion                      = "1.0.0"
-	DynamoDbVersionConflictRetriesCount = 3
+	CountersTtlHours                     = 48
+	QuotasTtlDays                        = 2
+	ProjectName                          = "User Votes Storage"
+	ProjectVersion                       = "1.0.0"
+	DynamoDbVersionConflictRetriesCount  = 3
+	DynamoDbUnprocessedItemsRetriesCount = 5
+	BatchVotesConcurrency                = 8
+	CountersMaxShards                    = 100
 )
 
 type RomancesConfig struct {
 	MutualRomanceTtlSeconds    int64
 	NonMutualRomanceTtlSeconds int64
 	DeadRomanceTtlSeconds      int64
+	BlockedRomanceTtlSeconds   int64
 }
 
 type CountersConfig struct {
-	TtlSeconds int64
+	TtlSeconds           int64
+	DailyRetentionDays   int                  `env:"COUNTERS_DAILY_RETENTION_DAYS" envDefault:"400"`
+	WeeklyRetentionWeeks int                  `env:"COUNTERS_WEEKLY_RETENTION_WEEKS" envDefault:"104"`
+	Shards               CountersShardsConfig `env:"COUNTERS_SHARDS"`
+	Buffer               CountersBufferConfig
+	UpdateMode           CountersUpdateMode `env:"COUNTERS_UPDATE_MODE" envDefault:"inline"`
+	Stream               CountersStreamConfig
+}
+
+// CountersStreamConfig sets up the consumer of the Romances stream which keeps the counters in the stream mode.
+type CountersStreamConfig struct {
+	Region       string        `env:"COUNTERS_STREAM_REGION"`
+	PollInterval time.Duration `env:"COUNTERS_STREAM_POLL_INTERVAL" envDefault:"1s"`
+}
+
+func (c CountersStreamConfig) RegionOrDefault(defaultRegion string) string {
+	if c.Region != "" {
+		return c.Region
+	}
+	return defaultRegion
+}
+
+// CountersUpdateMode tells whether the counters of a new vote are written in the request or by the message processor.
+type CountersUpdateMode string
+
+const (
+	CountersUpdateModeInline CountersUpdateMode = "inline"
+	CountersUpdateModeAsync  CountersUpdateMode = "async"
+	CountersUpdateModeStream CountersUpdateMode = "stream"
+)
+
+func (m *CountersUpdateMode) UnmarshalText(text []byte) error {
+	mode := CountersUpdateMode(text)
+	if mode != CountersUpdateModeInline && mode != CountersUpdateModeAsync && mode != CountersUpdateModeStream {
+		return fmt.Errorf(
+			"invalid counters update mode: %q (must be %q, %q or %q)",
+			mode, CountersUpdateModeInline, CountersUpdateModeAsync, CountersUpdateModeStream,
+		)
+	}
+
+	*m = mode
+	return nil
+}
+
+func (m CountersUpdateMode) IsAsync() bool {
+	return m == CountersUpdateModeAsync
+}
+
+func (m CountersUpdateMode) IsStream() bool {
+	return m == CountersUpdateModeStream
+}
+
+// CountersBufferConfig sets up the write-behind buffer of the counters increments.
+type CountersBufferConfig struct {
+	Enabled       bool          `env:"COUNTERS_BUFFER_ENABLED" envDefault:"false"`
+	FlushInterval time.Duration `env:"COUNTERS_BUFFER_FLUSH_INTERVAL" envDefault:"5s"`
+	MaxSize       int           `env:"COUNTERS_BUFFER_MAX_SIZE" envDefault:"1000"`
+}
+
+// CountersShardsConfig sets the number of the items the counters of a user are spread over.
+type CountersShardsConfig struct {
+	Default uint16               `json:"default"`
+	Users   map[uuid.UUID]uint16 `json:"users"`
+}
+
+// UnmarshalText merges the JSON from the environment into the defaults.
+func (c *CountersShardsConfig) UnmarshalText(text []byte) error {
+	type plainCountersShardsConfig CountersShardsConfig
+	if err := json.Unmarshal(text, (*plainCountersShardsConfig)(c)); err != nil {
+		return err
+	}
+
+	if c.Default < 1 || c.Default > CountersMaxShards {
+		return fmt.Errorf("invalid default counters shards: %d (must be 1-%d)", c.Default, CountersMaxShards)
+	}
+	for userId, shards := range c.Users {
+		if shards < 1 || shards > CountersMaxShards {
+			return fmt.Errorf("invalid counters shards of user %s: %d (must be 1-%d)", userId, shards, CountersMaxShards)
+		}
+	}
+
+	return nil
+}
+
+func (c CountersShardsConfig) UserShards(userId uuid.UUID) uint16 {
+	if shards, ok := c.Users[userId]; ok {
+		return shards
+	}
+	return c.Default
+}
+
+// VoteTransitionRules lists for every current vote type the vote types it may be changed to.
+type VoteTransitionRules map[string][]string
+
+// VoteTransitionsConfig holds the default vote transitions and the per country overrides.
+type VoteTransitionsConfig struct {
+	Default   VoteTransitionRules            `json:"default"`
+	Countries map[uint16]VoteTransitionRules `json:"countries"`
+}
+
+// UnmarshalText merges the JSON from the environment into the defaults.
+func (c *VoteTransitionsConfig) UnmarshalText(text []byte) error {
+	type plainVoteTransitionsConfig VoteTransitionsConfig
+	return json.Unmarshal(text, (*plainVoteTransitionsConfig)(c))
+}
+
+// VoteQuotaLimits maps a vote type to the number of votes of the type a user may add per UTC day.
+type VoteQuotaLimits map[string]uint32
+
+// VoteQuotasConfig holds the default daily limits and the per country overrides.
+type VoteQuotasConfig struct {
+	Default    VoteQuotaLimits            `json:"default"`
+	Countries  map[uint16]VoteQuotaLimits `json:"countries"`
+	TtlSeconds int64                      `json:"-"`
+}
+
+// UnmarshalText merges the JSON from the environment into the defaults.
+func (c *VoteQuotasConfig) UnmarshalText(text []byte) error {
+	type plainVoteQuotasConfig VoteQuotasConfig
+	return json.Unmarshal(text, (*plainVoteQuotasConfig)(c))
+}
+
+// DynamoDbRegionConfig describes the client of a DynamoDB region the service keeps data in.
+type DynamoDbRegionConfig struct {
+	Endpoint        string `json:"endpoint,omitempty"`
+	AccessKeyId     string `json:"access_key_id,omitempty"`
+	SecretAccessKey string `json:"secret_access_key,omitempty"`
+	MaxAttempts     int    `json:"max_attempts,omitempty"`
+}
+
+// DynamoDbRoutingConfig maps countries to the DynamoDB regions holding their data.
+type DynamoDbRoutingConfig struct {
+	DefaultRegion  string                          `json:"default_region"`
+	Countries      map[uint16]string               `json:"countries"`
+	Regions        map[string]DynamoDbRegionConfig `json:"regions"`
+	RegionPriority []string                        `json:"region_priority"`
+}
+
+// UnmarshalText merges the JSON from the environment into the defaults.
+func (c *DynamoDbRoutingConfig) UnmarshalText(text []byte) error {
+	type plainDynamoDbRoutingConfig DynamoDbRoutingConfig
+	return json.Unmarshal(text, (*plainDynamoDbRoutingConfig)(c))
 }
 
 type Config struct {
@@ -32,24 +182,57 @@ type Config struct {
 		DynamoDbLocalEndpoint string `env:"DYNAMO_DB_ENDPOINT"`
 		SnsLocalEndpoint      string `env:"SNS_DB_ENDPOINT"`
 	}
-	Counters CountersConfig
-	Romances RomancesConfig
+	DynamoDbRouting DynamoDbRoutingConfig `env:"DYNAMO_DB_ROUTING"`
+	Counters        CountersConfig
+	Romances        RomancesConfig
+	VoteTransitions VoteTransitionsConfig `env:"VOTE_TRANSITIONS"`
+	VoteQuotas      VoteQuotasConfig      `env:"VOTE_QUOTAS"`
 }
 
 type ServerOptions struct {
-	Host string `doc:"Hostname to listen on." default:"0.0.0.0"`
-	Port int    `doc:"Port to listen on." short:"p" default:"8888"`
+	Host      string `doc:"Hostname to listen on." default:"0.0.0.0"`
+	Port      int    `doc:"Port to listen on." short:"p" default:"8888"`
+	AdminHost string `doc:"Hostname to serve the internal metrics on." default:"127.0.0.1"`
+	AdminPort int    `doc:"Port to serve the internal metrics on." default:"8889"`
 }
 
 func Load() Config {
 	cfg := Config{
+		DynamoDbRouting: DynamoDbRoutingConfig{
+			DefaultRegion: "us-east-2",
+			Countries:     map[uint16]string{},
+			Regions: map[string]DynamoDbRegionConfig{
+				"us-east-2": {},
+			},
+		},
 		Counters: CountersConfig{
 			TtlSeconds: CountersTtlHours * timeutil.HourSeconds,
+			UpdateMode: CountersUpdateModeInline,
+			Shards: CountersShardsConfig{
+				Default: 1,
+				Users:   map[uuid.UUID]uint16{},
+			},
 		},
 		Romances: RomancesConfig{
 			MutualRomanceTtlSeconds:    546 * timeutil.DaySeconds,
 			NonMutualRomanceTtlSeconds: 180 * timeutil.DaySeconds,
 			DeadRomanceTtlSeconds:      90 * timeutil.DaySeconds,
+			BlockedRomanceTtlSeconds:   546 * timeutil.DaySeconds,
+		},
+		VoteTransitions: VoteTransitionsConfig{
+			Default: VoteTransitionRules{
+				"empty":      {"yes", "no", "crush", "compliment"},
+				"no":         {"yes", "crush", "compliment", "empty"},
+				"yes":        {"crush", "compliment", "empty"},
+				"crush":      {"empty"},
+				"compliment": {"empty"},
+			},
+			Countries: map[uint16]VoteTransitionRules{},
+		},
+		VoteQuotas: VoteQuotasConfig{
+			Default:    VoteQuotaLimits{},
+			Countries:  map[uint16]VoteQuotaLimits{},
+			TtlSeconds: QuotasTtlDays * timeutil.DaySeconds,
 		},
 	}
 	if err := env.Parse(&cfg); err != nil {
@@ -13,16 +13,43 @@ ${AWS_BASE} dynamodb update-time-to-live \
   --table-name Counters \
   --time-to-live-specification "Enabled=true, AttributeName=ttl"
 
+${AWS_BASE} dynamodb create-table \
+--table-name Quotas \
+--attribute-definitions AttributeName=u,AttributeType=S AttributeName=d,AttributeType=N \
+--key-schema AttributeName=u,KeyType=HASH AttributeName=d,KeyType=RANGE \
+--provisioned-throughput ReadCapacityUnits=1000,WriteCapacityUnits=1000
+
+${AWS_BASE} dynamodb update-time-to-live \
+  --table-name Quotas \
+  --time-to-live-specification "Enabled=true, AttributeName=ttl"
+
 ${AWS_BASE} dynamodb create-table \
 --table-name Romances \
---attribute-definitions AttributeName=a,AttributeType=S AttributeName=b,AttributeType=S \
+--stream-specification StreamEnabled=true,StreamViewType=NEW_AND_OLD_IMAGES \
+--attribute-definitions AttributeName=a,AttributeType=S AttributeName=b,AttributeType=S AttributeName=m,AttributeType=N AttributeName=c,AttributeType=N AttributeName=d,AttributeType=N \
 --key-schema AttributeName=a,KeyType=HASH AttributeName=b,KeyType=RANGE \
 --provisioned-throughput ReadCapacityUnits=10000,WriteCapacityUnits=2400 \
 --global-secondary-indexes '[
 {"IndexName":"gsiByMaxMinUser",
 "KeySchema":[{"AttributeName":"b","KeyType":"HASH"},{"AttributeName":"a","KeyType":"RANGE"}],
 "Projection":{"ProjectionType":"KEYS_ONLY"},
-"ProvisionedThroughput":{"ReadCapacityUnits":100,"WriteCapacityUnits":1300}}]'
+"ProvisionedThroughput":{"ReadCapacityUnits":100,"WriteCapacityUnits":1300}},
+{"IndexName":"gsiMatchesByPkUser",
+"KeySchema":[{"AttributeName":"a","KeyType":"HASH"},{"AttributeName":"m","KeyType":"RANGE"}],
+"Projection":{"ProjectionType":"ALL"},
+"ProvisionedThroughput":{"ReadCapacityUnits":100,"WriteCapacityUnits":100}},
+{"IndexName":"gsiMatchesBySkUser",
+"KeySchema":[{"AttributeName":"b","KeyType":"HASH"},{"AttributeName":"m","KeyType":"RANGE"}],
+"Projection":{"ProjectionType":"ALL"},
+"ProvisionedThroughput":{"ReadCapacityUnits":100,"WriteCapacityUnits":100}},
+{"IndexName":"gsiLikesByPkUser",
+"KeySchema":[{"AttributeName":"a","KeyType":"HASH"},{"AttributeName":"c","KeyType":"RANGE"}],
+"Projection":{"ProjectionType":"ALL"},
+"ProvisionedThroughput":{"ReadCapacityUnits":100,"WriteCapacityUnits":100}},
+{"IndexName":"gsiLikesBySkUser",
+"KeySchema":[{"AttributeName":"b","KeyType":"HASH"},{"AttributeName":"d","KeyType":"RANGE"}],
+"Projection":{"ProjectionType":"ALL"},
+"ProvisionedThroughput":{"ReadCapacityUnits":100,"WriteCapacityUnits":100}}]'
 
 ${AWS_BASE} dynamodb update-time-to-live \
   --table-name Romances \
@@ -32,5 +59,11 @@ echo "DynamoDB tables ready."
 
 ${AWS_BASE} sns create-topic --name delete-romances
 ${AWS_BASE} sqs create-queue --queue-name delete-romances-queue
+${AWS_BASE} sns create-topic --name reconcile-counters
+${AWS_BASE} sqs create-queue --queue-name reconcile-counters-queue
+${AWS_BASE} sns create-topic --name vote-counted
+${AWS_BASE} sqs create-queue --queue-name vote-counted-queue
+${AWS_BASE} sns create-topic --name backfill-romance-indexes
+${AWS_BASE} sqs create-queue --queue-name backfill-romance-indexes-queue
 
 echo "SNS ready."
\ No newline at end of file
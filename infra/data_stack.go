@@ -50,16 +50,41 @@ func NewDataStack(scope constructs.Construct, id string, props *DataStackProps)
 		PartitionKey: &awsdynamodb.Attribute{Name: jsii.String(persistence.PkUserIdAttrName), Type: awsdynamodb.AttributeType_STRING},
 		SortKey:      &awsdynamodb.Attribute{Name: jsii.String(persistence.SkUserIdAttrName), Type: awsdynamodb.AttributeType_STRING},
 		BillingMode:  awsdynamodb.BillingMode_PAY_PER_REQUEST,
+		Stream:       awsdynamodb.StreamViewType_NEW_AND_OLD_IMAGES,
 	})
 	cfnRomances := romancesTbl.Node().DefaultChild().(awscdk.CfnResource)
 	cfnRomances.AddOverride(jsii.String("Properties.TimeToLiveSpecification"),
 		map[string]interface{}{"Enabled": true, "AttributeName": "ttl"})
 	romancesTbl.AddGlobalSecondaryIndex(&awsdynamodb.GlobalSecondaryIndexProps{
-		IndexName:      jsii.String("gsiByMaxMinUser"),
+		IndexName:      jsii.String(persistence.RomancesByMaxMinUserIndexName),
 		PartitionKey:   &awsdynamodb.Attribute{Name: jsii.String(persistence.SkUserIdAttrName), Type: awsdynamodb.AttributeType_STRING},
 		SortKey:        &awsdynamodb.Attribute{Name: jsii.String(persistence.PkUserIdAttrName), Type: awsdynamodb.AttributeType_STRING},
 		ProjectionType: awsdynamodb.ProjectionType_KEYS_ONLY,
 	})
+	romancesTbl.AddGlobalSecondaryIndex(&awsdynamodb.GlobalSecondaryIndexProps{
+		IndexName:      jsii.String(persistence.RomancesMatchesByPkUserIndexName),
+		PartitionKey:   &awsdynamodb.Attribute{Name: jsii.String(persistence.PkUserIdAttrName), Type: awsdynamodb.AttributeType_STRING},
+		SortKey:        &awsdynamodb.Attribute{Name: jsii.String(persistence.MatchedAtAttrName), Type: awsdynamodb.AttributeType_NUMBER},
+		ProjectionType: awsdynamodb.ProjectionType_ALL,
+	})
+	romancesTbl.AddGlobalSecondaryIndex(&awsdynamodb.GlobalSecondaryIndexProps{
+		IndexName:      jsii.String(persistence.RomancesMatchesBySkUserIndexName),
+		PartitionKey:   &awsdynamodb.Attribute{Name: jsii.String(persistence.SkUserIdAttrName), Type: awsdynamodb.AttributeType_STRING},
+		SortKey:        &awsdynamodb.Attribute{Name: jsii.String(persistence.MatchedAtAttrName), Type: awsdynamodb.AttributeType_NUMBER},
+		ProjectionType: awsdynamodb.ProjectionType_ALL,
+	})
+	romancesTbl.AddGlobalSecondaryIndex(&awsdynamodb.GlobalSecondaryIndexProps{
+		IndexName:      jsii.String(persistence.RomancesLikesByPkUserIndexName),
+		PartitionKey:   &awsdynamodb.Attribute{Name: jsii.String(persistence.PkUserIdAttrName), Type: awsdynamodb.AttributeType_STRING},
+		SortKey:        &awsdynamodb.Attribute{Name: jsii.String(persistence.PkUserLikedAtAttrName), Type: awsdynamodb.AttributeType_NUMBER},
+		ProjectionType: awsdynamodb.ProjectionType_ALL,
+	})
+	romancesTbl.AddGlobalSecondaryIndex(&awsdynamodb.GlobalSecondaryIndexProps{
+		IndexName:      jsii.String(persistence.RomancesLikesBySkUserIndexName),
+		PartitionKey:   &awsdynamodb.Attribute{Name: jsii.String(persistence.SkUserIdAttrName), Type: awsdynamodb.AttributeType_STRING},
+		SortKey:        &awsdynamodb.Attribute{Name: jsii.String(persistence.SkUserLikedAtAttrName), Type: awsdynamodb.AttributeType_NUMBER},
+		ProjectionType: awsdynamodb.ProjectionType_ALL,
+	})
 	romances = romancesTbl
 
 	if props != nil && props.GrantRwToRole != nil {
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+)
+
+type customMethodMux struct {
+	*http.ServeMux
+	routes map[string]*customMethodRoute
+}
+
+type customMethodRoute struct {
+	wildcard string
+	plain    http.HandlerFunc
+	methods  map[string]http.HandlerFunc
+}
+
+func newCustomMethodMux() *customMethodMux {
+	return &customMethodMux{
+		ServeMux: http.NewServeMux(),
+		routes:   map[string]*customMethodRoute{},
+	}
+}
+
+func (m *customMethodMux) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	basePattern, wildcard, customMethod, ok := splitCustomMethodPattern(pattern)
+	if !ok {
+		m.ServeMux.HandleFunc(pattern, handler)
+		return
+	}
+
+	route, exists := m.routes[basePattern]
+	if !exists {
+		route = &customMethodRoute{wildcard: wildcard, methods: map[string]http.HandlerFunc{}}
+		m.routes[basePattern] = route
+		m.ServeMux.HandleFunc(basePattern, route.serveHTTP)
+	}
+
+	if customMethod == "" {
+		route.plain = handler
+	} else {
+		route.methods[customMethod] = handler
+	}
+}
+
+func (r *customMethodRoute) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	value := req.PathValue(r.wildcard)
+	if i := strings.LastIndexByte(value, ':'); i != -1 {
+		if handler, ok := r.methods[value[i+1:]]; ok {
+			req.SetPathValue(r.wildcard, value[:i])
+			handler(w, req)
+			return
+		}
+	}
+
+	if r.plain == nil {
+		http.NotFound(w, req)
+		return
+	}
+	r.plain(w, req)
+}
+
+func splitCustomMethodPattern(pattern string) (string, string, string, bool) {
+	segmentStart := strings.LastIndexByte(pattern, '/') + 1
+	segment := pattern[segmentStart:]
+	if !strings.HasPrefix(segment, "{") {
+		return "", "", "", false
+	}
+
+	wildcardEnd := strings.IndexByte(segment, '}')
+	if wildcardEnd == -1 || strings.HasSuffix(segment[:wildcardEnd], "...") {
+		return "", "", "", false
+	}
+
+	customMethod := ""
+	if rest := segment[wildcardEnd+1:]; rest != "" {
+		if !strings.HasPrefix(rest, ":") || len(rest) == 1 {
+			return "", "", "", false
+		}
+		customMethod = rest[1:]
+	}
+
+	return pattern[:segmentStart+wildcardEnd+1], segment[1:wildcardEnd], customMethod, true
+}
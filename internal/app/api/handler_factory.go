@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"expvar"
 	"github.bumble.dev/shcherbanich/user-votes-storage/config"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/app/api/response"
 	votingV1 "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/interface/api/rest/v1"
@@ -23,7 +24,7 @@ func NewHandlerFactory(
 }
 
 func (s HandlerFactory) NewHumaApiServerHandler() http.Handler {
-	handler := http.NewServeMux()
+	handler := newCustomMethodMux()
 	api := humago.New(handler, huma.DefaultConfig(config.ProjectName, config.ProjectVersion))
 	grp := huma.NewGroup(api, "/v1")
 
@@ -36,6 +37,13 @@ func (s HandlerFactory) NewHumaApiServerHandler() http.Handler {
 	return handler
 }
 
+// NewAdminHandler serves the internal metrics, it must not be exposed on the API port.
+func (s HandlerFactory) NewAdminHandler() http.Handler {
+	handler := http.NewServeMux()
+	handler.Handle("GET /debug/vars", expvar.Handler())
+	return handler
+}
+
 func (s HandlerFactory) registerHealthCheck(api huma.API) {
 	huma.Register(api, huma.Operation{
 		Method:  http.MethodGet,
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"context"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/application/operation"
+	romancesRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/repository"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
+)
+
+// CountersStreamConsumer keeps the counters in the stream mode by projecting the changes of the romances onto them.
+type CountersStreamConsumer struct {
+	romanceChangesReader            romancesRepo.RomanceChangesReader
+	projectRomanceCountersOperation operation.ProjectRomanceCountersOperation
+	logger                          platform.Logger
+}
+
+func NewCountersStreamConsumer(
+	romanceChangesReader romancesRepo.RomanceChangesReader,
+	projectRomanceCountersOperation operation.ProjectRomanceCountersOperation,
+	logger platform.Logger,
+) *CountersStreamConsumer {
+	return &CountersStreamConsumer{
+		romanceChangesReader:            romanceChangesReader,
+		projectRomanceCountersOperation: projectRomanceCountersOperation,
+		logger:                          logger,
+	}
+}
+
+func (s *CountersStreamConsumer) Start(ctx context.Context) error {
+	s.logger.Info("Reading the romances stream")
+	return s.romanceChangesReader.Read(ctx, s.projectRomanceCountersOperation.Run)
+}
@@ -10,7 +10,10 @@ import (
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/application/messaging/handler"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/application/operation"
 	countersRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/repository"
+	quotasRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota/repository"
+	quotaValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota/valueobject"
 	romancesRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/repository"
+	romancesValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/infrastructure/persistence"
 	storageV1 "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/interface/api/rest/v1"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/messaging"
@@ -22,31 +25,67 @@ import (
 
 var PlatformSet = wire.NewSet(
 	platform.NewLogger,
+	platform.NewMetrics,
+	platform.NewClock,
+)
+
+var PoliciesSet = wire.NewSet(
+	romancesValueObject.NewVoteTransitionPolicy,
+	quotaValueObject.NewQuotaPolicy,
 )
 
 var ReposSet = wire.NewSet(
-	dynamodb.NewDynamoDbClient,
+	dynamodb.NewClientPool,
+	wire.Bind(new(dynamodb.Client), new(*dynamodb.ClientPool)),
+	dynamodb.NewRegionRouter,
 	persistence.NewRomancesRepository,
-	persistence.NewCountersRepository,
+	persistence.NewQuotasRepository,
 	wire.Bind(new(romancesRepo.RomancesRepository), new(*persistence.RomancesRepository)),
+	wire.Bind(new(quotasRepo.QuotasRepository), new(*persistence.QuotasRepository)),
+)
+
+// BufferedCountersSet holds the counters increments back in the buffer.
+var BufferedCountersSet = wire.NewSet(
+	persistence.NewCountersRepository,
+	persistence.NewBufferedCountersRepository,
+	wire.Bind(new(countersRepo.CountersRepository), new(*persistence.BufferedCountersRepository)),
+	wire.Bind(new(countersRepo.CountersBuffer), new(*persistence.BufferedCountersRepository)),
+)
+
+// CountersSet writes the counters right away.
+var CountersSet = wire.NewSet(
+	persistence.NewCountersRepository,
 	wire.Bind(new(countersRepo.CountersRepository), new(*persistence.CountersRepository)),
 )
 
 func InitializeApiWebServer(config config.Config) (*app.ApiWebServer, error) {
 	wire.Build(
 		PlatformSet,
+		PoliciesSet,
 		ReposSet,
+		BufferedCountersSet,
 		amazon_sns.NewSnsPublisher,
 		wire.Bind(new(messaging.Publisher), new(*amazon_sns.SnsPublisher)),
 		operation.NewGetRomanceOperation,
+		operation.NewGetRomancesOperation,
 		operation.NewDeleteRomanceOperation,
+		operation.NewBlockRomanceOperation,
+		operation.NewUnblockRomanceOperation,
 		operation.NewGetUserVoteOperation,
 		operation.NewAddUserVoteOperation,
+		operation.NewAddUserVotesOperation,
 		operation.NewChangeUserVoteOperation,
 		operation.NewDeleteUserVoteOperation,
 		operation.NewGetLifetimeCountersOperation,
 		operation.NewGetHourlyCountersOperation,
+		operation.NewGetHourlyCountersSeriesOperation,
+		operation.NewGetPeriodCountersOperation,
+		operation.NewGetDailyQuotasOperation,
 		operation.NewDeleteRomancesOperation,
+		operation.NewRequestCountersReconciliationOperation,
+		operation.NewListRomancesOperation,
+		operation.NewListMatchesOperation,
+		operation.NewListLikesOperation,
 		application.NewVotingService,
 		storageV1.NewVotesStorageRoutsRegister,
 		api.NewHandlerFactory,
@@ -58,10 +97,31 @@ func InitializeApiWebServer(config config.Config) (*app.ApiWebServer, error) {
 func InitializeMessageProcessor(config config.Config) (*app.MessageProcessor, error) {
 	wire.Build(
 		PlatformSet,
+		ReposSet,
+		CountersSet,
 		amazon_sns.NewSnsSubscriber,
 		handler.NewDeleteDeleteRomancesHandler,
+		handler.NewReconcileCountersHandler,
+		handler.NewVoteCountedHandler,
+		operation.NewReconcileCountersOperation,
+		handler.NewBackfillRomanceIndexesHandler,
+		operation.NewBackfillRomanceIndexesOperation,
 		wire.Bind(new(messaging.Subscriber), new(*amazon_sns.SnsSubscriber)),
 		app.NewMessageProcessor,
 	)
 	return nil, nil
 }
+
+func InitializeCountersStreamConsumer(config config.Config) (*app.CountersStreamConsumer, error) {
+	wire.Build(
+		PlatformSet,
+		ReposSet,
+		CountersSet,
+		dynamodb.NewStreamsClient,
+		persistence.NewRomancesStreamReader,
+		wire.Bind(new(romancesRepo.RomanceChangesReader), new(*persistence.RomancesStreamReader)),
+		operation.NewProjectRomanceCountersOperation,
+		app.NewCountersStreamConsumer,
+	)
+	return nil, nil
+}
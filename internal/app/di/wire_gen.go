@@ -13,8 +13,11 @@ import (
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/application"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/application/messaging/handler"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/application/operation"
-	repository2 "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/repository"
+	repository3 "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/repository"
+	repository2 "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota/repository"
+	valueobject2 "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota/valueobject"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/repository"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/infrastructure/persistence"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/interface/api/rest/v1"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
@@ -27,36 +30,113 @@ import (
 
 func InitializeApiWebServer(config2 config.Config) (*app.ApiWebServer, error) {
 	logger := platform.NewLogger(config2)
-	client := dynamodb.NewDynamoDbClient(config2, logger)
-	romancesRepository := persistence.NewRomancesRepository(client, config2, logger)
-	countersRepository := persistence.NewCountersRepository(client, config2, logger)
-	addUserVoteOperation := operation.NewAddUserVoteOperation(romancesRepository, countersRepository, logger)
+	clientPool, err := dynamodb.NewClientPool(config2, logger)
+	if err != nil {
+		return nil, err
+	}
+	regionRouter, err := dynamodb.NewRegionRouter(config2)
+	if err != nil {
+		return nil, err
+	}
+	clock := platform.NewClock()
+	romancesRepository := persistence.NewRomancesRepository(clientPool, regionRouter, config2, clock, logger)
+	countersRepository := persistence.NewCountersRepository(clientPool, regionRouter, config2, clock, logger)
+	metrics := platform.NewMetrics()
+	bufferedCountersRepository := persistence.NewBufferedCountersRepository(countersRepository, config2, metrics)
+	voteTransitionPolicy, err := valueobject.NewVoteTransitionPolicy(config2)
+	if err != nil {
+		return nil, err
+	}
+	quotaPolicy, err := valueobject2.NewQuotaPolicy(config2)
+	if err != nil {
+		return nil, err
+	}
+	snsPublisher := amazon_sns.NewSnsPublisher(config2, logger)
+	addUserVoteOperation := operation.NewAddUserVoteOperation(romancesRepository, bufferedCountersRepository, voteTransitionPolicy, quotaPolicy, snsPublisher, config2, clock, logger)
+	addUserVotesOperation := operation.NewAddUserVotesOperation(addUserVoteOperation)
 	getUserVoteOperation := operation.NewGetUserVoteOperation(romancesRepository)
-	deleteUserVoteOperation := operation.NewDeleteUserVoteOperation(romancesRepository, countersRepository, logger)
-	changeUserVoteOperation := operation.NewChangeUserVoteOperation(romancesRepository, countersRepository, logger)
+	deleteUserVoteOperation := operation.NewDeleteUserVoteOperation(romancesRepository, bufferedCountersRepository, voteTransitionPolicy, snsPublisher, config2, clock, logger)
+	changeUserVoteOperation := operation.NewChangeUserVoteOperation(romancesRepository, bufferedCountersRepository, voteTransitionPolicy, quotaPolicy, snsPublisher, config2, clock, logger)
 	getRomanceOperation := operation.NewGetRomanceOperation(romancesRepository)
+	getRomancesOperation := operation.NewGetRomancesOperation(romancesRepository)
 	deleteRomanceOperation := operation.NewDeleteRomanceOperation(romancesRepository)
-	snsPublisher := amazon_sns.NewSnsPublisher(config2, logger)
 	deleteRomancesOperation := operation.NewDeleteRomancesOperation(snsPublisher, logger)
-	getLifetimeCountersOperation := operation.NewGetLifetimeCountersOperation(countersRepository)
-	getHourlyCountersOperation := operation.NewGetHourlyCountersOperation(countersRepository)
-	votingService := application.NewVotingService(addUserVoteOperation, getUserVoteOperation, deleteUserVoteOperation, changeUserVoteOperation, getRomanceOperation, deleteRomanceOperation, deleteRomancesOperation, getLifetimeCountersOperation, getHourlyCountersOperation)
-	votesStorageRoutsRegister := v1.NewVotesStorageRoutsRegister(votingService)
+	blockRomanceOperation := operation.NewBlockRomanceOperation(romancesRepository, logger)
+	unblockRomanceOperation := operation.NewUnblockRomanceOperation(romancesRepository, logger)
+	listRomancesOperation := operation.NewListRomancesOperation(romancesRepository)
+	listMatchesOperation := operation.NewListMatchesOperation(romancesRepository)
+	listLikesOperation := operation.NewListLikesOperation(romancesRepository)
+	getLifetimeCountersOperation := operation.NewGetLifetimeCountersOperation(bufferedCountersRepository)
+	getHourlyCountersOperation := operation.NewGetHourlyCountersOperation(bufferedCountersRepository, clock)
+	getHourlyCountersSeriesOperation := operation.NewGetHourlyCountersSeriesOperation(bufferedCountersRepository)
+	getPeriodCountersOperation := operation.NewGetPeriodCountersOperation(bufferedCountersRepository)
+	quotasRepository := persistence.NewQuotasRepository(clientPool, regionRouter, config2, logger)
+	getDailyQuotasOperation := operation.NewGetDailyQuotasOperation(quotasRepository, quotaPolicy, clock)
+	requestCountersReconciliationOperation := operation.NewRequestCountersReconciliationOperation(snsPublisher, logger)
+	votingService := application.NewVotingService(addUserVoteOperation, addUserVotesOperation, getUserVoteOperation, deleteUserVoteOperation, changeUserVoteOperation, getRomanceOperation, getRomancesOperation, deleteRomanceOperation, deleteRomancesOperation, blockRomanceOperation, unblockRomanceOperation, listRomancesOperation, listMatchesOperation, listLikesOperation, getLifetimeCountersOperation, getHourlyCountersOperation, getHourlyCountersSeriesOperation, getPeriodCountersOperation, getDailyQuotasOperation, requestCountersReconciliationOperation)
+	votesStorageRoutsRegister := v1.NewVotesStorageRoutsRegister(votingService, voteTransitionPolicy)
 	handlerFactory := api.NewHandlerFactory(votesStorageRoutsRegister)
-	apiWebServer := app.NewApiWebServer(handlerFactory, config2, logger)
+	apiWebServer := app.NewApiWebServer(handlerFactory, bufferedCountersRepository, config2, logger)
 	return apiWebServer, nil
 }
 
 func InitializeMessageProcessor(config2 config.Config) (*app.MessageProcessor, error) {
 	logger := platform.NewLogger(config2)
 	snsSubscriber := amazon_sns.NewSnsSubscriber(config2, logger)
-	deleteRomancesHandler := handler.NewDeleteDeleteRomancesHandler(logger)
-	messageProcessor := app.NewMessageProcessor(snsSubscriber, deleteRomancesHandler, logger)
+	clientPool, err := dynamodb.NewClientPool(config2, logger)
+	if err != nil {
+		return nil, err
+	}
+	regionRouter, err := dynamodb.NewRegionRouter(config2)
+	if err != nil {
+		return nil, err
+	}
+	clock := platform.NewClock()
+	romancesRepository := persistence.NewRomancesRepository(clientPool, regionRouter, config2, clock, logger)
+	deleteRomancesHandler := handler.NewDeleteDeleteRomancesHandler(romancesRepository, logger)
+	countersRepository := persistence.NewCountersRepository(clientPool, regionRouter, config2, clock, logger)
+	reconcileCountersOperation := operation.NewReconcileCountersOperation(romancesRepository, countersRepository, logger)
+	reconcileCountersHandler := handler.NewReconcileCountersHandler(reconcileCountersOperation, romancesRepository, logger)
+	voteCountedHandler := handler.NewVoteCountedHandler(countersRepository, logger)
+	backfillRomanceIndexesOperation := operation.NewBackfillRomanceIndexesOperation(romancesRepository, logger)
+	backfillRomanceIndexesHandler := handler.NewBackfillRomanceIndexesHandler(backfillRomanceIndexesOperation, logger)
+	messageProcessor := app.NewMessageProcessor(snsSubscriber, deleteRomancesHandler, reconcileCountersHandler, voteCountedHandler, backfillRomanceIndexesHandler, logger)
 	return messageProcessor, nil
 }
 
+func InitializeCountersStreamConsumer(config2 config.Config) (*app.CountersStreamConsumer, error) {
+	logger := platform.NewLogger(config2)
+	clientPool, err := dynamodb.NewClientPool(config2, logger)
+	if err != nil {
+		return nil, err
+	}
+	streamsClient, err := dynamodb.NewStreamsClient(config2)
+	if err != nil {
+		return nil, err
+	}
+	regionRouter, err := dynamodb.NewRegionRouter(config2)
+	if err != nil {
+		return nil, err
+	}
+	clock := platform.NewClock()
+	romancesRepository := persistence.NewRomancesRepository(clientPool, regionRouter, config2, clock, logger)
+	romancesStreamReader := persistence.NewRomancesStreamReader(clientPool, streamsClient, romancesRepository, config2, clock, logger)
+	countersRepository := persistence.NewCountersRepository(clientPool, regionRouter, config2, clock, logger)
+	projectRomanceCountersOperation := operation.NewProjectRomanceCountersOperation(countersRepository, clock)
+	countersStreamConsumer := app.NewCountersStreamConsumer(romancesStreamReader, projectRomanceCountersOperation, logger)
+	return countersStreamConsumer, nil
+}
+
 // wire.go:
 
-var PlatformSet = wire.NewSet(platform.NewLogger)
+var PlatformSet = wire.NewSet(platform.NewLogger, platform.NewMetrics, platform.NewClock)
+
+var PoliciesSet = wire.NewSet(valueobject.NewVoteTransitionPolicy, valueobject2.NewQuotaPolicy)
+
+var ReposSet = wire.NewSet(dynamodb.NewClientPool, wire.Bind(new(dynamodb.Client), new(*dynamodb.ClientPool)), dynamodb.NewRegionRouter, persistence.NewRomancesRepository, persistence.NewQuotasRepository, wire.Bind(new(repository.RomancesRepository), new(*persistence.RomancesRepository)), wire.Bind(new(repository2.QuotasRepository), new(*persistence.QuotasRepository)))
+
+// BufferedCountersSet holds the counters increments back in the buffer, the API server flushes it on stop.
+var BufferedCountersSet = wire.NewSet(persistence.NewCountersRepository, persistence.NewBufferedCountersRepository, wire.Bind(new(repository3.CountersRepository), new(*persistence.BufferedCountersRepository)), wire.Bind(new(repository3.CountersBuffer), new(*persistence.BufferedCountersRepository)))
 
-var ReposSet = wire.NewSet(dynamodb.NewDynamoDbClient, persistence.NewRomancesRepository, persistence.NewCountersRepository, wire.Bind(new(repository.RomancesRepository), new(*persistence.RomancesRepository)), wire.Bind(new(repository2.CountersRepository), new(*persistence.CountersRepository)))
+// CountersSet writes the counters right away, it is used by the workers which do not flush a buffer on stop.
+var CountersSet = wire.NewSet(persistence.NewCountersRepository, wire.Bind(new(repository3.CountersRepository), new(*persistence.CountersRepository)))
@@ -9,20 +9,29 @@ import (
 )
 
 type MessageProcessor struct {
-	subscriber            messaging.Subscriber
-	deleteRomancesHandler handler.DeleteRomancesHandler
-	logger                platform.Logger
+	subscriber                    messaging.Subscriber
+	deleteRomancesHandler         handler.DeleteRomancesHandler
+	reconcileCountersHandler      handler.ReconcileCountersHandler
+	voteCountedHandler            handler.VoteCountedHandler
+	backfillRomanceIndexesHandler handler.BackfillRomanceIndexesHandler
+	logger                        platform.Logger
 }
 
 func NewMessageProcessor(
 	subscriber messaging.Subscriber,
 	deleteRomancesHandler handler.DeleteRomancesHandler,
+	reconcileCountersHandler handler.ReconcileCountersHandler,
+	voteCountedHandler handler.VoteCountedHandler,
+	backfillRomanceIndexesHandler handler.BackfillRomanceIndexesHandler,
 	logger platform.Logger,
 ) *MessageProcessor {
 	return &MessageProcessor{
-		subscriber:            subscriber,
-		deleteRomancesHandler: deleteRomancesHandler,
-		logger:                logger,
+		subscriber:                    subscriber,
+		deleteRomancesHandler:         deleteRomancesHandler,
+		reconcileCountersHandler:      reconcileCountersHandler,
+		voteCountedHandler:            voteCountedHandler,
+		backfillRomanceIndexesHandler: backfillRomanceIndexesHandler,
+		logger:                        logger,
 	}
 }
 
@@ -37,6 +46,36 @@ func (s *MessageProcessor) Start(ctx context.Context) error {
 		}
 	}()
 
+	cancelReconcile, err := messaging.Listen(ctx, s.subscriber, operation.ReconcileCountersTopic, s.reconcileCountersHandler)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err = cancelReconcile(); err != nil {
+			s.logger.Error("cancel failed", "err", err)
+		}
+	}()
+
+	cancelVoteCounted, err := messaging.Listen(ctx, s.subscriber, operation.VoteCountedTopic, s.voteCountedHandler)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err = cancelVoteCounted(); err != nil {
+			s.logger.Error("cancel failed", "err", err)
+		}
+	}()
+
+	cancelBackfill, err := messaging.Listen(ctx, s.subscriber, operation.BackfillRomanceIndexesTopic, s.backfillRomanceIndexesHandler)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err = cancelBackfill(); err != nil {
+			s.logger.Error("cancel failed", "err", err)
+		}
+	}()
+
 	<-ctx.Done()
 	return ctx.Err()
 }
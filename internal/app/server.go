@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.bumble.dev/shcherbanich/user-votes-storage/config"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/app/api"
+	countersRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/repository"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
 	"github.com/danielgtaylor/huma/v2/humacli"
 	"net/http"
@@ -14,17 +15,20 @@ import (
 
 type ApiWebServer struct {
 	handlerFactory api.HandlerFactory
+	countersBuffer countersRepo.CountersBuffer
 	config         config.Config
 	logger         platform.Logger
 }
 
 func NewApiWebServer(
 	handlerFactory api.HandlerFactory,
+	countersBuffer countersRepo.CountersBuffer,
 	config config.Config,
 	logger platform.Logger,
 ) *ApiWebServer {
 	return &ApiWebServer{
 		handlerFactory: handlerFactory,
+		countersBuffer: countersBuffer,
 		config:         config,
 		logger:         logger,
 	}
@@ -39,7 +43,20 @@ func (s *ApiWebServer) Serve() {
 			Handler: s.handlerFactory.NewHumaApiServerHandler(),
 		}
 
+		adminAddr := fmt.Sprintf("%s:%d", o.AdminHost, o.AdminPort)
+		adminServer := &http.Server{
+			Addr:    adminAddr,
+			Handler: s.handlerFactory.NewAdminHandler(),
+		}
+
 		hooks.OnStart(func() {
+			go func() {
+				s.logger.Info(fmt.Sprintf("Serving metrics on http://%s", adminAddr))
+				if err := adminServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					s.logger.Error(fmt.Sprintf("Admin server error: %s", err))
+				}
+			}()
+
 			s.logger.Info(fmt.Sprintf("Listening on http://%s", addr))
 			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 				s.logger.Error(fmt.Sprintf("Server error: %s", err))
@@ -50,6 +67,10 @@ func (s *ApiWebServer) Serve() {
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 			defer cancel()
 			_ = server.Shutdown(ctx)
+			_ = adminServer.Shutdown(ctx)
+			if err := s.countersBuffer.Close(ctx); err != nil {
+				s.logger.Error(fmt.Sprintf("Counters buffer flush error: %s", err))
+			}
 		})
 	})
 
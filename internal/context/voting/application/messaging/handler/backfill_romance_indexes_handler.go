@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/application/messaging/message"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/application/operation"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
+)
+
+type BackfillRomanceIndexesHandler struct {
+	backfillRomanceIndexesOperation operation.BackfillRomanceIndexesOperation
+	logger                          platform.Logger
+}
+
+func NewBackfillRomanceIndexesHandler(
+	backfillRomanceIndexesOperation operation.BackfillRomanceIndexesOperation,
+	logger platform.Logger,
+) BackfillRomanceIndexesHandler {
+	return BackfillRomanceIndexesHandler{
+		backfillRomanceIndexesOperation: backfillRomanceIndexesOperation,
+		logger:                          logger,
+	}
+}
+
+func (h BackfillRomanceIndexesHandler) Handle(ctx context.Context, message *message.BackfillRomanceIndexesMessage) error {
+	h.logger.Debug(fmt.Sprintf("message BackfillRomanceIndexesMessage received: %v", message))
+
+	updated, err := h.backfillRomanceIndexesOperation.Run(ctx, message.CountryId, message.Segment, message.TotalSegments)
+	if err != nil {
+		return err
+	}
+
+	h.logger.Info(fmt.Sprintf(
+		"BackfillRomanceIndexesMessage %s: segment %d/%d, %d romances updated",
+		message.Id, message.Segment, message.TotalSegments, updated,
+	))
+
+	return nil
+}
@@ -4,22 +4,40 @@ import (
 	"context"
 	"fmt"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/application/messaging/message"
+	romancesRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/repository"
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
 )
 
 type DeleteRomancesHandler struct {
-	logger platform.Logger
+	romancesRepository romancesRepo.RomancesRepository
+	logger             platform.Logger
 }
 
 func NewDeleteDeleteRomancesHandler(
+	romancesRepository romancesRepo.RomancesRepository,
 	logger platform.Logger,
 ) DeleteRomancesHandler {
 	return DeleteRomancesHandler{
-		logger: logger,
+		romancesRepository: romancesRepository,
+		logger:             logger,
 	}
 }
 
 func (h DeleteRomancesHandler) Handle(ctx context.Context, message *message.DeleteRomancesMessage) error {
 	h.logger.Debug(fmt.Sprintf("message DeleteRomancesMessage received: %v", message))
+
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(message.CountryId, message.ActiveUserId)
+	if err != nil {
+		h.logger.Error(fmt.Sprintf("DeleteRomancesMessage %s is invalid: %+v", message.Id, err))
+		return err
+	}
+
+	err = h.romancesRepository.DeleteUserRomances(ctx, activeUserKey)
+	if err != nil {
+		h.logger.Error(fmt.Sprintf("DeleteUserRomances error: %+v", err))
+		return err
+	}
+
 	return nil
 }
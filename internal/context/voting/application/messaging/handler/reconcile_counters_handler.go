@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/application/messaging/message"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/application/operation"
+	counterEntity "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/entity"
+	romancesRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/repository"
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
+	"github.com/google/uuid"
+)
+
+const reconciledUsersLimit = 10000
+
+type ReconcileCountersHandler struct {
+	reconcileCountersOperation operation.ReconcileCountersOperation
+	romancesRepository         romancesRepo.RomancesRepository
+	logger                     platform.Logger
+}
+
+type countersReconciliationSummary struct {
+	Checked   int
+	Drifted   int
+	Corrected int
+	Failed    int
+}
+
+func NewReconcileCountersHandler(
+	reconcileCountersOperation operation.ReconcileCountersOperation,
+	romancesRepository romancesRepo.RomancesRepository,
+	logger platform.Logger,
+) ReconcileCountersHandler {
+	return ReconcileCountersHandler{
+		reconcileCountersOperation: reconcileCountersOperation,
+		romancesRepository:         romancesRepository,
+		logger:                     logger,
+	}
+}
+
+func (h ReconcileCountersHandler) Handle(ctx context.Context, message *message.ReconcileCountersMessage) error {
+	h.logger.Debug(fmt.Sprintf("message ReconcileCountersMessage received: %v", message))
+
+	summary := countersReconciliationSummary{}
+
+	var err error
+	if message.IsForSingleUser() {
+		activeUserKey, keyErr := sharedValueObject.NewActiveUserKey(message.CountryId, message.ActiveUserId)
+		if keyErr != nil {
+			h.logger.Error(fmt.Sprintf("ReconcileCountersMessage %s is invalid: %+v", message.Id, keyErr))
+			return keyErr
+		}
+
+		err = h.reconcile(ctx, activeUserKey, message.Rewrite, &summary)
+	} else {
+		err = h.reconcileSegment(ctx, message, &summary)
+	}
+
+	h.logger.Info(fmt.Sprintf(
+		"ReconcileCountersMessage %s done (rewrite: %t): checked %d, drifted %d, corrected %d, failed %d",
+		message.Id, message.Rewrite, summary.Checked, summary.Drifted, summary.Corrected, summary.Failed,
+	))
+
+	// the failed users are checked again on the redelivery of the message
+	return err
+}
+
+func (h ReconcileCountersHandler) reconcileSegment(
+	ctx context.Context,
+	message *message.ReconcileCountersMessage,
+	summary *countersReconciliationSummary,
+) error {
+	reconciled := make(map[uuid.UUID]struct{}, reconciledUsersLimit)
+	cursor := ""
+
+	var errs []error
+	for {
+		pageRequest, err := sharedValueObject.NewPageRequest(sharedValueObject.MaxPageLimit, cursor)
+		if err != nil {
+			return err
+		}
+
+		page, err := h.romancesRepository.ScanRomanceUsers(
+			ctx,
+			message.CountryId,
+			message.Segment,
+			message.TotalSegments,
+			pageRequest,
+		)
+		if err != nil {
+			h.logger.Error(fmt.Sprintf("ScanRomanceUsers error: %+v", err))
+			return errors.Join(append(errs, err)...)
+		}
+
+		for _, userKey := range page.UserKeys {
+			if _, ok := reconciled[userKey.ActiveUserId()]; ok {
+				continue
+			}
+			if len(reconciled) >= reconciledUsersLimit {
+				clear(reconciled)
+			}
+			reconciled[userKey.ActiveUserId()] = struct{}{}
+
+			if err = h.reconcile(ctx, userKey, message.Rewrite, summary); err != nil {
+				errs = append(errs, fmt.Errorf("user %s: %w", userKey.ActiveUserId(), err))
+			}
+		}
+
+		if !page.HasMore() {
+			return errors.Join(errs...)
+		}
+		cursor = page.NextCursor
+	}
+}
+
+func (h ReconcileCountersHandler) reconcile(
+	ctx context.Context,
+	activeUserKey sharedValueObject.ActiveUserKey,
+	rewrite bool,
+	summary *countersReconciliationSummary,
+) error {
+	summary.Checked++
+
+	reconciliation, err := h.reconcileCountersOperation.Run(ctx, activeUserKey, rewrite)
+	if err != nil {
+		summary.Failed++
+		h.logger.Error(fmt.Sprintf("Counters reconciliation of user %s failed: %+v", activeUserKey.ActiveUserId(), err))
+		return err
+	}
+
+	if !reconciliation.HasDrift() {
+		return nil
+	}
+
+	summary.Drifted++
+	if reconciliation.Corrected {
+		summary.Corrected++
+	}
+
+	h.logger.Info(fmt.Sprintf(
+		"Counters of user %s drifted (corrected: %t): stored [%s], actual [%s]",
+		activeUserKey.ActiveUserId(),
+		reconciliation.Corrected,
+		formatLifetimeCounters(reconciliation.Stored),
+		formatLifetimeCounters(reconciliation.Actual),
+	))
+
+	return nil
+}
+
+func formatLifetimeCounters(c counterEntity.CountersGroup) string {
+	return fmt.Sprintf(
+		"incoming yes %d, incoming no %d, outgoing yes %d, outgoing no %d, incoming by vote type %+v, outgoing by vote type %+v, matches %d",
+		c.IncomingYes, c.IncomingNo, c.OutgoingYes, c.OutgoingNo, c.IncomingByVoteType, c.OutgoingByVoteType, c.Matches,
+	)
+}
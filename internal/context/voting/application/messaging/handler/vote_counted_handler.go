@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/application/messaging/message"
+	countersRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/repository"
+	countersValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/valueobject"
+	romancesValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
+)
+
+type VoteCountedHandler struct {
+	countersRepository countersRepo.CountersRepository
+	logger             platform.Logger
+}
+
+func NewVoteCountedHandler(
+	countersRepository countersRepo.CountersRepository,
+	logger platform.Logger,
+) VoteCountedHandler {
+	return VoteCountedHandler{
+		countersRepository: countersRepository,
+		logger:             logger,
+	}
+}
+
+func (h VoteCountedHandler) Handle(ctx context.Context, message *message.VoteCountedMessage) error {
+	h.logger.Debug(fmt.Sprintf("message VoteCountedMessage received: %v", message))
+
+	countersChanges, voteId, err := h.toCountersChanges(message)
+	if err != nil {
+		h.logger.Error(fmt.Sprintf("VoteCountedMessage %s is invalid: %+v", message.Id, err))
+		return err
+	}
+
+	// the message id keeps a redelivered message from counting the vote twice
+	err = h.countersRepository.ApplyVoteCountersChange(ctx, message.Id, voteId, countersChanges)
+	if err != nil {
+		h.logger.Error(fmt.Sprintf("ApplyVoteCountersChange error: %+v", err))
+		return err
+	}
+
+	return nil
+}
+
+func (h VoteCountedHandler) toCountersChanges(
+	message *message.VoteCountedMessage,
+) ([]countersValueObject.VoteCountersChange, sharedValueObject.VoteId, error) {
+	voteId, err := sharedValueObject.NewVoteId(message.CountryId, message.ActiveUserId, message.PeerCountryId, message.PeerUserId)
+	if err != nil {
+		return nil, sharedValueObject.VoteId{}, err
+	}
+
+	counterUpdateGroup, err := countersValueObject.NewCounterUpdateGroup(message.CountedAt)
+	if err != nil {
+		return nil, sharedValueObject.VoteId{}, err
+	}
+
+	voteTypes := make([]romancesValueObject.VoteType, 0, 3)
+	for _, name := range []string{message.OldVoteType, message.VoteType, message.PeerVoteType} {
+		voteType, ok := romancesValueObject.VoteTypeFromString(name)
+		if !ok {
+			return nil, sharedValueObject.VoteId{}, fmt.Errorf("unknown vote type %q", name)
+		}
+		voteTypes = append(voteTypes, voteType)
+	}
+
+	countersChanges := []countersValueObject.VoteCountersChange{
+		countersValueObject.NewVoteTypeCountersChange(counterUpdateGroup, voteTypes[0], voteTypes[1], voteTypes[2]).Increments(),
+	}
+
+	if message.ReplacedCountedAt != nil {
+		replacedCounterUpdateGroup, err := countersValueObject.NewCounterUpdateGroup(*message.ReplacedCountedAt)
+		if err != nil {
+			return nil, sharedValueObject.VoteId{}, err
+		}
+		countersChanges = append(
+			countersChanges,
+			countersValueObject.NewVoteTypeCountersChange(replacedCounterUpdateGroup, voteTypes[0], voteTypes[1], voteTypes[2]).Decrements(),
+		)
+	}
+
+	return countersChanges, voteId, nil
+}
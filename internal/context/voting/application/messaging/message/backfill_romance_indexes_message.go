@@ -0,0 +1,41 @@
+package message
+
+import (
+	"encoding/json"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/messaging"
+	"github.com/google/uuid"
+)
+
+// BackfillRomanceIndexesMessage asks to backfill the index attributes of one scan segment.
+type BackfillRomanceIndexesMessage struct {
+	Id            uuid.UUID `json:"id"`
+	CountryId     uint16    `json:"country_id"`
+	Segment       int32     `json:"segment"`
+	TotalSegments int32     `json:"total_segments"`
+}
+
+func NewBackfillRomanceIndexesMessage(
+	countryId uint16,
+	segment int32,
+	totalSegments int32,
+) *BackfillRomanceIndexesMessage {
+	return &BackfillRomanceIndexesMessage{
+		Id:            uuid.New(),
+		CountryId:     countryId,
+		Segment:       segment,
+		TotalSegments: totalSegments,
+	}
+}
+
+func (m *BackfillRomanceIndexesMessage) GetId() uuid.UUID {
+	return m.Id
+}
+
+func (m *BackfillRomanceIndexesMessage) GetPayload() messaging.Payload {
+	payload, _ := json.Marshal(m)
+	return payload
+}
+
+func (m *BackfillRomanceIndexesMessage) Load(payload messaging.Payload) error {
+	return json.Unmarshal(payload, &m)
+}
@@ -0,0 +1,59 @@
+package message
+
+import (
+	"encoding/json"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/messaging"
+	"github.com/google/uuid"
+)
+
+// ReconcileCountersMessage asks to reconcile the lifetime counters of a user or of a scan segment.
+type ReconcileCountersMessage struct {
+	Id            uuid.UUID `json:"id"`
+	ActiveUserId  uuid.UUID `json:"active_user_id,omitempty"`
+	CountryId     uint16    `json:"country_id"`
+	Segment       int32     `json:"segment,omitempty"`
+	TotalSegments int32     `json:"total_segments,omitempty"`
+	Rewrite       bool      `json:"rewrite,omitempty"`
+}
+
+func NewUserReconcileCountersMessage(activeUserKey valueobject.ActiveUserKey, rewrite bool) *ReconcileCountersMessage {
+	return &ReconcileCountersMessage{
+		Id:           uuid.New(),
+		ActiveUserId: activeUserKey.ActiveUserId(),
+		CountryId:    activeUserKey.CountryId(),
+		Rewrite:      rewrite,
+	}
+}
+
+func NewSegmentReconcileCountersMessage(
+	countryId uint16,
+	segment int32,
+	totalSegments int32,
+	rewrite bool,
+) *ReconcileCountersMessage {
+	return &ReconcileCountersMessage{
+		Id:            uuid.New(),
+		CountryId:     countryId,
+		Segment:       segment,
+		TotalSegments: totalSegments,
+		Rewrite:       rewrite,
+	}
+}
+
+func (m *ReconcileCountersMessage) IsForSingleUser() bool {
+	return m.ActiveUserId != uuid.Nil
+}
+
+func (m *ReconcileCountersMessage) GetId() uuid.UUID {
+	return m.Id
+}
+
+func (m *ReconcileCountersMessage) GetPayload() messaging.Payload {
+	payload, _ := json.Marshal(m)
+	return payload
+}
+
+func (m *ReconcileCountersMessage) Load(payload messaging.Payload) error {
+	return json.Unmarshal(payload, &m)
+}
@@ -0,0 +1,64 @@
+package message
+
+import (
+	"encoding/json"
+	romancesValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/messaging"
+	"github.com/google/uuid"
+	"time"
+)
+
+// VoteCountedMessage carries the counters increments of a new vote to the message processor.
+type VoteCountedMessage struct {
+	Id            uuid.UUID `json:"id"`
+	CountryId     uint16    `json:"country_id"`
+	ActiveUserId  uuid.UUID `json:"active_user_id"`
+	PeerCountryId uint16    `json:"peer_country_id"`
+	PeerUserId    uuid.UUID `json:"peer_user_id"`
+	OldVoteType   string    `json:"old_vote_type"`
+	VoteType      string    `json:"vote_type"`
+	PeerVoteType  string    `json:"peer_vote_type"`
+	CountedAt     time.Time `json:"counted_at"`
+
+	ReplacedCountedAt *time.Time `json:"replaced_counted_at,omitempty"`
+}
+
+func NewVoteCountedMessage(
+	voteId valueobject.VoteId,
+	oldVoteType romancesValueObject.VoteType,
+	voteType romancesValueObject.VoteType,
+	peerVoteType romancesValueObject.VoteType,
+	countedAt time.Time,
+) *VoteCountedMessage {
+	return &VoteCountedMessage{
+		Id:            uuid.New(),
+		CountryId:     voteId.CountryId(),
+		ActiveUserId:  voteId.ActiveUserId(),
+		PeerCountryId: voteId.PeerCountryId(),
+		PeerUserId:    voteId.PeerUserId(),
+		OldVoteType:   oldVoteType.String(),
+		VoteType:      voteType.String(),
+		PeerVoteType:  peerVoteType.String(),
+		CountedAt:     countedAt,
+	}
+}
+
+// WithReplacedCountedAt makes the message carry the decrements of the replaced vote counted at the given time.
+func (m *VoteCountedMessage) WithReplacedCountedAt(replacedCountedAt time.Time) *VoteCountedMessage {
+	m.ReplacedCountedAt = &replacedCountedAt
+	return m
+}
+
+func (m *VoteCountedMessage) GetId() uuid.UUID {
+	return m.Id
+}
+
+func (m *VoteCountedMessage) GetPayload() messaging.Payload {
+	payload, _ := json.Marshal(m)
+	return payload
+}
+
+func (m *VoteCountedMessage) Load(payload messaging.Payload) error {
+	return json.Unmarshal(payload, &m)
+}
@@ -5,32 +5,52 @@ import (
 	"errors"
 	"fmt"
 	"github.bumble.dev/shcherbanich/user-votes-storage/config"
+	counterDomain "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter"
 	countersRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/repository"
 	countersValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/valueobject"
+	quotaValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota/valueobject"
 	romanceDomain "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/entity"
 	romancesRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/repository"
 	romancesValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
 	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/messaging"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
 	"time"
 )
 
+const VoteCountedTopic = messaging.Topic("vote-counted")
+
 type AddUserVoteOperation struct {
-	romancesRepository romancesRepo.RomancesRepository
-	countersRepository countersRepo.CountersRepository
-	logger             platform.Logger
+	romancesRepository   romancesRepo.RomancesRepository
+	countersRepository   countersRepo.CountersRepository
+	voteTransitionPolicy romancesValueObject.VoteTransitionPolicy
+	quotaPolicy          quotaValueObject.QuotaPolicy
+	publisher            messaging.Publisher
+	config               config.Config
+	clock                platform.Clock
+	logger               platform.Logger
 }
 
 func NewAddUserVoteOperation(
 	romancesRepository romancesRepo.RomancesRepository,
 	countersRepository countersRepo.CountersRepository,
+	voteTransitionPolicy romancesValueObject.VoteTransitionPolicy,
+	quotaPolicy quotaValueObject.QuotaPolicy,
+	publisher messaging.Publisher,
+	config config.Config,
+	clock platform.Clock,
 	logger platform.Logger,
 ) AddUserVoteOperation {
 	return AddUserVoteOperation{
-		romancesRepository: romancesRepository,
-		countersRepository: countersRepository,
-		logger:             logger,
+		romancesRepository:   romancesRepository,
+		countersRepository:   countersRepository,
+		voteTransitionPolicy: voteTransitionPolicy,
+		quotaPolicy:          quotaPolicy,
+		publisher:            publisher,
+		config:               config,
+		clock:                clock,
+		logger:               logger,
 	}
 }
 
@@ -38,8 +58,13 @@ func (r *AddUserVoteOperation) Run(
 	ctx context.Context,
 	voteId sharedValueObject.VoteId,
 	voteType romancesValueObject.VoteType,
+	message romancesValueObject.ComplimentMessage,
 	votedAt time.Time,
 ) (entity.Vote, error) {
+	if !message.IsEmpty() && !voteType.CanCarryMessage() {
+		return entity.Vote{}, romanceDomain.NewMessageNotAllowedError(voteType)
+	}
+
 	tries := 0
 
 	getRomanceOperation := NewGetRomanceOperation(r.romancesRepository)
@@ -50,7 +75,11 @@ func (r *AddUserVoteOperation) Run(
 			return entity.Vote{}, err
 		}
 
-		if !isVoteTypeCanBeChanged(romance.ActiveUserVote, voteType) {
+		if romance.IsBlocked() {
+			return entity.Vote{}, romanceDomain.ErrRomanceBlocked
+		}
+
+		if !r.voteTransitionPolicy.IsAllowed(voteId.CountryId(), romance.ActiveUserVote.VoteType, voteType) {
 			return entity.Vote{}, romanceDomain.NewChangingVoteTypeError(romance.ActiveUserVote.VoteType, voteType)
 		}
 
@@ -58,39 +87,104 @@ func (r *AddUserVoteOperation) Run(
 			return entity.Vote{}, romanceDomain.ErrVoteDuplicate
 		}
 
-		currentTime := time.Now()
+		currentTime := r.clock.Now()
 		counterUpdateGroup, err := countersValueObject.NewCounterUpdateGroup(currentTime)
 		if err != nil {
 			return entity.Vote{}, err
 		}
 
-		newVoteIsPositive := voteType.IsPositive()
-		newVoteIsNegative := voteType.IsNegative()
-		oldVoteIsNotPositive := !romance.ActiveUserVote.VoteType.IsPositive()
-		oldVoteIsNotNegative := !romance.ActiveUserVote.VoteType.IsNegative()
+		quotaConsumption, err := getQuotaConsumption(r.quotaPolicy, voteId, voteType, currentTime)
+		if err != nil {
+			return entity.Vote{}, err
+		}
+
+		oldVote := romance.ActiveUserVote
+		peerVoteType := romance.PeerUserVote.VoteType
+		countersIncrements := countersValueObject.NewVoteTypeCountersChange(
+			counterUpdateGroup,
+			oldVote.VoteType,
+			voteType,
+			peerVoteType,
+		).Increments()
 
-		romance, err = r.romancesRepository.AddActiveUserVoteToRomance(
+		// the replaced vote is taken out of the hourly group it was counted in
+		var replacedCountersDecrements countersValueObject.VoteCountersChange
+		if !oldVote.VoteType.IsEmpty() {
+			replacedCountersChange, err := getMovedVoteCountersChange(oldVote, voteType, peerVoteType, currentTime)
+			if err != nil {
+				return entity.Vote{}, err
+			}
+			replacedCountersDecrements = replacedCountersChange.Decrements()
+		}
+
+		countersUpdateMode := r.config.Counters.UpdateMode
+		var transactionCountersChanges []countersValueObject.VoteCountersChange
+		if countsVoteInTransaction(r.config.Counters) {
+			transactionCountersChanges = []countersValueObject.VoteCountersChange{countersIncrements, replacedCountersDecrements}
+		}
+		voteCountedMessage := newVoteCountedMessage(
+			voteId,
+			oldVote.VoteType,
+			voteType,
+			peerVoteType,
+			currentTime,
+			replacedCountersDecrements.UpdateGroup().HourStartTime(),
+		)
+
+		romance, err = r.romancesRepository.AddActiveUserVoteToRomanceWithCounters(
 			ctx,
 			romance,
 			voteType,
+			message,
 			votedAt,
+			voteCountedMessage.Id,
+			transactionCountersChanges,
+			quotaConsumption,
 		)
 
+		if errors.Is(err, counterDomain.ErrCountersNotApplied) {
+			r.logger.Error(fmt.Sprintf("AddActiveUserVoteToRomanceWithCounters error: %+v", err))
+			err = queueVoteCountersChange(
+				ctx, r.publisher, r.countersRepository, r.logger, voteCountedMessage, voteId, transactionCountersChanges,
+			)
+			if err != nil {
+				return entity.Vote{}, err
+			}
+			return romance.ActiveUserVote, nil
+		}
+
 		if err != nil {
 			if errors.Is(err, romanceDomain.ErrVersionConflict) && tries < config.DynamoDbVersionConflictRetriesCount {
 				tries += 1
 				continue
 			}
-			r.logger.Error(fmt.Sprintf("AddActiveUserVoteToRomance error: %+v", err))
+			r.logger.Error(fmt.Sprintf("AddActiveUserVoteToRomanceWithCounters error: %+v", err))
 			return entity.Vote{}, err
 		}
 
-		if newVoteIsPositive && oldVoteIsNotPositive {
-			r.countersRepository.IncrYesCounters(ctx, voteId, counterUpdateGroup)
+		if transactionCountersChanges != nil || countersUpdateMode.IsStream() {
+			return romance.ActiveUserVote, nil
+		}
+
+		if countersUpdateMode.IsAsync() {
+			err = queueVoteCountersChange(
+				ctx,
+				r.publisher,
+				r.countersRepository,
+				r.logger,
+				voteCountedMessage,
+				voteId,
+				[]countersValueObject.VoteCountersChange{countersIncrements, replacedCountersDecrements},
+			)
+			if err != nil {
+				r.logger.Error(fmt.Sprintf("ApplyVoteCountersChange error: %+v", err))
+			}
+			return romance.ActiveUserVote, nil
 		}
 
-		if newVoteIsNegative && oldVoteIsNotNegative {
-			r.countersRepository.IncrNoCounters(ctx, voteId, counterUpdateGroup)
+		r.countersRepository.ChangeVoteCounters(ctx, voteId, countersIncrements)
+		if !replacedCountersDecrements.IsEmpty() {
+			r.countersRepository.ChangeVoteCounters(ctx, oldVote.Id, replacedCountersDecrements)
 		}
 
 		return romance.ActiveUserVote, nil
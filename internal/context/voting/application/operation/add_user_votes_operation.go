@@ -0,0 +1,76 @@
+package operation
+
+import (
+	"context"
+	"github.bumble.dev/shcherbanich/user-votes-storage/config"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/entity"
+	romancesValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	"sync"
+	"time"
+)
+
+type UserVoteToAdd struct {
+	VoteId   sharedValueObject.VoteId
+	VoteType romancesValueObject.VoteType
+	Message  romancesValueObject.ComplimentMessage
+	VotedAt  time.Time
+}
+
+type AddUserVoteResult struct {
+	VoteId sharedValueObject.VoteId
+	Vote   entity.Vote
+	Err    error
+}
+
+type AddUserVotesOperation struct {
+	addUserVoteOperation AddUserVoteOperation
+}
+
+func NewAddUserVotesOperation(
+	addUserVoteOperation AddUserVoteOperation,
+) AddUserVotesOperation {
+	return AddUserVotesOperation{
+		addUserVoteOperation: addUserVoteOperation,
+	}
+}
+
+// Run adds the votes independently and returns the results in their order.
+func (r *AddUserVotesOperation) Run(ctx context.Context, votes []UserVoteToAdd) []AddUserVoteResult {
+	results := make([]AddUserVoteResult, len(votes))
+	semaphore := make(chan struct{}, config.BatchVotesConcurrency)
+
+	var wg sync.WaitGroup
+	for i, vote := range votes {
+		results[i].VoteId = vote.VoteId
+
+		if err := acquireSlot(ctx, semaphore); err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			results[i].Vote, results[i].Err = r.addUserVoteOperation.Run(ctx, vote.VoteId, vote.VoteType, vote.Message, vote.VotedAt)
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+func acquireSlot(ctx context.Context, semaphore chan struct{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	select {
+	case semaphore <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
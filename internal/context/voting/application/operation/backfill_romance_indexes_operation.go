@@ -0,0 +1,59 @@
+package operation
+
+import (
+	"context"
+	"fmt"
+	romancesRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/repository"
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/messaging"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
+)
+
+const BackfillRomanceIndexesTopic = messaging.Topic("backfill-romance-indexes")
+
+// BackfillRomanceIndexesOperation backfills the match and like index attributes of one scan segment after the deploy.
+type BackfillRomanceIndexesOperation struct {
+	romancesRepository romancesRepo.RomancesRepository
+	logger             platform.Logger
+}
+
+func NewBackfillRomanceIndexesOperation(
+	romancesRepository romancesRepo.RomancesRepository,
+	logger platform.Logger,
+) BackfillRomanceIndexesOperation {
+	return BackfillRomanceIndexesOperation{
+		romancesRepository: romancesRepository,
+		logger:             logger,
+	}
+}
+
+// Run backfills the romances of one scan segment in the region of the country and returns the number of updated ones.
+func (o *BackfillRomanceIndexesOperation) Run(
+	ctx context.Context,
+	countryId uint16,
+	segment int32,
+	totalSegments int32,
+) (int, error) {
+	updated := 0
+	cursor := ""
+	for {
+		pageRequest, err := sharedValueObject.NewPageRequest(sharedValueObject.MaxPageLimit, cursor)
+		if err != nil {
+			return updated, err
+		}
+
+		page, err := o.romancesRepository.BackfillRomanceIndexes(ctx, countryId, segment, totalSegments, pageRequest)
+		if err != nil {
+			o.logger.Error(fmt.Sprintf("BackfillRomanceIndexes error: %+v", err))
+			return updated, err
+		}
+		updated += page.Updated
+
+		if !page.HasMore() {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	return updated, nil
+}
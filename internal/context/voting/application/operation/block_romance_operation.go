@@ -0,0 +1,57 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.bumble.dev/shcherbanich/user-votes-storage/config"
+	romanceDomain "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance"
+	romancesRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/repository"
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
+)
+
+type BlockRomanceOperation struct {
+	romancesRepository romancesRepo.RomancesRepository
+	logger             platform.Logger
+}
+
+func NewBlockRomanceOperation(
+	romancesRepository romancesRepo.RomancesRepository,
+	logger platform.Logger,
+) BlockRomanceOperation {
+	return BlockRomanceOperation{
+		romancesRepository: romancesRepository,
+		logger:             logger,
+	}
+}
+
+// Run blocks the peer for the active user, blocking an already blocked romance does nothing.
+func (r *BlockRomanceOperation) Run(ctx context.Context, voteId sharedValueObject.VoteId) error {
+	tries := 0
+
+	getRomanceOperation := NewGetRomanceOperation(r.romancesRepository)
+	for {
+		romance, err := getRomanceOperation.Run(ctx, voteId)
+		if err != nil {
+			r.logger.Error(fmt.Sprintf("GetRomance error: %+v", err))
+			return err
+		}
+
+		if romance.IsBlocked() {
+			return nil
+		}
+
+		_, err = r.romancesRepository.BlockRomance(ctx, romance)
+		if err != nil {
+			if errors.Is(err, romanceDomain.ErrVersionConflict) && tries < config.DynamoDbVersionConflictRetriesCount {
+				tries += 1
+				continue
+			}
+			r.logger.Error(fmt.Sprintf("BlockRomance error: %+v", err))
+			return err
+		}
+
+		return nil
+	}
+}
@@ -5,30 +5,49 @@ import (
 	"errors"
 	"fmt"
 	"github.bumble.dev/shcherbanich/user-votes-storage/config"
+	counterDomain "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter"
 	countersRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/repository"
+	countersValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/valueobject"
+	quotaValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota/valueobject"
 	romanceDomain "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/entity"
 	romancesRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/repository"
 	romancesValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
 	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/messaging"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
 )
 
 type ChangeUserVoteOperation struct {
-	romancesRepository romancesRepo.RomancesRepository
-	countersRepository countersRepo.CountersRepository
-	logger             platform.Logger
+	romancesRepository   romancesRepo.RomancesRepository
+	countersRepository   countersRepo.CountersRepository
+	voteTransitionPolicy romancesValueObject.VoteTransitionPolicy
+	quotaPolicy          quotaValueObject.QuotaPolicy
+	publisher            messaging.Publisher
+	config               config.Config
+	clock                platform.Clock
+	logger               platform.Logger
 }
 
 func NewChangeUserVoteOperation(
 	romancesRepository romancesRepo.RomancesRepository,
 	countersRepository countersRepo.CountersRepository,
+	voteTransitionPolicy romancesValueObject.VoteTransitionPolicy,
+	quotaPolicy quotaValueObject.QuotaPolicy,
+	publisher messaging.Publisher,
+	config config.Config,
+	clock platform.Clock,
 	logger platform.Logger,
 ) ChangeUserVoteOperation {
 	return ChangeUserVoteOperation{
-		romancesRepository: romancesRepository,
-		countersRepository: countersRepository,
-		logger:             logger,
+		romancesRepository:   romancesRepository,
+		countersRepository:   countersRepository,
+		voteTransitionPolicy: voteTransitionPolicy,
+		quotaPolicy:          quotaPolicy,
+		publisher:            publisher,
+		config:               config,
+		clock:                clock,
+		logger:               logger,
 	}
 }
 
@@ -36,7 +55,12 @@ func (r *ChangeUserVoteOperation) Run(
 	ctx context.Context,
 	voteId sharedValueObject.VoteId,
 	newVoteType romancesValueObject.VoteType,
+	message romancesValueObject.ComplimentMessage,
 ) (entity.Vote, error) {
+	if !message.IsEmpty() && !newVoteType.CanCarryMessage() {
+		return entity.Vote{}, romanceDomain.NewMessageNotAllowedError(newVoteType)
+	}
+
 	tries := 0
 
 	getRomanceOperation := NewGetRomanceOperation(r.romancesRepository)
@@ -47,7 +71,11 @@ func (r *ChangeUserVoteOperation) Run(
 			return entity.Vote{}, err
 		}
 
-		if !isVoteTypeCanBeChanged(romance.ActiveUserVote, newVoteType) {
+		if romance.IsBlocked() {
+			return entity.Vote{}, romanceDomain.ErrRomanceBlocked
+		}
+
+		if !r.voteTransitionPolicy.IsAllowed(voteId.CountryId(), romance.ActiveUserVote.VoteType, newVoteType) {
 			return entity.Vote{}, romanceDomain.NewChangingVoteTypeError(romance.ActiveUserVote.VoteType, newVoteType)
 		}
 
@@ -55,55 +83,59 @@ func (r *ChangeUserVoteOperation) Run(
 			return entity.Vote{}, romanceDomain.ErrVoteDuplicate
 		}
 
-		romance, err = r.romancesRepository.ChangeActiveUserVoteTypeInRomance(
+		quotaConsumption, err := getQuotaConsumption(r.quotaPolicy, voteId, newVoteType, r.clock.Now())
+		if err != nil {
+			return entity.Vote{}, err
+		}
+
+		oldVote := romance.ActiveUserVote
+		peerVoteType := romance.PeerUserVote.VoteType
+		countersChange, err := getMovedVoteCountersChange(oldVote, newVoteType, peerVoteType, r.clock.Now())
+		if err != nil {
+			return entity.Vote{}, err
+		}
+
+		var transactionCountersChanges []countersValueObject.VoteCountersChange
+		if countsVoteInTransaction(r.config.Counters) {
+			transactionCountersChanges = []countersValueObject.VoteCountersChange{countersChange}
+		}
+		countedAt := countersChange.UpdateGroup().HourStartTime()
+		voteCountedMessage := newVoteCountedMessage(voteId, oldVote.VoteType, newVoteType, peerVoteType, countedAt, countedAt)
+
+		romance, err = r.romancesRepository.ChangeActiveUserVoteTypeInRomanceWithCounters(
 			ctx,
 			romance,
 			newVoteType,
+			message,
+			voteCountedMessage.Id,
+			transactionCountersChanges,
+			quotaConsumption,
 		)
 
+		if errors.Is(err, counterDomain.ErrCountersNotApplied) {
+			r.logger.Error(fmt.Sprintf("ChangeActiveUserVoteTypeInRomanceWithCounters error: %+v", err))
+			err = queueVoteCountersChange(
+				ctx, r.publisher, r.countersRepository, r.logger, voteCountedMessage, voteId, transactionCountersChanges,
+			)
+			if err != nil {
+				return entity.Vote{}, err
+			}
+			return romance.ActiveUserVote, nil
+		}
+
 		if err != nil {
 			if errors.Is(err, romanceDomain.ErrVersionConflict) && tries < config.DynamoDbVersionConflictRetriesCount {
 				tries += 1
 				continue
 			}
-			r.logger.Error(fmt.Sprintf("ChangeActiveUserVoteTypeInRomance error: %+v", err))
+			r.logger.Error(fmt.Sprintf("ChangeActiveUserVoteTypeInRomanceWithCounters error: %+v", err))
 			return entity.Vote{}, err
 		}
 
-		return romance.ActiveUserVote, nil
-	}
-}
-
-func isVoteTypeCanBeChanged(oldVote entity.Vote, newVoteType romancesValueObject.VoteType) bool {
-	allowedVoteChangeOperations := map[romancesValueObject.VoteType][]romancesValueObject.VoteType{
-		romancesValueObject.VoteTypeEmpty: {
-			romancesValueObject.VoteTypeNo,
-			romancesValueObject.VoteTypeYes,
-			romancesValueObject.VoteTypeCrush,
-			romancesValueObject.VoteTypeCompliment,
-		},
-		romancesValueObject.VoteTypeNo: {
-			romancesValueObject.VoteTypeYes,
-			romancesValueObject.VoteTypeCrush,
-			romancesValueObject.VoteTypeCompliment,
-		},
-		romancesValueObject.VoteTypeYes: {
-			romancesValueObject.VoteTypeCrush,
-			romancesValueObject.VoteTypeCompliment,
-		},
-		romancesValueObject.VoteTypeCrush:      {},
-		romancesValueObject.VoteTypeCompliment: {},
-	}
-
-	vals, ok := allowedVoteChangeOperations[oldVote.VoteType]
-	if !ok {
-		return false
-	}
-
-	for _, v := range vals {
-		if v == newVoteType {
-			return true
+		if transactionCountersChanges == nil && !r.config.Counters.UpdateMode.IsStream() && !countersChange.IsEmpty() {
+			r.countersRepository.ChangeVoteCounters(ctx, oldVote.Id, countersChange)
 		}
+
+		return romance.ActiveUserVote, nil
 	}
-	return false
 }
@@ -5,28 +5,44 @@ import (
 	"errors"
 	"fmt"
 	"github.bumble.dev/shcherbanich/user-votes-storage/config"
+	counterDomain "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter"
 	countersRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/repository"
+	countersValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/valueobject"
 	romanceDomain "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance"
 	romancesRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/repository"
+	romancesValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
 	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/messaging"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
 )
 
 type DeleteUserVoteOperation struct {
-	romancesRepository romancesRepo.RomancesRepository
-	countersRepository countersRepo.CountersRepository
-	logger             platform.Logger
+	romancesRepository   romancesRepo.RomancesRepository
+	countersRepository   countersRepo.CountersRepository
+	voteTransitionPolicy romancesValueObject.VoteTransitionPolicy
+	publisher            messaging.Publisher
+	config               config.Config
+	clock                platform.Clock
+	logger               platform.Logger
 }
 
 func NewDeleteUserVoteOperation(
 	romancesRepository romancesRepo.RomancesRepository,
 	countersRepository countersRepo.CountersRepository,
+	voteTransitionPolicy romancesValueObject.VoteTransitionPolicy,
+	publisher messaging.Publisher,
+	config config.Config,
+	clock platform.Clock,
 	logger platform.Logger,
 ) DeleteUserVoteOperation {
 	return DeleteUserVoteOperation{
-		romancesRepository: romancesRepository,
-		countersRepository: countersRepository,
-		logger:             logger,
+		romancesRepository:   romancesRepository,
+		countersRepository:   countersRepository,
+		voteTransitionPolicy: voteTransitionPolicy,
+		publisher:            publisher,
+		config:               config,
+		clock:                clock,
+		logger:               logger,
 	}
 }
 
@@ -41,7 +57,48 @@ func (r *DeleteUserVoteOperation) Run(ctx context.Context, voteId sharedValueObj
 			return err
 		}
 
-		err = r.romancesRepository.DeleteActiveUserVoteFromRomance(ctx, romance)
+		oldVoteType := romance.ActiveUserVote.VoteType
+		if !oldVoteType.IsEmpty() && !r.voteTransitionPolicy.IsAllowed(voteId.CountryId(), oldVoteType, romancesValueObject.VoteTypeEmpty) {
+			return romanceDomain.NewChangingVoteTypeError(oldVoteType, romancesValueObject.VoteTypeEmpty)
+		}
+
+		countersChange, err := getMovedVoteCountersChange(
+			romance.ActiveUserVote,
+			romancesValueObject.VoteTypeEmpty,
+			romance.PeerUserVote.VoteType,
+			r.clock.Now(),
+		)
+		if err != nil {
+			return err
+		}
+
+		var transactionCountersChanges []countersValueObject.VoteCountersChange
+		if countsVoteInTransaction(r.config.Counters) {
+			transactionCountersChanges = []countersValueObject.VoteCountersChange{countersChange}
+		}
+		countedAt := countersChange.UpdateGroup().HourStartTime()
+		voteCountedMessage := newVoteCountedMessage(
+			voteId,
+			oldVoteType,
+			romancesValueObject.VoteTypeEmpty,
+			romance.PeerUserVote.VoteType,
+			countedAt,
+			countedAt,
+		)
+
+		err = r.romancesRepository.DeleteActiveUserVoteFromRomanceWithCounters(
+			ctx,
+			romance,
+			voteCountedMessage.Id,
+			transactionCountersChanges,
+		)
+
+		if errors.Is(err, counterDomain.ErrCountersNotApplied) {
+			r.logger.Error(fmt.Sprintf("DeleteUserVoteFromRomance error: %+v", err))
+			return queueVoteCountersChange(
+				ctx, r.publisher, r.countersRepository, r.logger, voteCountedMessage, voteId, transactionCountersChanges,
+			)
+		}
 
 		if err != nil {
 			if errors.Is(err, romanceDomain.ErrVersionConflict) && tries < config.DynamoDbVersionConflictRetriesCount {
@@ -52,6 +109,10 @@ func (r *DeleteUserVoteOperation) Run(ctx context.Context, voteId sharedValueObj
 			return err
 		}
 
+		if transactionCountersChanges == nil && !r.config.Counters.UpdateMode.IsStream() && !countersChange.IsEmpty() {
+			r.countersRepository.ChangeVoteCounters(ctx, romance.ActiveUserVote.Id, countersChange)
+		}
+
 		return nil
 	}
 }
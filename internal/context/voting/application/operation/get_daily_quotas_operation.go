@@ -0,0 +1,61 @@
+package operation
+
+import (
+	"context"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota/entity"
+	quotasRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota/repository"
+	quotaValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota/valueobject"
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
+)
+
+type GetDailyQuotasOperation struct {
+	quotasRepository quotasRepo.QuotasRepository
+	quotaPolicy      quotaValueObject.QuotaPolicy
+	clock            platform.Clock
+}
+
+func NewGetDailyQuotasOperation(
+	quotasRepository quotasRepo.QuotasRepository,
+	quotaPolicy quotaValueObject.QuotaPolicy,
+	clock platform.Clock,
+) GetDailyQuotasOperation {
+	return GetDailyQuotasOperation{
+		quotasRepository: quotasRepository,
+		quotaPolicy:      quotaPolicy,
+		clock:            clock,
+	}
+}
+
+func (r *GetDailyQuotasOperation) Run(
+	ctx context.Context,
+	activeUserKey sharedValueObject.ActiveUserKey,
+) ([]entity.DailyQuota, error) {
+	voteTypes := r.quotaPolicy.LimitedVoteTypes(activeUserKey.CountryId())
+	if len(voteTypes) == 0 {
+		return []entity.DailyQuota{}, nil
+	}
+
+	day, err := quotaValueObject.NewQuotaDay(r.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := r.quotasRepository.GetDailyUsage(ctx, activeUserKey, day)
+	if err != nil {
+		return nil, err
+	}
+
+	quotas := make([]entity.DailyQuota, 0, len(voteTypes))
+	for _, voteType := range voteTypes {
+		limit, _ := r.quotaPolicy.DailyLimit(activeUserKey.CountryId(), voteType)
+		quotas = append(quotas, entity.DailyQuota{
+			VoteType: voteType,
+			Limit:    limit,
+			Used:     usage[voteType],
+			ResetsAt: day.EndTime(),
+		})
+	}
+
+	return quotas, nil
+}
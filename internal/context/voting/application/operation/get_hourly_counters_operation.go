@@ -6,25 +6,35 @@ import (
 	countersRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/repository"
 	countersValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/valueobject"
 	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
+	"time"
 )
 
 type GetHourlyCountersOperation struct {
 	countersRepository countersRepo.CountersRepository
+	clock              platform.Clock
 }
 
 func NewGetHourlyCountersOperation(
 	countersRepository countersRepo.CountersRepository,
+	clock platform.Clock,
 ) GetHourlyCountersOperation {
 	return GetHourlyCountersOperation{
 		countersRepository: countersRepository,
+		clock:              clock,
 	}
 }
 
 func (r *GetHourlyCountersOperation) Run(
 	ctx context.Context,
 	activeUserKey sharedValueObject.ActiveUserKey,
-	hoursOffsetGroups countersValueObject.HoursOffsetGroups,
+	hoursOffsets []uint8,
+	at time.Time,
 ) (map[uint8]*entity.CountersGroup, error) {
+	hoursOffsetGroups, err := countersValueObject.NewHoursOffsetGroups(hoursOffsets, at, r.clock.Now())
+	if err != nil {
+		return map[uint8]*entity.CountersGroup{}, err
+	}
 
 	countersGroups, err := r.countersRepository.GetHourlyCounters(
 		ctx,
@@ -0,0 +1,38 @@
+package operation
+
+import (
+	"context"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/entity"
+	countersRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/repository"
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+)
+
+type GetHourlyCountersSeriesOperation struct {
+	countersRepository countersRepo.CountersRepository
+}
+
+func NewGetHourlyCountersSeriesOperation(
+	countersRepository countersRepo.CountersRepository,
+) GetHourlyCountersSeriesOperation {
+	return GetHourlyCountersSeriesOperation{
+		countersRepository: countersRepository,
+	}
+}
+
+func (r *GetHourlyCountersSeriesOperation) Run(
+	ctx context.Context,
+	activeUserKey sharedValueObject.ActiveUserKey,
+	hoursCount uint8,
+) ([]entity.CountersGroup, error) {
+
+	countersGroups, err := r.countersRepository.GetHourlyCountersSeries(
+		ctx,
+		activeUserKey,
+		hoursCount,
+	)
+	if err != nil {
+		return []entity.CountersGroup{}, err
+	}
+
+	return countersGroups, nil
+}
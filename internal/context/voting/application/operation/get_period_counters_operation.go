@@ -0,0 +1,41 @@
+package operation
+
+import (
+	"context"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/entity"
+	countersRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/repository"
+	countersValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/valueobject"
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+)
+
+type GetPeriodCountersOperation struct {
+	countersRepository countersRepo.CountersRepository
+}
+
+func NewGetPeriodCountersOperation(
+	countersRepository countersRepo.CountersRepository,
+) GetPeriodCountersOperation {
+	return GetPeriodCountersOperation{
+		countersRepository: countersRepository,
+	}
+}
+
+func (r *GetPeriodCountersOperation) Run(
+	ctx context.Context,
+	activeUserKey sharedValueObject.ActiveUserKey,
+	period countersValueObject.CountersPeriod,
+	periodsCount uint16,
+) ([]entity.PeriodCounters, error) {
+
+	periodCounters, err := r.countersRepository.GetPeriodCounters(
+		ctx,
+		activeUserKey,
+		period,
+		periodsCount,
+	)
+	if err != nil {
+		return []entity.PeriodCounters{}, err
+	}
+
+	return periodCounters, nil
+}
@@ -0,0 +1,24 @@
+package operation
+
+import (
+	"context"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/entity"
+	romancesRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/repository"
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+)
+
+type GetRomancesOperation struct {
+	romancesRepository romancesRepo.RomancesRepository
+}
+
+func NewGetRomancesOperation(
+	romancesRepository romancesRepo.RomancesRepository,
+) GetRomancesOperation {
+	return GetRomancesOperation{
+		romancesRepository: romancesRepository,
+	}
+}
+
+func (r *GetRomancesOperation) Run(ctx context.Context, voteIds []sharedValueObject.VoteId) ([]entity.Romance, error) {
+	return r.romancesRepository.GetRomances(ctx, voteIds)
+}
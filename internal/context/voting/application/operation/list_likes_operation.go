@@ -0,0 +1,37 @@
+package operation
+
+import (
+	"context"
+	romanceDomain "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/entity"
+	romancesRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/repository"
+	romancesValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+)
+
+type ListLikesOperation struct {
+	romancesRepository romancesRepo.RomancesRepository
+}
+
+func NewListLikesOperation(
+	romancesRepository romancesRepo.RomancesRepository,
+) ListLikesOperation {
+	return ListLikesOperation{
+		romancesRepository: romancesRepository,
+	}
+}
+
+func (r *ListLikesOperation) Run(
+	ctx context.Context,
+	activeUserKey sharedValueObject.ActiveUserKey,
+	peerVoteTypes []romancesValueObject.VoteType,
+	pageRequest sharedValueObject.PageRequest,
+) (entity.RomancesPage, error) {
+	for _, voteType := range peerVoteTypes {
+		if !voteType.IsPositive() {
+			return entity.RomancesPage{}, romanceDomain.ErrWrongVote
+		}
+	}
+
+	return r.romancesRepository.ListUserLikes(ctx, activeUserKey, peerVoteTypes, pageRequest)
+}
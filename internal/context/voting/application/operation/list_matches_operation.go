@@ -0,0 +1,28 @@
+package operation
+
+import (
+	"context"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/entity"
+	romancesRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/repository"
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+)
+
+type ListMatchesOperation struct {
+	romancesRepository romancesRepo.RomancesRepository
+}
+
+func NewListMatchesOperation(
+	romancesRepository romancesRepo.RomancesRepository,
+) ListMatchesOperation {
+	return ListMatchesOperation{
+		romancesRepository: romancesRepository,
+	}
+}
+
+func (r *ListMatchesOperation) Run(
+	ctx context.Context,
+	activeUserKey sharedValueObject.ActiveUserKey,
+	pageRequest sharedValueObject.PageRequest,
+) (entity.RomancesPage, error) {
+	return r.romancesRepository.ListUserMatches(ctx, activeUserKey, pageRequest)
+}
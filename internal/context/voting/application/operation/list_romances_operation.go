@@ -0,0 +1,52 @@
+package operation
+
+import (
+	"context"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/entity"
+	romancesRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/repository"
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+)
+
+type ListRomancesOperation struct {
+	romancesRepository romancesRepo.RomancesRepository
+}
+
+func NewListRomancesOperation(
+	romancesRepository romancesRepo.RomancesRepository,
+) ListRomancesOperation {
+	return ListRomancesOperation{
+		romancesRepository: romancesRepository,
+	}
+}
+
+// Run hides the blocked romances from both users and reads on until the page is full or there is nothing left.
+func (r *ListRomancesOperation) Run(
+	ctx context.Context,
+	activeUserKey sharedValueObject.ActiveUserKey,
+	pageRequest sharedValueObject.PageRequest,
+) (entity.RomancesPage, error) {
+	result := entity.RomancesPage{Romances: []entity.Romance{}}
+	for {
+		page, err := r.romancesRepository.ListUserRomances(ctx, activeUserKey, pageRequest)
+		if err != nil {
+			return entity.RomancesPage{}, err
+		}
+
+		for _, romance := range page.Romances {
+			if !romance.IsBlocked() {
+				result.Romances = append(result.Romances, romance)
+			}
+		}
+		result.NextCursor = page.NextCursor
+
+		remaining := pageRequest.Limit() - int32(len(result.Romances))
+		if !page.HasMore() || remaining <= 0 {
+			return result, nil
+		}
+
+		pageRequest, err = sharedValueObject.NewPageRequest(remaining, page.NextCursor)
+		if err != nil {
+			return entity.RomancesPage{}, err
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package operation
+
+import (
+	"context"
+	countersRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/repository"
+	countersValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/valueobject"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/entity"
+	romancesValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
+	"github.com/google/uuid"
+)
+
+// ProjectRomanceCountersOperation keeps the counters as a projection of the romance changes in the stream mode.
+type ProjectRomanceCountersOperation struct {
+	countersRepository countersRepo.CountersRepository
+	clock              platform.Clock
+}
+
+func NewProjectRomanceCountersOperation(
+	countersRepository countersRepo.CountersRepository,
+	clock platform.Clock,
+) ProjectRomanceCountersOperation {
+	return ProjectRomanceCountersOperation{
+		countersRepository: countersRepository,
+		clock:              clock,
+	}
+}
+
+func (r *ProjectRomanceCountersOperation) Run(ctx context.Context, romanceChange entity.RomanceChange) error {
+	votes := []struct {
+		oldVote      entity.Vote
+		newVote      entity.Vote
+		peerVoteType romancesValueObject.VoteType
+	}{
+		{
+			oldVote:      romanceChange.Old.ActiveUserVote,
+			newVote:      romanceChange.New.ActiveUserVote,
+			peerVoteType: romanceChange.Old.PeerUserVote.VoteType,
+		},
+		// the peer vote is counted after the active user vote, so the matches are not counted twice
+		{
+			oldVote:      romanceChange.Old.PeerUserVote,
+			newVote:      romanceChange.New.PeerUserVote,
+			peerVoteType: romanceChange.New.ActiveUserVote.VoteType,
+		},
+	}
+
+	now := r.clock.Now()
+	for i, vote := range votes {
+		if vote.oldVote.VoteType == vote.newVote.VoteType {
+			continue
+		}
+
+		newVoteGroup, err := getVoteCounterUpdateGroup(vote.newVote, now)
+		if err != nil {
+			return err
+		}
+		oldVoteGroup, err := getVoteCounterUpdateGroup(vote.oldVote, now)
+		if err != nil {
+			return err
+		}
+
+		countersChanges := []countersValueObject.VoteCountersChange{
+			countersValueObject.NewVoteTypeCountersChange(newVoteGroup, vote.oldVote.VoteType, vote.newVote.VoteType, vote.peerVoteType).Increments(),
+			countersValueObject.NewVoteTypeCountersChange(oldVoteGroup, vote.oldVote.VoteType, vote.newVote.VoteType, vote.peerVoteType).Decrements(),
+		}
+		for j, countersChange := range countersChanges {
+			if countersChange.IsEmpty() {
+				continue
+			}
+			changeId := uuid.NewSHA1(romanceChange.Id, []byte{byte(i), byte(j)})
+			err = r.countersRepository.ApplyVoteCountersChange(
+				ctx, changeId, vote.newVote.Id, []countersValueObject.VoteCountersChange{countersChange},
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
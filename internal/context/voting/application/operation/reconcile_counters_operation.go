@@ -0,0 +1,141 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.bumble.dev/shcherbanich/user-votes-storage/config"
+	counterDomain "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter"
+	counterEntity "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/entity"
+	countersRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/repository"
+	romancesRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/repository"
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/messaging"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
+)
+
+const ReconcileCountersTopic = messaging.Topic("reconcile-counters")
+
+// CountersReconciliation compares the stored lifetime counters of the user with the ones recomputed from the romances.
+type CountersReconciliation struct {
+	Stored    counterEntity.CountersGroup
+	Actual    counterEntity.CountersGroup
+	Corrected bool
+}
+
+func (c CountersReconciliation) HasDrift() bool {
+	return c.Stored.IncomingYes != c.Actual.IncomingYes ||
+		c.Stored.IncomingNo != c.Actual.IncomingNo ||
+		c.Stored.OutgoingYes != c.Actual.OutgoingYes ||
+		c.Stored.OutgoingNo != c.Actual.OutgoingNo ||
+		c.Stored.IncomingByVoteType != c.Actual.IncomingByVoteType ||
+		c.Stored.OutgoingByVoteType != c.Actual.OutgoingByVoteType ||
+		c.Stored.Matches != c.Actual.Matches
+}
+
+type ReconcileCountersOperation struct {
+	romancesRepository romancesRepo.RomancesRepository
+	countersRepository countersRepo.CountersRepository
+	logger             platform.Logger
+}
+
+func NewReconcileCountersOperation(
+	romancesRepository romancesRepo.RomancesRepository,
+	countersRepository countersRepo.CountersRepository,
+	logger platform.Logger,
+) ReconcileCountersOperation {
+	return ReconcileCountersOperation{
+		romancesRepository: romancesRepository,
+		countersRepository: countersRepository,
+		logger:             logger,
+	}
+}
+
+// Run recomputes the lifetime counters of the user from the romances and rewrites the stored ones when they drifted.
+func (r *ReconcileCountersOperation) Run(
+	ctx context.Context,
+	activeUserKey sharedValueObject.ActiveUserKey,
+	rewrite bool,
+) (CountersReconciliation, error) {
+	tries := 0
+
+	for {
+		reconciliation, err := r.compare(ctx, activeUserKey)
+		if err != nil {
+			return CountersReconciliation{}, err
+		}
+
+		if !reconciliation.HasDrift() || !rewrite {
+			return reconciliation, nil
+		}
+
+		err = r.countersRepository.ReplaceLifetimeCounter(ctx, reconciliation.Stored, reconciliation.Actual)
+		if err != nil {
+			if errors.Is(err, counterDomain.ErrCountersChanged) && tries < config.DynamoDbVersionConflictRetriesCount {
+				tries += 1
+				continue
+			}
+			r.logger.Error(fmt.Sprintf("ReplaceLifetimeCounter error: %+v", err))
+			return CountersReconciliation{}, err
+		}
+
+		reconciliation.Corrected = true
+		return reconciliation, nil
+	}
+}
+
+func (r *ReconcileCountersOperation) compare(
+	ctx context.Context,
+	activeUserKey sharedValueObject.ActiveUserKey,
+) (CountersReconciliation, error) {
+	stored, err := r.countersRepository.GetLifetimeCounter(ctx, activeUserKey)
+	if err != nil {
+		r.logger.Error(fmt.Sprintf("GetLifetimeCounter error: %+v", err))
+		return CountersReconciliation{}, err
+	}
+	stored.ActiveUserKey = activeUserKey
+
+	actual := counterEntity.CountersGroup{ActiveUserKey: activeUserKey}
+	cursor := ""
+	for {
+		pageRequest, err := sharedValueObject.NewPageRequest(sharedValueObject.MaxPageLimit, cursor)
+		if err != nil {
+			return CountersReconciliation{}, err
+		}
+
+		page, err := r.romancesRepository.ListUserRomances(ctx, activeUserKey, pageRequest)
+		if err != nil {
+			r.logger.Error(fmt.Sprintf("ListUserRomances error: %+v", err))
+			return CountersReconciliation{}, err
+		}
+
+		for _, romance := range page.Romances {
+			switch {
+			case romance.ActiveUserVote.VoteType.IsPositive():
+				actual.OutgoingYes++
+			case romance.ActiveUserVote.VoteType.IsNegative():
+				actual.OutgoingNo++
+			}
+
+			switch {
+			case romance.PeerUserVote.VoteType.IsPositive():
+				actual.IncomingYes++
+			case romance.PeerUserVote.VoteType.IsNegative():
+				actual.IncomingNo++
+			}
+
+			actual.OutgoingByVoteType.Incr(romance.ActiveUserVote.VoteType)
+			actual.IncomingByVoteType.Incr(romance.PeerUserVote.VoteType)
+			if romance.ActiveUserVote.VoteType.IsPositive() && romance.PeerUserVote.VoteType.IsPositive() {
+				actual.Matches++
+			}
+		}
+
+		if !page.HasMore() {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	return CountersReconciliation{Stored: stored, Actual: actual}, nil
+}
@@ -0,0 +1,51 @@
+package operation
+
+import (
+	"context"
+	"fmt"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/application/messaging/message"
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/messaging"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
+)
+
+type RequestCountersReconciliationOperation struct {
+	publisher messaging.Publisher
+	logger    platform.Logger
+}
+
+func NewRequestCountersReconciliationOperation(
+	publisher messaging.Publisher,
+	logger platform.Logger,
+) RequestCountersReconciliationOperation {
+	return RequestCountersReconciliationOperation{
+		publisher: publisher,
+		logger:    logger,
+	}
+}
+
+func (r *RequestCountersReconciliationOperation) RunForUser(
+	ctx context.Context,
+	userKey sharedValueObject.ActiveUserKey,
+	rewrite bool,
+) error {
+	r.logger.Debug("Publishing new ReconcileCountersMessage message")
+	return r.publisher.Publish(ReconcileCountersTopic, message.NewUserReconcileCountersMessage(userKey, rewrite))
+}
+
+// RunForCountry publishes one message per segment of the romances table scan in the region of the country.
+func (r *RequestCountersReconciliationOperation) RunForCountry(
+	ctx context.Context,
+	countryId uint16,
+	totalSegments int32,
+	rewrite bool,
+) error {
+	r.logger.Debug(fmt.Sprintf("Publishing %d new ReconcileCountersMessage messages", totalSegments))
+	for segment := range totalSegments {
+		msg := message.NewSegmentReconcileCountersMessage(countryId, segment, totalSegments, rewrite)
+		if err := r.publisher.Publish(ReconcileCountersTopic, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
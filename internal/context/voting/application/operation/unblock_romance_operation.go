@@ -0,0 +1,61 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.bumble.dev/shcherbanich/user-votes-storage/config"
+	romanceDomain "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance"
+	romancesRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/repository"
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
+)
+
+type UnblockRomanceOperation struct {
+	romancesRepository romancesRepo.RomancesRepository
+	logger             platform.Logger
+}
+
+func NewUnblockRomanceOperation(
+	romancesRepository romancesRepo.RomancesRepository,
+	logger platform.Logger,
+) UnblockRomanceOperation {
+	return UnblockRomanceOperation{
+		romancesRepository: romancesRepository,
+		logger:             logger,
+	}
+}
+
+// Run lifts the block of the active user, only the user who blocked the peer can lift it.
+func (r *UnblockRomanceOperation) Run(ctx context.Context, voteId sharedValueObject.VoteId) error {
+	tries := 0
+
+	getRomanceOperation := NewGetRomanceOperation(r.romancesRepository)
+	for {
+		romance, err := getRomanceOperation.Run(ctx, voteId)
+		if err != nil {
+			r.logger.Error(fmt.Sprintf("GetRomance error: %+v", err))
+			return err
+		}
+
+		if !romance.IsBlocked() {
+			return nil
+		}
+
+		if !romance.IsBlockedByActiveUser() {
+			return romanceDomain.ErrRomanceBlocked
+		}
+
+		_, err = r.romancesRepository.UnblockRomance(ctx, romance)
+		if err != nil {
+			if errors.Is(err, romanceDomain.ErrVersionConflict) && tries < config.DynamoDbVersionConflictRetriesCount {
+				tries += 1
+				continue
+			}
+			r.logger.Error(fmt.Sprintf("UnblockRomance error: %+v", err))
+			return err
+		}
+
+		return nil
+	}
+}
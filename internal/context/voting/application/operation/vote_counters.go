@@ -0,0 +1,79 @@
+package operation
+
+import (
+	"context"
+	"fmt"
+	"github.bumble.dev/shcherbanich/user-votes-storage/config"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/application/messaging/message"
+	countersRepo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/repository"
+	countersValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/valueobject"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/entity"
+	romancesValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/messaging"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
+	"time"
+)
+
+func getVoteCounterUpdateGroup(vote entity.Vote, now time.Time) (countersValueObject.CounterUpdateGroup, error) {
+	countedAt := now
+	if vote.CreatedAt != nil {
+		countedAt = *vote.CreatedAt
+	} else if vote.VotedAt != nil {
+		countedAt = *vote.VotedAt
+	}
+
+	return countersValueObject.NewCounterUpdateGroup(countedAt)
+}
+
+func countsVoteInTransaction(countersConfig config.CountersConfig) bool {
+	return !countersConfig.Buffer.Enabled && !countersConfig.UpdateMode.IsAsync() && !countersConfig.UpdateMode.IsStream()
+}
+
+func getMovedVoteCountersChange(
+	oldVote entity.Vote,
+	newVoteType romancesValueObject.VoteType,
+	peerVoteType romancesValueObject.VoteType,
+	now time.Time,
+) (countersValueObject.VoteCountersChange, error) {
+	counterUpdateGroup, err := getVoteCounterUpdateGroup(oldVote, now)
+	if err != nil {
+		return countersValueObject.VoteCountersChange{}, err
+	}
+
+	return countersValueObject.NewVoteTypeCountersChange(counterUpdateGroup, oldVote.VoteType, newVoteType, peerVoteType), nil
+}
+
+func newVoteCountedMessage(
+	voteId sharedValueObject.VoteId,
+	oldVoteType romancesValueObject.VoteType,
+	voteType romancesValueObject.VoteType,
+	peerVoteType romancesValueObject.VoteType,
+	countedAt time.Time,
+	replacedCountedAt time.Time,
+) *message.VoteCountedMessage {
+	voteCountedMessage := message.NewVoteCountedMessage(voteId, oldVoteType, voteType, peerVoteType, countedAt)
+	if oldVoteType.IsEmpty() {
+		return voteCountedMessage
+	}
+
+	return voteCountedMessage.WithReplacedCountedAt(replacedCountedAt)
+}
+
+func queueVoteCountersChange(
+	ctx context.Context,
+	publisher messaging.Publisher,
+	countersRepository countersRepo.CountersRepository,
+	logger platform.Logger,
+	voteCountedMessage *message.VoteCountedMessage,
+	voteId sharedValueObject.VoteId,
+	countersChanges []countersValueObject.VoteCountersChange,
+) error {
+	err := publisher.Publish(VoteCountedTopic, voteCountedMessage)
+	if err == nil {
+		return nil
+	}
+	logger.Error(fmt.Sprintf("Publishing VoteCountedMessage error: %+v", err))
+
+	return countersRepository.ApplyVoteCountersChange(ctx, voteCountedMessage.Id, voteId, countersChanges)
+}
@@ -0,0 +1,38 @@
+package operation
+
+import (
+	quotaDomain "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota"
+	quotaValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota/valueobject"
+	romancesValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	"time"
+)
+
+func getQuotaConsumption(
+	quotaPolicy quotaValueObject.QuotaPolicy,
+	voteId sharedValueObject.VoteId,
+	voteType romancesValueObject.VoteType,
+	now time.Time,
+) (*quotaValueObject.QuotaConsumption, error) {
+	limit, ok := quotaPolicy.DailyLimit(voteId.CountryId(), voteType)
+	if !ok {
+		return nil, nil
+	}
+
+	if limit == 0 {
+		return nil, quotaDomain.NewQuotaExceededError(voteType, limit)
+	}
+
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(voteId.CountryId(), voteId.ActiveUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	day, err := quotaValueObject.NewQuotaDay(now)
+	if err != nil {
+		return nil, err
+	}
+
+	consumption := quotaValueObject.NewQuotaConsumption(activeUserKey, day, voteType, limit)
+	return &consumption, nil
+}
@@ -5,6 +5,7 @@ import (
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/application/operation"
 	counterEntity "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/entity"
 	countersValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/valueobject"
+	quotaEntity "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota/entity"
 	romanceEntity "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/entity"
 	romancesValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
 	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
@@ -13,38 +14,71 @@ import (
 )
 
 type VotingService struct {
-	addUserVoteOperation         operation.AddUserVoteOperation
-	deleteUserVoteOperation      operation.DeleteUserVoteOperation
-	getUserVoteOperation         operation.GetUserVoteOperation
-	changeUserVoteOperation      operation.ChangeUserVoteOperation
-	getRomanceOperation          operation.GetRomanceOperation
-	deleteRomanceOperation       operation.DeleteRomanceOperation
-	deleteRomancesOperation      operation.DeleteRomancesOperation
-	getLifetimeCountersOperation operation.GetLifetimeCountersOperation
-	getHourlyCountersOperation   operation.GetHourlyCountersOperation
+	addUserVoteOperation             operation.AddUserVoteOperation
+	addUserVotesOperation            operation.AddUserVotesOperation
+	deleteUserVoteOperation          operation.DeleteUserVoteOperation
+	getUserVoteOperation             operation.GetUserVoteOperation
+	changeUserVoteOperation          operation.ChangeUserVoteOperation
+	getRomanceOperation              operation.GetRomanceOperation
+	getRomancesOperation             operation.GetRomancesOperation
+	deleteRomanceOperation           operation.DeleteRomanceOperation
+	deleteRomancesOperation          operation.DeleteRomancesOperation
+	blockRomanceOperation            operation.BlockRomanceOperation
+	unblockRomanceOperation          operation.UnblockRomanceOperation
+	listRomancesOperation            operation.ListRomancesOperation
+	listMatchesOperation             operation.ListMatchesOperation
+	listLikesOperation               operation.ListLikesOperation
+	getLifetimeCountersOperation     operation.GetLifetimeCountersOperation
+	getHourlyCountersOperation       operation.GetHourlyCountersOperation
+	getHourlyCountersSeriesOperation operation.GetHourlyCountersSeriesOperation
+	getPeriodCountersOperation       operation.GetPeriodCountersOperation
+	getDailyQuotasOperation          operation.GetDailyQuotasOperation
+	requestCountersReconciliation    operation.RequestCountersReconciliationOperation
 }
 
 func NewVotingService(
 	addUserVoteOperation operation.AddUserVoteOperation,
+	addUserVotesOperation operation.AddUserVotesOperation,
 	getUserVoteOperation operation.GetUserVoteOperation,
 	deleteUserVoteOperation operation.DeleteUserVoteOperation,
 	changeUserVoteOperation operation.ChangeUserVoteOperation,
 	getRomanceOperation operation.GetRomanceOperation,
+	getRomancesOperation operation.GetRomancesOperation,
 	deleteRomanceOperation operation.DeleteRomanceOperation,
 	deleteRomancesOperation operation.DeleteRomancesOperation,
+	blockRomanceOperation operation.BlockRomanceOperation,
+	unblockRomanceOperation operation.UnblockRomanceOperation,
+	listRomancesOperation operation.ListRomancesOperation,
+	listMatchesOperation operation.ListMatchesOperation,
+	listLikesOperation operation.ListLikesOperation,
 	getLifetimeCountersOperation operation.GetLifetimeCountersOperation,
 	getHourlyCountersOperation operation.GetHourlyCountersOperation,
+	getHourlyCountersSeriesOperation operation.GetHourlyCountersSeriesOperation,
+	getPeriodCountersOperation operation.GetPeriodCountersOperation,
+	getDailyQuotasOperation operation.GetDailyQuotasOperation,
+	requestCountersReconciliation operation.RequestCountersReconciliationOperation,
 ) VotingService {
 	return VotingService{
-		addUserVoteOperation:         addUserVoteOperation,
-		getUserVoteOperation:         getUserVoteOperation,
-		deleteUserVoteOperation:      deleteUserVoteOperation,
-		changeUserVoteOperation:      changeUserVoteOperation,
-		getRomanceOperation:          getRomanceOperation,
-		deleteRomanceOperation:       deleteRomanceOperation,
-		deleteRomancesOperation:      deleteRomancesOperation,
-		getLifetimeCountersOperation: getLifetimeCountersOperation,
-		getHourlyCountersOperation:   getHourlyCountersOperation,
+		addUserVoteOperation:             addUserVoteOperation,
+		addUserVotesOperation:            addUserVotesOperation,
+		getUserVoteOperation:             getUserVoteOperation,
+		deleteUserVoteOperation:          deleteUserVoteOperation,
+		changeUserVoteOperation:          changeUserVoteOperation,
+		getRomanceOperation:              getRomanceOperation,
+		getRomancesOperation:             getRomancesOperation,
+		deleteRomanceOperation:           deleteRomanceOperation,
+		deleteRomancesOperation:          deleteRomancesOperation,
+		blockRomanceOperation:            blockRomanceOperation,
+		unblockRomanceOperation:          unblockRomanceOperation,
+		listRomancesOperation:            listRomancesOperation,
+		listMatchesOperation:             listMatchesOperation,
+		listLikesOperation:               listLikesOperation,
+		getLifetimeCountersOperation:     getLifetimeCountersOperation,
+		getHourlyCountersOperation:       getHourlyCountersOperation,
+		getHourlyCountersSeriesOperation: getHourlyCountersSeriesOperation,
+		getPeriodCountersOperation:       getPeriodCountersOperation,
+		getDailyQuotasOperation:          getDailyQuotasOperation,
+		requestCountersReconciliation:    requestCountersReconciliation,
 	}
 }
 
@@ -52,18 +86,48 @@ func (v *VotingService) AddUserVote(ctx context.Context, command command.VoteAdd
 	voteId, err := sharedValueObject.NewVoteId(
 		command.CountryId,
 		command.Body.ActiveUserId,
+		getPeerCountryId(command.CountryId, command.Body.PeerCountryId),
 		command.Body.PeerId,
 	)
 	if err != nil {
 		return romanceEntity.Vote{}, err
 	}
-	return v.addUserVoteOperation.Run(ctx, voteId, romancesValueObject.VoteType(command.Body.VoteType), command.Body.VotedAt)
+	return v.addUserVoteOperation.Run(
+		ctx,
+		voteId,
+		romancesValueObject.VoteType(command.Body.VoteType),
+		romancesValueObject.ComplimentMessage(command.Body.Message),
+		command.Body.VotedAt,
+	)
+}
+
+func (v *VotingService) AddUserVotes(ctx context.Context, command command.VotesBatchAdd) ([]operation.AddUserVoteResult, error) {
+	votes := make([]operation.UserVoteToAdd, 0, len(command.Body.Votes))
+	for _, vote := range command.Body.Votes {
+		voteId, err := sharedValueObject.NewVoteId(
+			command.CountryId,
+			vote.ActiveUserId,
+			getPeerCountryId(command.CountryId, vote.PeerCountryId),
+			vote.PeerId,
+		)
+		if err != nil {
+			return nil, err
+		}
+		votes = append(votes, operation.UserVoteToAdd{
+			VoteId:   voteId,
+			VoteType: romancesValueObject.VoteType(vote.VoteType),
+			Message:  romancesValueObject.ComplimentMessage(vote.Message),
+			VotedAt:  vote.VotedAt,
+		})
+	}
+	return v.addUserVotesOperation.Run(ctx, votes), nil
 }
 
 func (v *VotingService) GetUserVote(ctx context.Context, get query.VoteGet) (romanceEntity.Vote, error) {
 	voteId, err := sharedValueObject.NewVoteId(
 		get.CountryId,
 		get.ActiveUserId,
+		getPeerCountryId(get.CountryId, get.PeerCountryId),
 		get.PeerId,
 	)
 	if err != nil {
@@ -76,6 +140,7 @@ func (v *VotingService) DeleteUserVote(ctx context.Context, command command.Dele
 	voteId, err := sharedValueObject.NewVoteId(
 		command.CountryId,
 		command.ActiveUserId,
+		getPeerCountryId(command.CountryId, command.PeerCountryId),
 		command.PeerId,
 	)
 	if err != nil {
@@ -88,18 +153,25 @@ func (v *VotingService) ChangeUserVote(ctx context.Context, command command.Chan
 	voteId, err := sharedValueObject.NewVoteId(
 		command.CountryId,
 		command.ActiveUserId,
+		getPeerCountryId(command.CountryId, command.PeerCountryId),
 		command.PeerId,
 	)
 	if err != nil {
 		return romanceEntity.Vote{}, err
 	}
-	return v.changeUserVoteOperation.Run(ctx, voteId, romancesValueObject.VoteType(command.Body.NewType))
+	return v.changeUserVoteOperation.Run(
+		ctx,
+		voteId,
+		romancesValueObject.VoteType(command.Body.NewType),
+		romancesValueObject.ComplimentMessage(command.Body.Message),
+	)
 }
 
 func (v *VotingService) GetRomance(ctx context.Context, get query.RomanceGet) (romanceEntity.Romance, error) {
 	voteId, err := sharedValueObject.NewVoteId(
 		get.CountryId,
 		get.ActiveUserId,
+		getPeerCountryId(get.CountryId, get.PeerCountryId),
 		get.PeerId,
 	)
 	if err != nil {
@@ -108,10 +180,28 @@ func (v *VotingService) GetRomance(ctx context.Context, get query.RomanceGet) (r
 	return v.getRomanceOperation.Run(ctx, voteId)
 }
 
+func (v *VotingService) BatchGetRomances(ctx context.Context, get query.RomancesBatchGet) ([]romanceEntity.Romance, error) {
+	voteIds := make([]sharedValueObject.VoteId, 0, len(get.Body.PeerIds))
+	for _, peerId := range get.Body.PeerIds {
+		voteId, err := sharedValueObject.NewVoteId(
+			get.CountryId,
+			get.ActiveUserId,
+			getPeerCountryId(get.CountryId, get.PeerCountryId),
+			peerId,
+		)
+		if err != nil {
+			return nil, err
+		}
+		voteIds = append(voteIds, voteId)
+	}
+	return v.getRomancesOperation.Run(ctx, voteIds)
+}
+
 func (v *VotingService) DeleteRomance(ctx context.Context, command command.DeleteRomance) error {
 	voteId, err := sharedValueObject.NewVoteId(
 		command.CountryId,
 		command.ActiveUserId,
+		getPeerCountryId(command.CountryId, command.PeerCountryId),
 		command.PeerId,
 	)
 	if err != nil {
@@ -120,6 +210,32 @@ func (v *VotingService) DeleteRomance(ctx context.Context, command command.Delet
 	return v.deleteRomanceOperation.Run(ctx, voteId)
 }
 
+func (v *VotingService) BlockRomance(ctx context.Context, command command.BlockRomance) error {
+	voteId, err := sharedValueObject.NewVoteId(
+		command.CountryId,
+		command.ActiveUserId,
+		getPeerCountryId(command.CountryId, command.PeerCountryId),
+		command.PeerId,
+	)
+	if err != nil {
+		return err
+	}
+	return v.blockRomanceOperation.Run(ctx, voteId)
+}
+
+func (v *VotingService) UnblockRomance(ctx context.Context, command command.UnblockRomance) error {
+	voteId, err := sharedValueObject.NewVoteId(
+		command.CountryId,
+		command.ActiveUserId,
+		getPeerCountryId(command.CountryId, command.PeerCountryId),
+		command.PeerId,
+	)
+	if err != nil {
+		return err
+	}
+	return v.unblockRomanceOperation.Run(ctx, voteId)
+}
+
 func (v *VotingService) DeleteRomances(ctx context.Context, command command.DeleteRomances) error {
 	userKey, err := sharedValueObject.NewActiveUserKey(
 		command.CountryId,
@@ -131,6 +247,54 @@ func (v *VotingService) DeleteRomances(ctx context.Context, command command.Dele
 	return v.deleteRomancesOperation.Run(ctx, userKey)
 }
 
+func (v *VotingService) ListRomances(ctx context.Context, query query.RomancesList) (romanceEntity.RomancesPage, error) {
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(
+		query.CountryId,
+		query.ActiveUserId,
+	)
+	if err != nil {
+		return romanceEntity.RomancesPage{}, err
+	}
+
+	pageRequest, err := sharedValueObject.NewPageRequest(query.Limit, query.Cursor)
+	if err != nil {
+		return romanceEntity.RomancesPage{}, err
+	}
+	return v.listRomancesOperation.Run(ctx, activeUserKey, pageRequest)
+}
+
+func (v *VotingService) ListMatches(ctx context.Context, query query.MatchesList) (romanceEntity.RomancesPage, error) {
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(
+		query.CountryId,
+		query.ActiveUserId,
+	)
+	if err != nil {
+		return romanceEntity.RomancesPage{}, err
+	}
+
+	pageRequest, err := sharedValueObject.NewPageRequest(query.Limit, query.Cursor)
+	if err != nil {
+		return romanceEntity.RomancesPage{}, err
+	}
+	return v.listMatchesOperation.Run(ctx, activeUserKey, pageRequest)
+}
+
+func (v *VotingService) ListLikes(ctx context.Context, query query.LikesList) (romanceEntity.RomancesPage, error) {
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(
+		query.CountryId,
+		query.ActiveUserId,
+	)
+	if err != nil {
+		return romanceEntity.RomancesPage{}, err
+	}
+
+	pageRequest, err := sharedValueObject.NewPageRequest(query.Limit, query.Cursor)
+	if err != nil {
+		return romanceEntity.RomancesPage{}, err
+	}
+	return v.listLikesOperation.Run(ctx, activeUserKey, query.PeerVoteTypes, pageRequest)
+}
+
 func (v *VotingService) GetLifetimeCounters(ctx context.Context, query query.LifetimeCountersGet) (counterEntity.CountersGroup, error) {
 	activeUserKey, err := sharedValueObject.NewActiveUserKey(
 		query.CountryId,
@@ -142,6 +306,21 @@ func (v *VotingService) GetLifetimeCounters(ctx context.Context, query query.Lif
 	return v.getLifetimeCountersOperation.Run(ctx, activeUserKey)
 }
 
+func (v *VotingService) ReconcileUserCounters(ctx context.Context, command command.ReconcileUserCounters) error {
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(
+		command.CountryId,
+		command.ActiveUserId,
+	)
+	if err != nil {
+		return err
+	}
+	return v.requestCountersReconciliation.RunForUser(ctx, activeUserKey, command.Body.Rewrite)
+}
+
+func (v *VotingService) ReconcileCountryCounters(ctx context.Context, command command.ReconcileCountryCounters) error {
+	return v.requestCountersReconciliation.RunForCountry(ctx, command.CountryId, command.Body.TotalSegments, command.Body.Rewrite)
+}
+
 func (v *VotingService) GetHourlyCounters(ctx context.Context, query query.HourlyCountersGet) (map[uint8]*counterEntity.CountersGroup, error) {
 	activeUserKey, err := sharedValueObject.NewActiveUserKey(
 		query.CountryId,
@@ -150,10 +329,56 @@ func (v *VotingService) GetHourlyCounters(ctx context.Context, query query.Hourl
 	if err != nil {
 		return map[uint8]*counterEntity.CountersGroup{}, err
 	}
+	return v.getHourlyCountersOperation.Run(ctx, activeUserKey, query.HoursOffsetGroups, query.At)
+}
 
-	hoursOffsetGroups, err := countersValueObject.NewHoursOffsetGroups(query.HoursOffsetGroups)
+func (v *VotingService) GetHourlyCountersSeries(ctx context.Context, query query.HourlyCountersSeriesGet) ([]counterEntity.CountersGroup, error) {
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(
+		query.CountryId,
+		query.ActiveUserId,
+	)
 	if err != nil {
-		return map[uint8]*counterEntity.CountersGroup{}, err
+		return []counterEntity.CountersGroup{}, err
+	}
+	return v.getHourlyCountersSeriesOperation.Run(ctx, activeUserKey, query.Hours)
+}
+
+func (v *VotingService) GetDailyCounters(ctx context.Context, query query.DailyCountersGet) ([]counterEntity.PeriodCounters, error) {
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(
+		query.CountryId,
+		query.ActiveUserId,
+	)
+	if err != nil {
+		return []counterEntity.PeriodCounters{}, err
+	}
+	return v.getPeriodCountersOperation.Run(ctx, activeUserKey, countersValueObject.CountersPeriodDay, query.Days)
+}
+
+func (v *VotingService) GetWeeklyCounters(ctx context.Context, query query.WeeklyCountersGet) ([]counterEntity.PeriodCounters, error) {
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(
+		query.CountryId,
+		query.ActiveUserId,
+	)
+	if err != nil {
+		return []counterEntity.PeriodCounters{}, err
+	}
+	return v.getPeriodCountersOperation.Run(ctx, activeUserKey, countersValueObject.CountersPeriodWeek, query.Weeks)
+}
+
+func (v *VotingService) GetDailyQuotas(ctx context.Context, query query.DailyQuotasGet) ([]quotaEntity.DailyQuota, error) {
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(
+		query.CountryId,
+		query.ActiveUserId,
+	)
+	if err != nil {
+		return []quotaEntity.DailyQuota{}, err
+	}
+	return v.getDailyQuotasOperation.Run(ctx, activeUserKey)
+}
+
+func getPeerCountryId(countryId uint16, peerCountryId uint16) uint16 {
+	if peerCountryId == 0 {
+		return countryId
 	}
-	return v.getHourlyCountersOperation.Run(ctx, activeUserKey, hoursOffsetGroups)
+	return peerCountryId
 }
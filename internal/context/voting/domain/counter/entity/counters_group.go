@@ -5,10 +5,13 @@ import (
 )
 
 type CountersGroup struct {
-	ActiveUserKey     sharedValueObject.ActiveUserKey
-	HourUnixTimestamp int32
-	IncomingYes       uint32
-	IncomingNo        uint32
-	OutgoingYes       uint32
-	OutgoingNo        uint32
+	ActiveUserKey      sharedValueObject.ActiveUserKey
+	HourUnixTimestamp  int32
+	IncomingYes        uint32
+	IncomingNo         uint32
+	OutgoingYes        uint32
+	OutgoingNo         uint32
+	IncomingByVoteType VoteTypeCounters
+	OutgoingByVoteType VoteTypeCounters
+	Matches            uint32
 }
@@ -0,0 +1,19 @@
+package entity
+
+import (
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	"time"
+)
+
+// PeriodCounters holds the counters of the user rolled up over a day or a week.
+type PeriodCounters struct {
+	ActiveUserKey      sharedValueObject.ActiveUserKey
+	PeriodStart        time.Time
+	IncomingYes        uint32
+	IncomingNo         uint32
+	OutgoingYes        uint32
+	OutgoingNo         uint32
+	IncomingByVoteType VoteTypeCounters
+	OutgoingByVoteType VoteTypeCounters
+	Matches            uint32
+}
@@ -0,0 +1,33 @@
+package entity
+
+import (
+	romancesValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
+)
+
+// VoteTypeCounters holds the votes count of every vote type.
+type VoteTypeCounters struct {
+	Yes        uint32
+	No         uint32
+	Crush      uint32
+	Compliment uint32
+}
+
+func (c *VoteTypeCounters) Incr(voteType romancesValueObject.VoteType) {
+	switch voteType {
+	case romancesValueObject.VoteTypeYes:
+		c.Yes++
+	case romancesValueObject.VoteTypeNo:
+		c.No++
+	case romancesValueObject.VoteTypeCrush:
+		c.Crush++
+	case romancesValueObject.VoteTypeCompliment:
+		c.Compliment++
+	}
+}
+
+func (c *VoteTypeCounters) Add(other VoteTypeCounters) {
+	c.Yes += other.Yes
+	c.No += other.No
+	c.Crush += other.Crush
+	c.Compliment += other.Compliment
+}
@@ -0,0 +1,9 @@
+package counter
+
+import "errors"
+
+var (
+	ErrCountersChanged      = errors.New("counters changed")
+	ErrCountersNotApplied   = errors.New("counters not applied")
+	ErrInvalidReferenceTime = errors.New("invalid reference time")
+)
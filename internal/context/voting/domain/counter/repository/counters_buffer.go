@@ -0,0 +1,10 @@
+package repository
+
+import (
+	"context"
+)
+
+// CountersBuffer holds the counters writes back until it is closed.
+type CountersBuffer interface {
+	Close(ctx context.Context) error
+}
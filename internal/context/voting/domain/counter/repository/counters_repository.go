@@ -5,6 +5,7 @@ import (
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/entity"
 	countersValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/valueobject"
 	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	"github.com/google/uuid"
 )
 
 //go:generate mockgen -destination=../../../../../testlib/mocks/counters_repository_mock.go -package=mocks github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/repository CountersRepository
@@ -20,6 +21,39 @@ type CountersRepository interface {
 		hoursOffsetGroups countersValueObject.HoursOffsetGroups,
 	) (map[uint8]*entity.CountersGroup, error)
 
+	GetHourlyCountersSeries(
+		ctx context.Context,
+		activeUserKey sharedValueObject.ActiveUserKey,
+		hoursCount uint8,
+	) ([]entity.CountersGroup, error)
+
+	GetPeriodCounters(
+		ctx context.Context,
+		activeUserKey sharedValueObject.ActiveUserKey,
+		period countersValueObject.CountersPeriod,
+		periodsCount uint16,
+	) ([]entity.PeriodCounters, error)
+
+	ReplaceLifetimeCounter(
+		ctx context.Context,
+		current entity.CountersGroup,
+		reconciled entity.CountersGroup,
+	) error
+
+	ChangeVoteCounters(
+		ctx context.Context,
+		voteId sharedValueObject.VoteId,
+		countersChange countersValueObject.VoteCountersChange,
+	)
+
+	// ApplyVoteCountersChange applies the changes of the counters once per change ID.
+	ApplyVoteCountersChange(
+		ctx context.Context,
+		changeId uuid.UUID,
+		voteId sharedValueObject.VoteId,
+		countersChanges []countersValueObject.VoteCountersChange,
+	) error
+
 	IncrYesCounters(
 		ctx context.Context,
 		voteId sharedValueObject.VoteId,
@@ -31,4 +65,28 @@ type CountersRepository interface {
 		voteId sharedValueObject.VoteId,
 		counterGroup countersValueObject.CounterUpdateGroup,
 	)
+
+	DecrYesCounters(
+		ctx context.Context,
+		voteId sharedValueObject.VoteId,
+		counterGroup countersValueObject.CounterUpdateGroup,
+	)
+
+	DecrNoCounters(
+		ctx context.Context,
+		voteId sharedValueObject.VoteId,
+		counterGroup countersValueObject.CounterUpdateGroup,
+	)
+
+	TransferNoToYesCounters(
+		ctx context.Context,
+		voteId sharedValueObject.VoteId,
+		counterGroup countersValueObject.CounterUpdateGroup,
+	)
+
+	TransferYesToNoCounters(
+		ctx context.Context,
+		voteId sharedValueObject.VoteId,
+		counterGroup countersValueObject.CounterUpdateGroup,
+	)
 }
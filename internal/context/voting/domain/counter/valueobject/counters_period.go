@@ -0,0 +1,30 @@
+package valueobject
+
+import (
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/timeutil"
+	"time"
+)
+
+// CountersPeriod is the period the counters are rolled up over.
+type CountersPeriod uint8
+
+const (
+	CountersPeriodDay CountersPeriod = iota + 1
+	CountersPeriodWeek
+)
+
+// StartTime returns the start of the period holding t.
+func (p CountersPeriod) StartTime(t time.Time) time.Time {
+	if p == CountersPeriodWeek {
+		return timeutil.WeekStart(t)
+	}
+	return timeutil.DayStart(t)
+}
+
+// Shift moves the start of the period by the given number of periods.
+func (p CountersPeriod) Shift(startTime time.Time, periods int) time.Time {
+	if p == CountersPeriodWeek {
+		return startTime.AddDate(0, 0, 7*periods)
+	}
+	return startTime.AddDate(0, 0, periods)
+}
@@ -3,23 +3,36 @@ package valueobject
 import (
 	"fmt"
 	"github.bumble.dev/shcherbanich/user-votes-storage/config"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter"
 	"sort"
+	"time"
 )
 
+// HoursOffsetGroups holds the hour offsets counted back from the reference time.
 type HoursOffsetGroups struct {
 	values []uint8
+	at     time.Time
 }
 
-func NewHoursOffsetGroups(offsets []uint8) (HoursOffsetGroups, error) {
+// NewHoursOffsetGroups creates the groups counted back from at, the zero at stands for now.
+func NewHoursOffsetGroups(offsets []uint8, at time.Time, now time.Time) (HoursOffsetGroups, error) {
 	err := ValidateHoursOffsets(offsets)
 	if err != nil {
 		return HoursOffsetGroups{}, err
 	}
 
+	if at.IsZero() {
+		at = now
+	}
+	err = ValidateReferenceTime(at, now)
+	if err != nil {
+		return HoursOffsetGroups{}, err
+	}
+
 	cp := make([]uint8, len(offsets))
 	copy(cp, offsets)
 	sort.Slice(cp, func(i, j int) bool { return cp[i] < cp[j] })
-	return HoursOffsetGroups{values: cp}, nil
+	return HoursOffsetGroups{values: cp, at: at}, nil
 }
 
 func (h HoursOffsetGroups) Values() []uint8 {
@@ -28,6 +41,11 @@ func (h HoursOffsetGroups) Values() []uint8 {
 	return cp
 }
 
+// At returns the reference time the offsets are counted back from.
+func (h HoursOffsetGroups) At() time.Time {
+	return h.at
+}
+
 func ValidateHoursOffsets(offsets []uint8) error {
 	if len(offsets) == 0 {
 		return fmt.Errorf("hours offset groups cannot be empty")
@@ -47,3 +65,20 @@ func ValidateHoursOffsets(offsets []uint8) error {
 
 	return nil
 }
+
+// ValidateReferenceTime checks that at is not in the future and the hourly counters of it are not expired yet.
+func ValidateReferenceTime(at time.Time, now time.Time) error {
+	if at.After(now) {
+		return fmt.Errorf("%w: %s is in the future", counter.ErrInvalidReferenceTime, at.UTC().Format(time.RFC3339))
+	}
+	if at.Before(now.Add(-config.CountersTtlHours * time.Hour)) {
+		return fmt.Errorf(
+			"%w: %s is older than %d hours",
+			counter.ErrInvalidReferenceTime,
+			at.UTC().Format(time.RFC3339),
+			config.CountersTtlHours,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package valueobject
+
+import (
+	romancesValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
+)
+
+// VoteCountersChange is the effect of a single vote write on the counters.
+type VoteCountersChange struct {
+	updateGroup    CounterUpdateGroup
+	yesDelta       int32
+	noDelta        int32
+	voteTypeDeltas map[romancesValueObject.VoteType]int32
+	matchesDelta   int32
+}
+
+func NewVoteCountersChange(updateGroup CounterUpdateGroup, yesDelta int32, noDelta int32) VoteCountersChange {
+	return VoteCountersChange{
+		updateGroup: updateGroup,
+		yesDelta:    yesDelta,
+		noDelta:     noDelta,
+	}
+}
+
+// NewVoteTypeCountersChange returns the change of the counters when the vote goes from oldVoteType to newVoteType.
+func NewVoteTypeCountersChange(
+	updateGroup CounterUpdateGroup,
+	oldVoteType romancesValueObject.VoteType,
+	newVoteType romancesValueObject.VoteType,
+	peerVoteType romancesValueObject.VoteType,
+) VoteCountersChange {
+	yesDelta := boolDelta(newVoteType.IsPositive()) - boolDelta(oldVoteType.IsPositive())
+
+	change := NewVoteCountersChange(
+		updateGroup,
+		yesDelta,
+		boolDelta(newVoteType.IsNegative())-boolDelta(oldVoteType.IsNegative()),
+	)
+
+	if oldVoteType != newVoteType {
+		change.voteTypeDeltas = map[romancesValueObject.VoteType]int32{}
+		if !oldVoteType.IsEmpty() {
+			change.voteTypeDeltas[oldVoteType] = -1
+		}
+		if !newVoteType.IsEmpty() {
+			change.voteTypeDeltas[newVoteType] = 1
+		}
+	}
+
+	// a romance is a match while both votes are positive
+	if peerVoteType.IsPositive() {
+		change.matchesDelta = yesDelta
+	}
+
+	return change
+}
+
+func (c VoteCountersChange) UpdateGroup() CounterUpdateGroup {
+	return c.updateGroup
+}
+
+func (c VoteCountersChange) YesDelta() int32 {
+	return c.yesDelta
+}
+
+func (c VoteCountersChange) NoDelta() int32 {
+	return c.noDelta
+}
+
+func (c VoteCountersChange) VoteTypeDelta(voteType romancesValueObject.VoteType) int32 {
+	return c.voteTypeDeltas[voteType]
+}
+
+func (c VoteCountersChange) MatchesDelta() int32 {
+	return c.matchesDelta
+}
+
+func (c VoteCountersChange) IsEmpty() bool {
+	return c.yesDelta == 0 && c.noDelta == 0 && c.matchesDelta == 0 && len(c.voteTypeDeltas) == 0
+}
+
+func (c VoteCountersChange) HasDecrements() bool {
+	if c.yesDelta < 0 || c.noDelta < 0 || c.matchesDelta < 0 {
+		return true
+	}
+	for _, delta := range c.voteTypeDeltas {
+		if delta < 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Increments returns the part of the change which only takes the counters up.
+func (c VoteCountersChange) Increments() VoteCountersChange {
+	return c.filter(func(delta int32) bool { return delta > 0 })
+}
+
+// Decrements returns the part of the change which only takes the counters down.
+func (c VoteCountersChange) Decrements() VoteCountersChange {
+	return c.filter(func(delta int32) bool { return delta < 0 })
+}
+
+func (c VoteCountersChange) filter(keep func(delta int32) bool) VoteCountersChange {
+	keepDelta := func(delta int32) int32 {
+		if keep(delta) {
+			return delta
+		}
+		return 0
+	}
+
+	filtered := VoteCountersChange{
+		updateGroup:  c.updateGroup,
+		yesDelta:     keepDelta(c.yesDelta),
+		noDelta:      keepDelta(c.noDelta),
+		matchesDelta: keepDelta(c.matchesDelta),
+	}
+	for voteType, delta := range c.voteTypeDeltas {
+		if keep(delta) {
+			if filtered.voteTypeDeltas == nil {
+				filtered.voteTypeDeltas = map[romancesValueObject.VoteType]int32{}
+			}
+			filtered.voteTypeDeltas[voteType] = delta
+		}
+	}
+
+	return filtered
+}
+
+func boolDelta(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
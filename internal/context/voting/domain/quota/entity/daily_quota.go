@@ -0,0 +1,20 @@
+package entity
+
+import (
+	romancesValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
+	"time"
+)
+
+type DailyQuota struct {
+	VoteType romancesValueObject.VoteType
+	Limit    uint32
+	Used     uint32
+	ResetsAt time.Time
+}
+
+func (q DailyQuota) Remaining() uint32 {
+	if q.Used >= q.Limit {
+		return 0
+	}
+	return q.Limit - q.Used
+}
@@ -0,0 +1,15 @@
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
+)
+
+var (
+	ErrQuotaExceeded = errors.New("quota exceeded")
+)
+
+func NewQuotaExceededError(voteType valueobject.VoteType, limit uint32) error {
+	return fmt.Errorf("%w: the daily limit of %d `%s` votes is reached", ErrQuotaExceeded, limit, voteType)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	quotaValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota/valueobject"
+	romancesValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+)
+
+//go:generate mockgen -destination=../../../../../testlib/mocks/quotas_repository_mock.go -package=mocks github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota/repository QuotasRepository
+type QuotasRepository interface {
+	GetDailyUsage(
+		ctx context.Context,
+		activeUserKey sharedValueObject.ActiveUserKey,
+		day quotaValueObject.QuotaDay,
+	) (map[romancesValueObject.VoteType]uint32, error)
+}
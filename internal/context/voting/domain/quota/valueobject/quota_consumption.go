@@ -0,0 +1,44 @@
+package valueobject
+
+import (
+	romancesValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+)
+
+// QuotaConsumption is one unit of the daily quota of the vote type taken by a vote of the user.
+type QuotaConsumption struct {
+	activeUserKey sharedValueObject.ActiveUserKey
+	day           QuotaDay
+	voteType      romancesValueObject.VoteType
+	limit         uint32
+}
+
+func NewQuotaConsumption(
+	activeUserKey sharedValueObject.ActiveUserKey,
+	day QuotaDay,
+	voteType romancesValueObject.VoteType,
+	limit uint32,
+) QuotaConsumption {
+	return QuotaConsumption{
+		activeUserKey: activeUserKey,
+		day:           day,
+		voteType:      voteType,
+		limit:         limit,
+	}
+}
+
+func (c QuotaConsumption) ActiveUserKey() sharedValueObject.ActiveUserKey {
+	return c.activeUserKey
+}
+
+func (c QuotaConsumption) Day() QuotaDay {
+	return c.day
+}
+
+func (c QuotaConsumption) VoteType() romancesValueObject.VoteType {
+	return c.voteType
+}
+
+func (c QuotaConsumption) Limit() uint32 {
+	return c.limit
+}
@@ -0,0 +1,30 @@
+package valueobject
+
+import (
+	"fmt"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/timeutil"
+	"time"
+)
+
+// QuotaDay is the UTC day the quotas are counted for.
+type QuotaDay struct {
+	startTime time.Time
+}
+
+func NewQuotaDay(eventTime time.Time) (QuotaDay, error) {
+	if eventTime.IsZero() {
+		return QuotaDay{}, fmt.Errorf("eventTime must not be zero")
+	}
+
+	return QuotaDay{
+		startTime: timeutil.DayStart(eventTime),
+	}, nil
+}
+
+func (d QuotaDay) StartTime() time.Time {
+	return d.startTime
+}
+
+func (d QuotaDay) EndTime() time.Time {
+	return d.startTime.AddDate(0, 0, 1)
+}
@@ -0,0 +1,73 @@
+package valueobject
+
+import (
+	"fmt"
+	"github.bumble.dev/shcherbanich/user-votes-storage/config"
+	romancesValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
+	"maps"
+	"slices"
+)
+
+type dailyLimits map[romancesValueObject.VoteType]uint32
+
+// QuotaPolicy decides how many votes of a vote type a user of a country may add per UTC day.
+type QuotaPolicy struct {
+	defaultLimits dailyLimits
+	countryLimits map[uint16]dailyLimits
+}
+
+func NewQuotaPolicy(conf config.Config) (QuotaPolicy, error) {
+	defaultLimits, err := newDailyLimits(conf.VoteQuotas.Default)
+	if err != nil {
+		return QuotaPolicy{}, err
+	}
+
+	countryLimits := make(map[uint16]dailyLimits, len(conf.VoteQuotas.Countries))
+	for countryId, overrides := range conf.VoteQuotas.Countries {
+		limits, err := newDailyLimits(overrides)
+		if err != nil {
+			return QuotaPolicy{}, fmt.Errorf("country %d: %w", countryId, err)
+		}
+
+		merged := maps.Clone(defaultLimits)
+		maps.Copy(merged, limits)
+		countryLimits[countryId] = merged
+	}
+
+	return QuotaPolicy{
+		defaultLimits: defaultLimits,
+		countryLimits: countryLimits,
+	}, nil
+}
+
+func newDailyLimits(conf config.VoteQuotaLimits) (dailyLimits, error) {
+	limits := make(dailyLimits, len(conf))
+	for name, limit := range conf {
+		voteType, ok := romancesValueObject.VoteTypeFromString(name)
+		if !ok || voteType.IsEmpty() {
+			return nil, fmt.Errorf("unknown vote type %q in vote quotas", name)
+		}
+		limits[voteType] = limit
+	}
+
+	return limits, nil
+}
+
+// DailyLimit returns the limit of the vote type in the country.
+func (p QuotaPolicy) DailyLimit(countryId uint16, voteType romancesValueObject.VoteType) (limit uint32, ok bool) {
+	limit, ok = p.limitsOf(countryId)[voteType]
+	return limit, ok
+}
+
+func (p QuotaPolicy) LimitedVoteTypes(countryId uint16) []romancesValueObject.VoteType {
+	voteTypes := slices.Collect(maps.Keys(p.limitsOf(countryId)))
+	slices.Sort(voteTypes)
+	return voteTypes
+}
+
+func (p QuotaPolicy) limitsOf(countryId uint16) dailyLimits {
+	if limits, ok := p.countryLimits[countryId]; ok {
+		return limits
+	}
+	return p.defaultLimits
+}
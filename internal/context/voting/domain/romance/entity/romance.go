@@ -3,12 +3,16 @@ package entity
 import (
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
 	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	"github.com/google/uuid"
+	"time"
 )
 
 type Romance struct {
 	ActiveUserVote Vote
 	PeerUserVote   Vote
-	Version        uint32
+	// BlockedBy is the user who blocked the peer, uuid.Nil when the romance is not blocked
+	BlockedBy uuid.UUID
+	Version   uint32
 }
 
 func CreateEmptyRomance(voteId sharedValueObject.VoteId) Romance {
@@ -21,3 +25,50 @@ func CreateEmptyRomance(voteId sharedValueObject.VoteId) Romance {
 func (r *Romance) IsEmpty() bool {
 	return r.ActiveUserVote.VoteType == valueobject.VoteTypeEmpty && r.PeerUserVote.VoteType == valueobject.VoteTypeEmpty
 }
+
+func (r *Romance) IsMutual() bool {
+	return r.ActiveUserVote.VoteType.IsPositive() && r.PeerUserVote.VoteType.IsPositive()
+}
+
+func (r *Romance) IsBlocked() bool {
+	return r.BlockedBy != uuid.Nil
+}
+
+func (r *Romance) IsBlockedByActiveUser() bool {
+	return r.IsBlocked() && r.BlockedBy == r.ActiveUserVote.Id.ActiveUserId()
+}
+
+// MatchedAt returns the time of the later of the two votes.
+func (r *Romance) MatchedAt() *time.Time {
+	if !r.IsMutual() {
+		return nil
+	}
+
+	activeVotedAt := r.ActiveUserVote.VotedAt
+	peerVotedAt := r.PeerUserVote.VotedAt
+	switch {
+	case activeVotedAt == nil:
+		return peerVotedAt
+	case peerVotedAt == nil:
+		return activeVotedAt
+	case peerVotedAt.After(*activeVotedAt):
+		return peerVotedAt
+	default:
+		return activeVotedAt
+	}
+}
+
+// IsIncomingLike reports whether the peer voted positively while the active user has not voted yet.
+func (r *Romance) IsIncomingLike() bool {
+	return r.ActiveUserVote.VoteType.IsEmpty() && r.PeerUserVote.VoteType.IsPositive()
+}
+
+// ToPeerRomance returns the same romance from the peer's perspective.
+func (r *Romance) ToPeerRomance() Romance {
+	return Romance{
+		ActiveUserVote: r.PeerUserVote,
+		PeerUserVote:   r.ActiveUserVote,
+		BlockedBy:      r.BlockedBy,
+		Version:        r.Version,
+	}
+}
@@ -0,0 +1,12 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+)
+
+// RomanceChange is a single write of a romance.
+type RomanceChange struct {
+	Id  uuid.UUID
+	Old Romance
+	New Romance
+}
@@ -0,0 +1,14 @@
+package entity
+
+import (
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+)
+
+type RomanceUsersPage struct {
+	UserKeys   []sharedValueObject.ActiveUserKey
+	NextCursor string
+}
+
+func (p RomanceUsersPage) HasMore() bool {
+	return p.NextCursor != ""
+}
@@ -0,0 +1,10 @@
+package entity
+
+type RomancesBackfillPage struct {
+	Updated    int
+	NextCursor string
+}
+
+func (p RomancesBackfillPage) HasMore() bool {
+	return p.NextCursor != ""
+}
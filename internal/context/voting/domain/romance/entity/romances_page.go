@@ -0,0 +1,10 @@
+package entity
+
+type RomancesPage struct {
+	Romances   []Romance
+	NextCursor string
+}
+
+func (p RomancesPage) HasMore() bool {
+	return p.NextCursor != ""
+}
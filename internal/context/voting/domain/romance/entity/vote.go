@@ -9,6 +9,7 @@ import (
 type Vote struct {
 	Id        sharedValueObject.VoteId
 	VoteType  valueobject.VoteType
+	Message   valueobject.ComplimentMessage
 	VotedAt   *time.Time
 	CreatedAt *time.Time
 	UpdatedAt *time.Time
@@ -11,8 +11,14 @@ var (
 	ErrWrongVote       = errors.New("wrong vote")
 	ErrVoteDuplicate   = errors.New("vote duplicate")
 	ErrVersionConflict = errors.New("version conflict")
+	ErrInvalidCursor   = errors.New("invalid cursor")
+	ErrRomanceBlocked  = errors.New("romance blocked")
 )
 
 func NewChangingVoteTypeError(oldVote valueobject.VoteType, newVote valueobject.VoteType) error {
 	return fmt.Errorf("%w: vote type change from `%s` to `%s` is not allowed", ErrWrongVote, oldVote, newVote)
 }
+
+func NewMessageNotAllowedError(voteType valueobject.VoteType) error {
+	return fmt.Errorf("%w: a message is not allowed for the `%s` vote", ErrWrongVote, voteType)
+}
@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"context"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/entity"
+)
+
+// RomanceChangesReader passes every change of the romances to the handler until the context is done.
+type RomanceChangesReader interface {
+	Read(ctx context.Context, handle func(ctx context.Context, romanceChange entity.RomanceChange) error) error
+}
@@ -2,26 +2,90 @@ package repository
 
 import (
 	"context"
+	countersValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/valueobject"
+	quotaValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota/valueobject"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/entity"
 	romancesValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
 	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	"github.com/google/uuid"
 	"time"
 )
 
 //go:generate mockgen -destination=../../../../../testlib/mocks/romances_repository_mock.go -package=mocks github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/repository RomancesRepository
 type RomancesRepository interface {
 	GetRomance(ctx context.Context, voteId sharedValueObject.VoteId) (entity.Romance, error)
+	GetRomances(ctx context.Context, voteIds []sharedValueObject.VoteId) ([]entity.Romance, error)
 	DeleteRomance(ctx context.Context, voteId sharedValueObject.VoteId) error
+	DeleteUserRomances(ctx context.Context, activeUserKey sharedValueObject.ActiveUserKey) error
+	ListUserRomances(
+		ctx context.Context,
+		activeUserKey sharedValueObject.ActiveUserKey,
+		pageRequest sharedValueObject.PageRequest,
+	) (entity.RomancesPage, error)
+	ScanRomanceUsers(
+		ctx context.Context,
+		countryId uint16,
+		segment int32,
+		totalSegments int32,
+		pageRequest sharedValueObject.PageRequest,
+	) (entity.RomanceUsersPage, error)
+	BackfillRomanceIndexes(
+		ctx context.Context,
+		countryId uint16,
+		segment int32,
+		totalSegments int32,
+		pageRequest sharedValueObject.PageRequest,
+	) (entity.RomancesBackfillPage, error)
+	ListUserMatches(
+		ctx context.Context,
+		activeUserKey sharedValueObject.ActiveUserKey,
+		pageRequest sharedValueObject.PageRequest,
+	) (entity.RomancesPage, error)
+	ListUserLikes(
+		ctx context.Context,
+		activeUserKey sharedValueObject.ActiveUserKey,
+		peerVoteTypes []romancesValueObject.VoteType,
+		pageRequest sharedValueObject.PageRequest,
+	) (entity.RomancesPage, error)
 	AddActiveUserVoteToRomance(
 		ctx context.Context,
 		romance entity.Romance,
 		voteType romancesValueObject.VoteType,
+		message romancesValueObject.ComplimentMessage,
+		votedAt time.Time,
+	) (entity.Romance, error)
+	AddActiveUserVoteToRomanceWithCounters(
+		ctx context.Context,
+		romance entity.Romance,
+		voteType romancesValueObject.VoteType,
+		message romancesValueObject.ComplimentMessage,
 		votedAt time.Time,
+		countersChangeId uuid.UUID,
+		countersChanges []countersValueObject.VoteCountersChange,
+		quotaConsumption *quotaValueObject.QuotaConsumption,
 	) (entity.Romance, error)
 	ChangeActiveUserVoteTypeInRomance(
 		ctx context.Context,
 		romance entity.Romance,
 		newVoteType romancesValueObject.VoteType,
+		message romancesValueObject.ComplimentMessage,
+	) (entity.Romance, error)
+	ChangeActiveUserVoteTypeInRomanceWithCounters(
+		ctx context.Context,
+		romance entity.Romance,
+		newVoteType romancesValueObject.VoteType,
+		message romancesValueObject.ComplimentMessage,
+		countersChangeId uuid.UUID,
+		countersChanges []countersValueObject.VoteCountersChange,
+		quotaConsumption *quotaValueObject.QuotaConsumption,
 	) (entity.Romance, error)
 	DeleteActiveUserVoteFromRomance(ctx context.Context, romance entity.Romance) error
+	DeleteActiveUserVoteFromRomanceWithCounters(
+		ctx context.Context,
+		romance entity.Romance,
+		countersChangeId uuid.UUID,
+		countersChanges []countersValueObject.VoteCountersChange,
+	) error
+	BlockRomance(ctx context.Context, romance entity.Romance) (entity.Romance, error)
+	UnblockRomance(ctx context.Context, romance entity.Romance) (entity.Romance, error)
 }
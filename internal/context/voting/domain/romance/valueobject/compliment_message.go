@@ -0,0 +1,32 @@
+package valueobject
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const ComplimentMessageMaxLength = 280
+
+// ComplimentMessage is the text a compliment vote carries, the empty message means no text.
+type ComplimentMessage string
+
+func NewComplimentMessage(text string) (ComplimentMessage, error) {
+	if !utf8.ValidString(text) {
+		return "", fmt.Errorf("the message must be a valid UTF-8 text")
+	}
+
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("the message must not be blank")
+	}
+
+	if utf8.RuneCountInString(text) > ComplimentMessageMaxLength {
+		return "", fmt.Errorf("the message must not be longer than %d characters", ComplimentMessageMaxLength)
+	}
+
+	return ComplimentMessage(text), nil
+}
+
+func (m ComplimentMessage) IsEmpty() bool {
+	return m == ""
+}
@@ -0,0 +1,104 @@
+package valueobject
+
+import (
+	"fmt"
+	"github.bumble.dev/shcherbanich/user-votes-storage/config"
+	"maps"
+	"slices"
+)
+
+type voteTransitionRules map[VoteType][]VoteType
+
+// VoteTransitionPolicy decides which vote type the current vote of the active user may be changed to.
+type VoteTransitionPolicy struct {
+	defaultRules voteTransitionRules
+	countryRules map[uint16]voteTransitionRules
+}
+
+func NewVoteTransitionPolicy(conf config.Config) (VoteTransitionPolicy, error) {
+	defaultRules, err := newVoteTransitionRules(conf.VoteTransitions.Default)
+	if err != nil {
+		return VoteTransitionPolicy{}, err
+	}
+
+	countryRules := make(map[uint16]voteTransitionRules, len(conf.VoteTransitions.Countries))
+	for countryId, overrides := range conf.VoteTransitions.Countries {
+		rules, err := newVoteTransitionRules(overrides)
+		if err != nil {
+			return VoteTransitionPolicy{}, fmt.Errorf("country %d: %w", countryId, err)
+		}
+
+		merged := maps.Clone(defaultRules)
+		maps.Copy(merged, rules)
+		countryRules[countryId] = merged
+	}
+
+	return VoteTransitionPolicy{
+		defaultRules: defaultRules,
+		countryRules: countryRules,
+	}, nil
+}
+
+func newVoteTransitionRules(conf config.VoteTransitionRules) (voteTransitionRules, error) {
+	rules := make(voteTransitionRules, len(conf))
+	for fromName, toNames := range conf {
+		from, ok := VoteTypeFromString(fromName)
+		if !ok {
+			return nil, fmt.Errorf("unknown vote type %q in vote transitions", fromName)
+		}
+
+		targets := make([]VoteType, 0, len(toNames))
+		for _, toName := range toNames {
+			to, ok := VoteTypeFromString(toName)
+			if !ok {
+				return nil, fmt.Errorf("unknown vote type %q in vote transitions of %q", toName, fromName)
+			}
+			if to == from {
+				return nil, fmt.Errorf("vote type %q can not be changed to itself", fromName)
+			}
+			targets = append(targets, to)
+		}
+		rules[from] = targets
+	}
+
+	return rules, nil
+}
+
+func (p VoteTransitionPolicy) IsAllowed(countryId uint16, from VoteType, to VoteType) bool {
+	rules, ok := p.countryRules[countryId]
+	if !ok {
+		rules = p.defaultRules
+	}
+
+	return slices.Contains(rules[from], to)
+}
+
+// AddableVoteTypes returns the vote types a vote may be added with in at least one country.
+func (p VoteTransitionPolicy) AddableVoteTypes() []VoteType {
+	return p.collectTargets(func(_ VoteType, to VoteType) bool {
+		return !to.IsEmpty()
+	})
+}
+
+// ChangeableVoteTypes returns the vote types an existing vote may be changed to in at least one country.
+func (p VoteTransitionPolicy) ChangeableVoteTypes() []VoteType {
+	return p.collectTargets(func(from VoteType, to VoteType) bool {
+		return !from.IsEmpty() && !to.IsEmpty()
+	})
+}
+
+func (p VoteTransitionPolicy) collectTargets(filter func(from VoteType, to VoteType) bool) []VoteType {
+	var result []VoteType
+	for _, rules := range append([]voteTransitionRules{p.defaultRules}, slices.Collect(maps.Values(p.countryRules))...) {
+		for from, targets := range rules {
+			for _, to := range targets {
+				if filter(from, to) && !slices.Contains(result, to) {
+					result = append(result, to)
+				}
+			}
+		}
+	}
+
+	slices.Sort(result)
+	return result
+}
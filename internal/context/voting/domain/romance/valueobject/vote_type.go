@@ -18,6 +18,15 @@ var UserVoteTypeToString = map[VoteType]string{
 	VoteTypeCompliment: "compliment",
 }
 
+func VoteTypeFromString(s string) (VoteType, bool) {
+	for voteType, name := range UserVoteTypeToString {
+		if name == s {
+			return voteType, true
+		}
+	}
+	return VoteTypeEmpty, false
+}
+
 func (v VoteType) IsPositive() bool {
 	return v == VoteTypeYes || v == VoteTypeCrush || v == VoteTypeCompliment
 }
@@ -33,3 +42,7 @@ func (v VoteType) IsEmpty() bool {
 func (v VoteType) String() string {
 	return UserVoteTypeToString[v]
 }
+
+func (v VoteType) CanCarryMessage() bool {
+	return v == VoteTypeCompliment
+}
@@ -0,0 +1,39 @@
+package valueobject
+
+import "fmt"
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+type PageRequest struct {
+	limit  int32
+	cursor string
+}
+
+func NewPageRequest(limit int32, cursor string) (PageRequest, error) {
+	if limit == 0 {
+		limit = DefaultPageLimit
+	}
+	if limit < 1 || limit > MaxPageLimit {
+		return PageRequest{}, fmt.Errorf("invalid page limit: %d (must be 1-%d)", limit, MaxPageLimit)
+	}
+
+	return PageRequest{
+		limit:  limit,
+		cursor: cursor,
+	}, nil
+}
+
+func (p PageRequest) Limit() int32 {
+	return p.limit
+}
+
+func (p PageRequest) Cursor() string {
+	return p.cursor
+}
+
+func (p PageRequest) IsFirstPage() bool {
+	return p.cursor == ""
+}
@@ -7,15 +7,18 @@ import (
 
 type VoteId struct {
 	activeUserKey ActiveUserKey
-	peerUserId    uuid.UUID
+	peerUserKey   ActiveUserKey
 }
 
-func NewVoteId(countryId uint16, activeUserId uuid.UUID, peerUserId uuid.UUID) (VoteId, error) {
+func NewVoteId(countryId uint16, activeUserId uuid.UUID, peerCountryId uint16, peerUserId uuid.UUID) (VoteId, error) {
 	activeUserKey, err := NewActiveUserKey(countryId, activeUserId)
 	if err != nil {
 		return VoteId{}, err
 	}
 
+	if peerCountryId == 0 {
+		return VoteId{}, errors.New("peerCountryId must be non-zero")
+	}
 	if peerUserId == uuid.Nil {
 		return VoteId{}, errors.New("peerUserId must not be empty")
 	}
@@ -25,7 +28,10 @@ func NewVoteId(countryId uint16, activeUserId uuid.UUID, peerUserId uuid.UUID) (
 
 	return VoteId{
 		activeUserKey: activeUserKey,
-		peerUserId:    peerUserId,
+		peerUserKey: ActiveUserKey{
+			countryId:    peerCountryId,
+			activeUserId: peerUserId,
+		},
 	}, nil
 }
 
@@ -37,16 +43,17 @@ func (id VoteId) ActiveUserId() uuid.UUID {
 	return id.activeUserKey.activeUserId
 }
 
+func (id VoteId) PeerCountryId() uint16 {
+	return id.peerUserKey.countryId
+}
+
 func (id VoteId) PeerUserId() uuid.UUID {
-	return id.peerUserId
+	return id.peerUserKey.activeUserId
 }
 
 func (id VoteId) ToPeerVoteId() VoteId {
 	return VoteId{
-		activeUserKey: ActiveUserKey{
-			activeUserId: id.peerUserId,
-			countryId:    id.activeUserKey.countryId,
-		},
-		peerUserId: id.activeUserKey.activeUserId,
+		activeUserKey: id.peerUserKey,
+		peerUserKey:   id.activeUserKey,
 	}
 }
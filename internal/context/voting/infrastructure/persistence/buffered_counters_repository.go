@@ -0,0 +1,324 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"github.bumble.dev/shcherbanich/user-votes-storage/config"
+	countersValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/valueobject"
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
+	"github.com/google/uuid"
+	"sync"
+	"time"
+)
+
+const (
+	countersBufferDepthMetric  = "counters_buffer_depth"
+	countersBufferFlushMetric  = "counters_buffer_flush"
+	countersBufferFlushTimeout = 30 * time.Second
+	countersBufferMaxRetries   = 3
+)
+
+// BufferedCountersRepository sums up the counters increments in memory and writes them periodically.
+type BufferedCountersRepository struct {
+	*CountersRepository
+	bufferConfig  config.CountersBufferConfig
+	metrics       platform.Metrics
+	mu            sync.Mutex
+	pending       map[bufferedCountersItemKey]bufferedCountersItem
+	closed        bool
+	flushRequests chan struct{}
+	stop          chan struct{}
+	stopped       chan struct{}
+}
+
+type bufferedCountersItemKey struct {
+	countryId uint16
+	userId    uuid.UUID
+	itemId    string
+	startTime int64
+}
+
+type bufferedCountersItem struct {
+	key        bufferedCountersItemKey
+	itemUpdate countersItemUpdate
+	failures   int
+}
+
+func NewBufferedCountersRepository(
+	countersRepository *CountersRepository,
+	config config.Config,
+	metrics platform.Metrics,
+) *BufferedCountersRepository {
+	r := &BufferedCountersRepository{
+		CountersRepository: countersRepository,
+		bufferConfig:       config.Counters.Buffer,
+		metrics:            metrics,
+		pending:            map[bufferedCountersItemKey]bufferedCountersItem{},
+		flushRequests:      make(chan struct{}, 1),
+		stop:               make(chan struct{}),
+		stopped:            make(chan struct{}),
+	}
+
+	if !r.bufferConfig.Enabled {
+		r.closed = true
+		close(r.stopped)
+		return r
+	}
+
+	go r.flushPeriodically()
+
+	return r
+}
+
+func (r *BufferedCountersRepository) IncrYesCounters(
+	ctx context.Context,
+	voteId sharedValueObject.VoteId,
+	counterUpdateGroup countersValueObject.CounterUpdateGroup,
+) {
+	r.ChangeVoteCounters(ctx, voteId, countersValueObject.NewVoteCountersChange(counterUpdateGroup, 1, 0))
+}
+
+func (r *BufferedCountersRepository) IncrNoCounters(
+	ctx context.Context,
+	voteId sharedValueObject.VoteId,
+	counterUpdateGroup countersValueObject.CounterUpdateGroup,
+) {
+	r.ChangeVoteCounters(ctx, voteId, countersValueObject.NewVoteCountersChange(counterUpdateGroup, 0, 1))
+}
+
+// ChangeVoteCounters writes a change with decrements right away.
+func (r *BufferedCountersRepository) ChangeVoteCounters(
+	ctx context.Context,
+	voteId sharedValueObject.VoteId,
+	countersChange countersValueObject.VoteCountersChange,
+) {
+	if countersChange.HasDecrements() || !r.add(voteId, countersChange) {
+		r.flushUsers(ctx, voteId.ActiveUserId(), voteId.PeerUserId())
+		r.CountersRepository.ChangeVoteCounters(ctx, voteId, countersChange)
+	}
+}
+
+func (r *BufferedCountersRepository) DecrYesCounters(
+	ctx context.Context,
+	voteId sharedValueObject.VoteId,
+	counterUpdateGroup countersValueObject.CounterUpdateGroup,
+) {
+	r.flushUsers(ctx, voteId.ActiveUserId(), voteId.PeerUserId())
+	r.CountersRepository.DecrYesCounters(ctx, voteId, counterUpdateGroup)
+}
+
+func (r *BufferedCountersRepository) DecrNoCounters(
+	ctx context.Context,
+	voteId sharedValueObject.VoteId,
+	counterUpdateGroup countersValueObject.CounterUpdateGroup,
+) {
+	r.flushUsers(ctx, voteId.ActiveUserId(), voteId.PeerUserId())
+	r.CountersRepository.DecrNoCounters(ctx, voteId, counterUpdateGroup)
+}
+
+func (r *BufferedCountersRepository) TransferNoToYesCounters(
+	ctx context.Context,
+	voteId sharedValueObject.VoteId,
+	counterUpdateGroup countersValueObject.CounterUpdateGroup,
+) {
+	r.flushUsers(ctx, voteId.ActiveUserId(), voteId.PeerUserId())
+	r.CountersRepository.TransferNoToYesCounters(ctx, voteId, counterUpdateGroup)
+}
+
+func (r *BufferedCountersRepository) TransferYesToNoCounters(
+	ctx context.Context,
+	voteId sharedValueObject.VoteId,
+	counterUpdateGroup countersValueObject.CounterUpdateGroup,
+) {
+	r.flushUsers(ctx, voteId.ActiveUserId(), voteId.PeerUserId())
+	r.CountersRepository.TransferYesToNoCounters(ctx, voteId, counterUpdateGroup)
+}
+
+// Close stops the periodic flush and writes out everything left in the buffer.
+func (r *BufferedCountersRepository) Close(ctx context.Context) error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	r.mu.Unlock()
+
+	close(r.stop)
+	select {
+	case <-r.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	for items := r.takeAll(); len(items) > 0; items = r.takeAll() {
+		r.requeue(r.flush(ctx, items))
+	}
+	return nil
+}
+
+func (r *BufferedCountersRepository) add(
+	voteId sharedValueObject.VoteId,
+	countersChange countersValueObject.VoteCountersChange,
+) bool {
+	if countersChange.IsEmpty() {
+		return true
+	}
+
+	itemUpdates := getVoteCountersItemUpdates(voteId, countersChange, r.config.Counters)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return false
+	}
+
+	for _, itemUpdate := range itemUpdates {
+		key := bufferedCountersItemKey{
+			countryId: itemUpdate.countryId,
+			userId:    itemUpdate.ownerId,
+			itemId:    itemUpdate.userId,
+			startTime: itemUpdate.startTime,
+		}
+
+		r.put(bufferedCountersItem{key: key, itemUpdate: itemUpdate})
+	}
+
+	depth := len(r.pending)
+	r.metrics.SetGauge(countersBufferDepthMetric, float64(depth))
+
+	if depth >= r.bufferConfig.MaxSize {
+		select {
+		case r.flushRequests <- struct{}{}:
+		default:
+		}
+	}
+
+	return true
+}
+
+func (r *BufferedCountersRepository) flushPeriodically() {
+	defer close(r.stopped)
+
+	ticker := time.NewTicker(r.bufferConfig.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		case <-r.flushRequests:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), countersBufferFlushTimeout)
+		r.requeue(r.flush(ctx, r.takeAll()))
+		cancel()
+	}
+}
+
+func (r *BufferedCountersRepository) flushUsers(ctx context.Context, userIds ...uuid.UUID) {
+	r.mu.Lock()
+	var items []bufferedCountersItem
+	for key, item := range r.pending {
+		for _, userId := range userIds {
+			if key.userId == userId {
+				items = append(items, item)
+				delete(r.pending, key)
+				break
+			}
+		}
+	}
+	r.metrics.SetGauge(countersBufferDepthMetric, float64(len(r.pending)))
+	r.mu.Unlock()
+
+	r.requeue(r.flush(ctx, items))
+}
+
+func (r *BufferedCountersRepository) takeAll() []bufferedCountersItem {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	items := make([]bufferedCountersItem, 0, len(r.pending))
+	for _, item := range r.pending {
+		items = append(items, item)
+	}
+	r.pending = map[bufferedCountersItemKey]bufferedCountersItem{}
+	r.metrics.SetGauge(countersBufferDepthMetric, 0)
+
+	return items
+}
+
+func (r *BufferedCountersRepository) flush(ctx context.Context, items []bufferedCountersItem) []bufferedCountersItem {
+	if len(items) == 0 {
+		return nil
+	}
+
+	var failed []bufferedCountersItem
+	startedAt := r.clock.Now()
+	for _, item := range items {
+		if err := r.addCountersItem(ctx, item.itemUpdate); err != nil {
+			r.logger.Error(fmt.Sprintf("flushCountersBuffer error: %s", err))
+			failed = append(failed, item)
+		}
+	}
+	r.metrics.ObserveDuration(countersBufferFlushMetric, r.clock.Now().Sub(startedAt))
+
+	r.logger.Debug(fmt.Sprintf("Flushed %d of %d buffered counters items", len(items)-len(failed), len(items)))
+
+	return failed
+}
+
+func (r *BufferedCountersRepository) requeue(failed []bufferedCountersItem) {
+	if len(failed) == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, item := range failed {
+		item.failures++
+		if item.failures > countersBufferMaxRetries {
+			r.logger.Error(fmt.Sprintf(
+				"Counters buffer item of user %s at %d dropped after %d failures: %+v",
+				item.itemUpdate.userId, item.itemUpdate.startTime, item.failures, item.itemUpdate.deltas,
+			))
+			continue
+		}
+		r.put(item)
+	}
+	r.metrics.SetGauge(countersBufferDepthMetric, float64(len(r.pending)))
+}
+
+func (r *BufferedCountersRepository) put(item bufferedCountersItem) {
+	buffered, ok := r.pending[item.key]
+	if !ok {
+		item.itemUpdate.deltas = append([]counterDelta(nil), item.itemUpdate.deltas...)
+		r.pending[item.key] = item
+		return
+	}
+	buffered.itemUpdate.deltas = mergeCounterDeltas(buffered.itemUpdate.deltas, item.itemUpdate.deltas)
+	buffered.failures = max(buffered.failures, item.failures)
+	r.pending[item.key] = buffered
+}
+
+func mergeCounterDeltas(deltas []counterDelta, added []counterDelta) []counterDelta {
+	for _, a := range added {
+		merged := false
+		for i := range deltas {
+			if deltas[i].attrName == a.attrName {
+				deltas[i].delta += a.delta
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			deltas = append(deltas, a)
+		}
+	}
+
+	return deltas
+}
@@ -0,0 +1,278 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.bumble.dev/shcherbanich/user-votes-storage/config"
+	counterDomain "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
+	platformDynamoDb "github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+)
+
+// countersItemsWriter rebuilds and retries the transaction when only the counters items cancelled it.
+type countersItemsWriter struct {
+	dynamoDbClient platformDynamoDb.Client
+	regionRouter   platformDynamoDb.RegionRouter
+	clock          platform.Clock
+	logger         platform.Logger
+}
+
+func newCountersItemsWriter(
+	dynamoDbClient platformDynamoDb.Client,
+	regionRouter platformDynamoDb.RegionRouter,
+	clock platform.Clock,
+	logger platform.Logger,
+) countersItemsWriter {
+	return countersItemsWriter{
+		dynamoDbClient: dynamoDbClient,
+		regionRouter:   regionRouter,
+		clock:          clock,
+		logger:         logger,
+	}
+}
+
+func (w countersItemsWriter) groupByRegion(itemUpdates []countersItemUpdate) map[string][]countersItemUpdate {
+	itemUpdatesByRegion := make(map[string][]countersItemUpdate, 1)
+	for _, itemUpdate := range itemUpdates {
+		region := w.regionRouter.RegionByCountry(itemUpdate.countryId)
+		itemUpdatesByRegion[region] = append(itemUpdatesByRegion[region], itemUpdate)
+	}
+
+	return itemUpdatesByRegion
+}
+
+// write puts the caller items first so their indexes in the cancellation reasons are kept.
+func (w countersItemsWriter) write(
+	ctx context.Context,
+	region string,
+	itemUpdates []countersItemUpdate,
+	callerItems []types.TransactWriteItem,
+) error {
+	itemUpdates = mergeCountersItemUpdates(itemUpdates)
+
+	for tries := 0; ; tries++ {
+		countersItems, err := w.getTransactItems(ctx, region, itemUpdates)
+		if err != nil {
+			return err
+		}
+
+		transactItems := slices.Concat(callerItems, countersItems)
+		if len(transactItems) == 0 {
+			return nil
+		}
+
+		_, err = w.dynamoDbClient.TransactWriteItems(
+			ctx,
+			&dynamodb.TransactWriteItemsInput{
+				TransactItems: transactItems,
+			},
+			func(o *dynamodb.Options) {
+				o.Region = region
+			},
+		)
+		if err == nil || !isCountersItemsConflict(err, len(callerItems)) {
+			return err
+		}
+		if tries >= config.DynamoDbVersionConflictRetriesCount {
+			return fmt.Errorf("%w: %w", counterDomain.ErrCountersChanged, err)
+		}
+
+		w.logger.Debug(fmt.Sprintf("Counters changed while written in %s, retrying: %s", region, err))
+	}
+}
+
+func (w countersItemsWriter) getTransactItems(
+	ctx context.Context,
+	region string,
+	itemUpdates []countersItemUpdate,
+) ([]types.TransactWriteItem, error) {
+	now := w.clock.Now().Unix()
+
+	items := make([]types.TransactWriteItem, 0, len(itemUpdates))
+	for _, itemUpdate := range itemUpdates {
+		shardDeltas, err := w.splitByShards(ctx, region, itemUpdate, now)
+		if err != nil {
+			return nil, err
+		}
+
+		for shard, deltas := range shardDeltas {
+			shardUpdate := itemUpdate
+			shardUpdate.deltas = deltas
+			if update := shardUpdate.toUpdate(shard); update != nil {
+				items = append(items, types.TransactWriteItem{Update: update})
+			}
+		}
+	}
+
+	return items, nil
+}
+
+func (w countersItemsWriter) splitByShards(
+	ctx context.Context,
+	region string,
+	itemUpdate countersItemUpdate,
+	now int64,
+) (map[uint16][]counterDelta, error) {
+	if !itemUpdate.hasDecrements() {
+		return map[uint16][]counterDelta{itemUpdate.randomShard(): itemUpdate.deltas}, nil
+	}
+
+	shardItems, err := w.queryShards(ctx, region, itemUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	shards := make([]uint16, 0, len(shardItems))
+	for shard := range shardItems {
+		shards = append(shards, shard)
+	}
+	rand.Shuffle(len(shards), func(i, j int) {
+		shards[i], shards[j] = shards[j], shards[i]
+	})
+
+	shardDeltas := make(map[uint16][]counterDelta, len(shards))
+	var increments []counterDelta
+	for _, d := range itemUpdate.deltas {
+		if d.delta > 0 {
+			increments = append(increments, d)
+		}
+		if d.delta >= 0 {
+			continue
+		}
+
+		left := -int64(d.delta)
+		for _, shard := range shards {
+			taken := min(left, getNumberAttr(shardItems[shard], d.attrName))
+			if taken <= 0 {
+				continue
+			}
+
+			shardDeltas[shard] = append(shardDeltas[shard], counterDelta{
+				placeholder: d.placeholder,
+				attrName:    d.attrName,
+				delta:       -int32(taken),
+			})
+			left -= taken
+			if left == 0 {
+				break
+			}
+		}
+
+		if left > 0 && (itemUpdate.ttl == 0 || itemUpdate.ttl > now) {
+			w.logger.Error(fmt.Sprintf(
+				"Counters underflow: %d of %s missing in %s at %d",
+				left, d.attrName, itemUpdate.userId, itemUpdate.startTime,
+			))
+		}
+	}
+
+	if len(increments) > 0 {
+		shard := itemUpdate.randomShard()
+		for _, s := range shards {
+			if _, ok := shardDeltas[s]; ok {
+				shard = s
+				break
+			}
+		}
+		shardDeltas[shard] = append(shardDeltas[shard], increments...)
+	}
+
+	return shardDeltas, nil
+}
+
+func (w countersItemsWriter) queryShards(
+	ctx context.Context,
+	region string,
+	itemUpdate countersItemUpdate,
+) (map[uint16]map[string]types.AttributeValue, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(CountersTableName),
+		KeyConditionExpression: aws.String("u = :pk AND h BETWEEN :from AND :to"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":   &types.AttributeValueMemberS{Value: itemUpdate.userId},
+			":from": &types.AttributeValueMemberN{Value: strconv.FormatInt(itemUpdate.startTime, 10)},
+			":to":   &types.AttributeValueMemberN{Value: strconv.FormatInt(itemUpdate.startTime+config.CountersMaxShards-1, 10)},
+		},
+		ConsistentRead: aws.Bool(true),
+	}
+
+	shardItems := map[uint16]map[string]types.AttributeValue{}
+	for {
+		out, err := w.dynamoDbClient.Query(ctx, input, func(o *dynamodb.Options) {
+			o.Region = region
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range out.Items {
+			shardItems[uint16(getNumberAttr(item, HourUnixTimestampAttrName)-itemUpdate.startTime)] = item
+		}
+
+		if len(out.LastEvaluatedKey) == 0 {
+			return shardItems, nil
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+}
+
+func isCountersItemsConflict(err error, callerItemsCount int) bool {
+	var canceledErr *types.TransactionCanceledException
+	if !errors.As(err, &canceledErr) {
+		return false
+	}
+
+	conflict := false
+	for i, reason := range canceledErr.CancellationReasons {
+		switch code := aws.ToString(reason.Code); {
+		case code == "TransactionConflict":
+			conflict = true
+		case code == "ConditionalCheckFailed" && i >= callerItemsCount:
+			conflict = true
+		case code != "" && code != "None":
+			return false
+		}
+	}
+
+	return conflict
+}
+
+func mergeCountersItemUpdates(itemUpdates []countersItemUpdate) []countersItemUpdate {
+	type itemKey struct {
+		userId    string
+		startTime int64
+	}
+
+	merged := make([]countersItemUpdate, 0, len(itemUpdates))
+	indexes := make(map[itemKey]int, len(itemUpdates))
+	for _, itemUpdate := range itemUpdates {
+		key := itemKey{userId: itemUpdate.userId, startTime: itemUpdate.startTime}
+		if i, ok := indexes[key]; ok {
+			merged[i].deltas = mergeCounterDeltas(merged[i].deltas, itemUpdate.deltas)
+			continue
+		}
+
+		indexes[key] = len(merged)
+		itemUpdate.deltas = slices.Clone(itemUpdate.deltas)
+		merged = append(merged, itemUpdate)
+	}
+
+	return merged
+}
+
+func getNumberAttr(item map[string]types.AttributeValue, attrName string) int64 {
+	attr, ok := item[attrName].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0
+	}
+
+	value, _ := strconv.ParseInt(attr.Value, 10, 64)
+	return value
+}
@@ -2,10 +2,13 @@ package persistence
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.bumble.dev/shcherbanich/user-votes-storage/config"
+	counterDomain "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/entity"
 	countersValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/valueobject"
+	romancesValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
 	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
 	platformDynamoDb "github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform/dynamodb"
@@ -15,7 +18,9 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/google/uuid"
+	"math/rand/v2"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -28,12 +33,52 @@ const (
 	incomingNoAttrName        = "in"
 	outgoingYesAttrName       = "oy"
 	outgoingNoAttrName        = "on"
+
+	// the yes and no counters sum up the positive and negative votes.
+	incomingYesVotesAttrName        = "ity"
+	incomingNoVotesAttrName         = "itn"
+	incomingCrushVotesAttrName      = "itc"
+	incomingComplimentVotesAttrName = "itm"
+	outgoingYesVotesAttrName        = "oty"
+	outgoingNoVotesAttrName         = "otn"
+	outgoingCrushVotesAttrName      = "otc"
+	outgoingComplimentVotesAttrName = "otm"
+	matchesAttrName                 = "m"
+
+	// the shard of a counters item is added to the sort key.
+	countersShardsSortKeyStep = timeutil.HourSeconds
+
+	// the rollups are kept in partitions of their own next to the hourly and lifetime rows of the user
+	dailyCountersUserIdSuffix  = "#d"
+	weeklyCountersUserIdSuffix = "#w"
+
+	// an applied counters change leaves a marker item in every region it was written to.
+	appliedCountersChangeUserIdPrefix = "change#"
+	appliedCountersChangeTtlSeconds   = 7 * timeutil.DaySeconds
+)
+
+var (
+	incomingVoteTypeAttrNames = map[romancesValueObject.VoteType]string{
+		romancesValueObject.VoteTypeYes:        incomingYesVotesAttrName,
+		romancesValueObject.VoteTypeNo:         incomingNoVotesAttrName,
+		romancesValueObject.VoteTypeCrush:      incomingCrushVotesAttrName,
+		romancesValueObject.VoteTypeCompliment: incomingComplimentVotesAttrName,
+	}
+	outgoingVoteTypeAttrNames = map[romancesValueObject.VoteType]string{
+		romancesValueObject.VoteTypeYes:        outgoingYesVotesAttrName,
+		romancesValueObject.VoteTypeNo:         outgoingNoVotesAttrName,
+		romancesValueObject.VoteTypeCrush:      outgoingCrushVotesAttrName,
+		romancesValueObject.VoteTypeCompliment: outgoingComplimentVotesAttrName,
+	}
 )
 
 type CountersRepository struct {
 	dynamoDbClient platformDynamoDb.Client
+	regionRouter   platformDynamoDb.RegionRouter
 	config         config.Config
+	clock          platform.Clock
 	logger         platform.Logger
+	countersWriter countersItemsWriter
 }
 
 type CountersDocumentSchema struct {
@@ -43,17 +88,72 @@ type CountersDocumentSchema struct {
 	IncomingNo        uint32 `dynamodbav:"in"`
 	OutgoingYes       uint32 `dynamodbav:"oy"`
 	OutgoingNo        uint32 `dynamodbav:"on"`
+
+	IncomingYesVotes        uint32 `dynamodbav:"ity"`
+	IncomingNoVotes         uint32 `dynamodbav:"itn"`
+	IncomingCrushVotes      uint32 `dynamodbav:"itc"`
+	IncomingComplimentVotes uint32 `dynamodbav:"itm"`
+	OutgoingYesVotes        uint32 `dynamodbav:"oty"`
+	OutgoingNoVotes         uint32 `dynamodbav:"otn"`
+	OutgoingCrushVotes      uint32 `dynamodbav:"otc"`
+	OutgoingComplimentVotes uint32 `dynamodbav:"otm"`
+	Matches                 uint32 `dynamodbav:"m"`
+}
+
+func (i *CountersDocumentSchema) add(other CountersDocumentSchema) {
+	i.IncomingYes += other.IncomingYes
+	i.IncomingNo += other.IncomingNo
+	i.OutgoingYes += other.OutgoingYes
+	i.OutgoingNo += other.OutgoingNo
+	i.IncomingYesVotes += other.IncomingYesVotes
+	i.IncomingNoVotes += other.IncomingNoVotes
+	i.IncomingCrushVotes += other.IncomingCrushVotes
+	i.IncomingComplimentVotes += other.IncomingComplimentVotes
+	i.OutgoingYesVotes += other.OutgoingYesVotes
+	i.OutgoingNoVotes += other.OutgoingNoVotes
+	i.OutgoingCrushVotes += other.OutgoingCrushVotes
+	i.OutgoingComplimentVotes += other.OutgoingComplimentVotes
+	i.Matches += other.Matches
+}
+
+func (i CountersDocumentSchema) hasSameCounters(other CountersDocumentSchema) bool {
+	i.UserId, other.UserId = "", ""
+	i.HourUnixTimestamp, other.HourUnixTimestamp = 0, 0
+	return i == other
+}
+
+func (i CountersDocumentSchema) incomingByVoteType() entity.VoteTypeCounters {
+	return entity.VoteTypeCounters{
+		Yes:        i.IncomingYesVotes,
+		No:         i.IncomingNoVotes,
+		Crush:      i.IncomingCrushVotes,
+		Compliment: i.IncomingComplimentVotes,
+	}
+}
+
+func (i CountersDocumentSchema) outgoingByVoteType() entity.VoteTypeCounters {
+	return entity.VoteTypeCounters{
+		Yes:        i.OutgoingYesVotes,
+		No:         i.OutgoingNoVotes,
+		Crush:      i.OutgoingCrushVotes,
+		Compliment: i.OutgoingComplimentVotes,
+	}
 }
 
 func NewCountersRepository(
 	dynamoDbClient platformDynamoDb.Client,
+	regionRouter platformDynamoDb.RegionRouter,
 	config config.Config,
+	clock platform.Clock,
 	logger platform.Logger,
 ) *CountersRepository {
 	return &CountersRepository{
 		dynamoDbClient: dynamoDbClient,
+		regionRouter:   regionRouter,
 		config:         config,
+		clock:          clock,
 		logger:         logger,
+		countersWriter: newCountersItemsWriter(dynamoDbClient, regionRouter, clock, logger),
 	}
 }
 
@@ -61,30 +161,16 @@ func (c *CountersRepository) GetLifetimeCounter(
 	ctx context.Context,
 	activeUserKey sharedValueObject.ActiveUserKey,
 ) (entity.CountersGroup, error) {
-	out, err := c.dynamoDbClient.GetItem(ctx, &dynamodb.GetItemInput{
-		Key:            c.getCountersTableKey(activeUserKey.ActiveUserId(), LifetimeCounterKey),
-		TableName:      aws.String(CountersTableName),
-		ConsistentRead: aws.Bool(true),
-	}, func(o *dynamodb.Options) {
-		o.Region = platformDynamoDb.GetDynamodbRegionByCountry(activeUserKey.CountryId())
-	})
-
+	shardItems, err := c.queryLifetimeCountersShards(ctx, activeUserKey)
 	if err != nil {
 		return entity.CountersGroup{}, err
 	}
 
-	c.logger.Debug(fmt.Sprintf("Got counter from dynamodb: %+v", out))
-
-	if len(out.Item) == 0 {
+	if len(shardItems) == 0 {
 		return entity.CountersGroup{}, nil
 	}
 
-	countersGroupItem := &CountersDocumentSchema{}
-	if err = attributevalue.UnmarshalMap(out.Item, countersGroupItem); err != nil {
-		return entity.CountersGroup{}, err
-	}
-
-	return c.transformCountersGroupItemToEntity(activeUserKey.CountryId(), *countersGroupItem)
+	return c.transformCountersGroupItemToEntity(activeUserKey.CountryId(), mergeCountersShards(shardItems)[0])
 }
 
 func (c *CountersRepository) GetHourlyCounters(
@@ -95,9 +181,10 @@ func (c *CountersRepository) GetHourlyCounters(
 
 	result := map[uint8]*entity.CountersGroup{}
 	maxHour := uint8(0)
+	at := hoursOffsetGroups.At()
 
 	for _, hour := range hoursOffsetGroups.Values() {
-		hourUnixTimestamp := timeutil.HourStart(time.Now().Add(time.Duration(hour) * time.Hour * -1)).Unix()
+		hourUnixTimestamp := timeutil.HourStart(at.Add(time.Duration(hour) * time.Hour * -1)).Unix()
 		result[hour] = &entity.CountersGroup{
 			ActiveUserKey:     activeUserKey,
 			HourUnixTimestamp: int32(hourUnixTimestamp),
@@ -107,37 +194,16 @@ func (c *CountersRepository) GetHourlyCounters(
 		}
 	}
 
-	timeFilter := timeutil.HourStart(time.Now().Add(time.Duration(maxHour) * time.Hour * -1))
-
-	out, err := c.dynamoDbClient.Query(ctx, &dynamodb.QueryInput{
-		TableName:              aws.String(CountersTableName),
-		KeyConditionExpression: aws.String("u = :pk AND h > :sk"),
-		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":pk": &types.AttributeValueMemberS{Value: activeUserKey.ActiveUserId().String()},
-			":sk": &types.AttributeValueMemberN{Value: strconv.FormatInt(timeFilter.Unix(), 10)},
-		},
-	}, func(o *dynamodb.Options) {
-		o.Region = platformDynamoDb.GetDynamodbRegionByCountry(activeUserKey.CountryId())
-	})
+	timeFilter := timeutil.HourStart(at.Add(time.Duration(maxHour) * time.Hour * -1))
 
+	countersItems, err := c.queryHourlyCountersItems(ctx, activeUserKey, timeFilter, timeutil.HourStart(at))
 	if err != nil {
 		return map[uint8]*entity.CountersGroup{}, err
 	}
 
-	c.logger.Debug(fmt.Sprintf("Got counters from dynamodb: %+v", out))
-
-	if len(out.Items) == 0 {
-		return result, nil
-	}
-
 	var countersGroup entity.CountersGroup
-	for _, item := range out.Items {
-		countersGroupItem := &CountersDocumentSchema{}
-		if err = attributevalue.UnmarshalMap(item, &countersGroupItem); err != nil {
-			return map[uint8]*entity.CountersGroup{}, err
-		}
-
-		countersGroup, err = c.transformCountersGroupItemToEntity(activeUserKey.CountryId(), *countersGroupItem)
+	for _, countersItem := range countersItems {
+		countersGroup, err = c.transformCountersGroupItemToEntity(activeUserKey.CountryId(), countersItem)
 		if err != nil {
 			return map[uint8]*entity.CountersGroup{}, err
 		}
@@ -151,18 +217,356 @@ func (c *CountersRepository) GetHourlyCounters(
 			group.IncomingYes += countersGroup.IncomingYes
 			group.OutgoingNo += countersGroup.OutgoingNo
 			group.OutgoingYes += countersGroup.OutgoingYes
+			group.IncomingByVoteType.Add(countersGroup.IncomingByVoteType)
+			group.OutgoingByVoteType.Add(countersGroup.OutgoingByVoteType)
+			group.Matches += countersGroup.Matches
+		}
+	}
+
+	return result, nil
+}
+
+// GetHourlyCountersSeries returns the counters of every one of the given number of the latest hours.
+func (c *CountersRepository) GetHourlyCountersSeries(
+	ctx context.Context,
+	activeUserKey sharedValueObject.ActiveUserKey,
+	hoursCount uint8,
+) ([]entity.CountersGroup, error) {
+	currentHourStart := timeutil.HourStart(c.clock.Now())
+
+	result := make([]entity.CountersGroup, hoursCount)
+	resultIndexes := make(map[int32]int, hoursCount)
+	for i := range result {
+		hourUnixTimestamp := int32(currentHourStart.Add(time.Duration(i-int(hoursCount)+1) * time.Hour).Unix())
+		result[i] = entity.CountersGroup{
+			ActiveUserKey:     activeUserKey,
+			HourUnixTimestamp: hourUnixTimestamp,
+		}
+		resultIndexes[hourUnixTimestamp] = i
+	}
+
+	timeFilter := currentHourStart.Add(time.Duration(hoursCount) * time.Hour * -1)
+
+	countersItems, err := c.queryHourlyCountersItems(ctx, activeUserKey, timeFilter, currentHourStart)
+	if err != nil {
+		return []entity.CountersGroup{}, err
+	}
+
+	for _, countersItem := range countersItems {
+		i, ok := resultIndexes[countersItem.HourUnixTimestamp]
+		if !ok {
+			continue
+		}
+
+		result[i], err = c.transformCountersGroupItemToEntity(activeUserKey.CountryId(), countersItem)
+		if err != nil {
+			return []entity.CountersGroup{}, err
+		}
+	}
+
+	return result, nil
+}
+
+func (c *CountersRepository) queryHourlyCountersItems(
+	ctx context.Context,
+	activeUserKey sharedValueObject.ActiveUserKey,
+	after time.Time,
+	until time.Time,
+) ([]CountersDocumentSchema, error) {
+	shardItems, err := c.queryCountersItems(ctx, activeUserKey.CountryId(), &dynamodb.QueryInput{
+		TableName:              aws.String(CountersTableName),
+		KeyConditionExpression: aws.String("u = :pk AND h BETWEEN :from AND :to"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: activeUserKey.ActiveUserId().String()},
+			// the shards of the hour started at the given time are left out as well
+			":from": &types.AttributeValueMemberN{Value: strconv.FormatInt(after.Unix()+countersShardsSortKeyStep, 10)},
+			":to":   &types.AttributeValueMemberN{Value: strconv.FormatInt(until.Unix()+countersShardsSortKeyStep-1, 10)},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeCountersShards(shardItems), nil
+}
+
+func (c *CountersRepository) queryLifetimeCountersShards(
+	ctx context.Context,
+	activeUserKey sharedValueObject.ActiveUserKey,
+) ([]CountersDocumentSchema, error) {
+	return c.queryCountersItems(ctx, activeUserKey.CountryId(), &dynamodb.QueryInput{
+		TableName:              aws.String(CountersTableName),
+		KeyConditionExpression: aws.String("u = :pk AND h BETWEEN :from AND :to"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":   &types.AttributeValueMemberS{Value: activeUserKey.ActiveUserId().String()},
+			":from": &types.AttributeValueMemberN{Value: strconv.Itoa(LifetimeCounterKey)},
+			":to":   &types.AttributeValueMemberN{Value: strconv.Itoa(LifetimeCounterKey + config.CountersMaxShards - 1)},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+}
+
+func (c *CountersRepository) queryCountersItems(
+	ctx context.Context,
+	countryId uint16,
+	input *dynamodb.QueryInput,
+) ([]CountersDocumentSchema, error) {
+	var countersItems []CountersDocumentSchema
+	for {
+		out, err := c.dynamoDbClient.Query(ctx, input, func(o *dynamodb.Options) {
+			o.Region = c.regionRouter.RegionByCountry(countryId)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		c.logger.Debug(fmt.Sprintf("Got counters from dynamodb: %+v", out))
+
+		for _, item := range out.Items {
+			countersItem := CountersDocumentSchema{}
+			if err = attributevalue.UnmarshalMap(item, &countersItem); err != nil {
+				return nil, err
+			}
+			countersItems = append(countersItems, countersItem)
+		}
+
+		if len(out.LastEvaluatedKey) == 0 {
+			return countersItems, nil
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+}
+
+func mergeCountersShards(shardItems []CountersDocumentSchema) []CountersDocumentSchema {
+	var countersItems []CountersDocumentSchema
+	for _, shardItem := range shardItems {
+		shardItem.HourUnixTimestamp -= shardItem.HourUnixTimestamp % countersShardsSortKeyStep
+
+		last := len(countersItems) - 1
+		if last >= 0 && countersItems[last].HourUnixTimestamp == shardItem.HourUnixTimestamp {
+			countersItems[last].add(shardItem)
+			continue
+		}
+		countersItems = append(countersItems, shardItem)
+	}
+
+	return countersItems
+}
+
+// GetPeriodCounters returns the rollups of the given number of the latest periods.
+func (c *CountersRepository) GetPeriodCounters(
+	ctx context.Context,
+	activeUserKey sharedValueObject.ActiveUserKey,
+	period countersValueObject.CountersPeriod,
+	periodsCount uint16,
+) ([]entity.PeriodCounters, error) {
+	currentPeriodStart := period.StartTime(c.clock.Now())
+	firstPeriodStart := period.Shift(currentPeriodStart, 1-int(periodsCount))
+
+	result := make([]entity.PeriodCounters, periodsCount)
+	resultIndexes := make(map[int64]int, periodsCount)
+	for i := range result {
+		periodStart := period.Shift(currentPeriodStart, -i)
+		result[i] = entity.PeriodCounters{
+			ActiveUserKey: activeUserKey,
+			PeriodStart:   periodStart,
 		}
+		resultIndexes[periodStart.Unix()] = i
+	}
+
+	shardItems, err := c.queryCountersItems(ctx, activeUserKey.CountryId(), &dynamodb.QueryInput{
+		TableName:              aws.String(CountersTableName),
+		KeyConditionExpression: aws.String("u = :pk AND h BETWEEN :from AND :to"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":   &types.AttributeValueMemberS{Value: getPeriodCountersUserId(activeUserKey.ActiveUserId(), period)},
+			":from": &types.AttributeValueMemberN{Value: strconv.FormatInt(firstPeriodStart.Unix(), 10)},
+			":to":   &types.AttributeValueMemberN{Value: strconv.FormatInt(currentPeriodStart.Unix()+config.CountersMaxShards-1, 10)},
+		},
+	})
+	if err != nil {
+		return []entity.PeriodCounters{}, err
+	}
+
+	for _, countersItem := range mergeCountersShards(shardItems) {
+		i, ok := resultIndexes[int64(countersItem.HourUnixTimestamp)]
+		if !ok {
+			continue
+		}
+		result[i].IncomingYes = countersItem.IncomingYes
+		result[i].IncomingNo = countersItem.IncomingNo
+		result[i].OutgoingYes = countersItem.OutgoingYes
+		result[i].OutgoingNo = countersItem.OutgoingNo
+		result[i].IncomingByVoteType = countersItem.incomingByVoteType()
+		result[i].OutgoingByVoteType = countersItem.outgoingByVoteType()
+		result[i].Matches = countersItem.Matches
 	}
 
 	return result, nil
 }
 
+// ReplaceLifetimeCounter spreads the reconciled lifetime counters over the shards of the user.
+func (c *CountersRepository) ReplaceLifetimeCounter(
+	ctx context.Context,
+	current entity.CountersGroup,
+	reconciled entity.CountersGroup,
+) error {
+	activeUserKey := reconciled.ActiveUserKey
+	userId := activeUserKey.ActiveUserId()
+
+	shardItems, err := c.queryLifetimeCountersShards(ctx, activeUserKey)
+	if err != nil {
+		return err
+	}
+
+	stored := CountersDocumentSchema{}
+	storedShards := map[int32]CountersDocumentSchema{}
+	for _, shardItem := range shardItems {
+		stored.add(shardItem)
+		storedShards[shardItem.HourUnixTimestamp] = shardItem
+	}
+	if !stored.hasSameCounters(getLifetimeCountersItem(userId, current, LifetimeCounterKey, 0, 1)) {
+		return counterDomain.ErrCountersChanged
+	}
+
+	var transactItems []types.TransactWriteItem
+	shards := c.config.Counters.Shards.UserShards(userId)
+	for shard := range shards {
+		shardKey := int32(LifetimeCounterKey + shard)
+		item, err := attributevalue.MarshalMap(getLifetimeCountersItem(userId, reconciled, shardKey, shard, shards))
+		if err != nil {
+			return err
+		}
+
+		put := &types.Put{
+			TableName:                aws.String(CountersTableName),
+			Item:                     item,
+			ConditionExpression:      aws.String("attribute_not_exists(#u)"),
+			ExpressionAttributeNames: map[string]string{"#u": UserIdAttrName},
+		}
+		if shardItem, ok := storedShards[shardKey]; ok {
+			condition, exprNames, exprValues := getCountersItemUnchangedCondition(shardItem)
+			put.ConditionExpression = aws.String(condition)
+			put.ExpressionAttributeNames = exprNames
+			put.ExpressionAttributeValues = exprValues
+			delete(storedShards, shardKey)
+		}
+		transactItems = append(transactItems, types.TransactWriteItem{Put: put})
+	}
+
+	// the shards left over from a bigger shards count of the user
+	for shardKey, shardItem := range storedShards {
+		condition, exprNames, exprValues := getCountersItemUnchangedCondition(shardItem)
+		transactItems = append(transactItems, types.TransactWriteItem{Delete: &types.Delete{
+			TableName:                 aws.String(CountersTableName),
+			Key:                       getCountersTableKey(userId, int64(shardKey)),
+			ConditionExpression:       aws.String(condition),
+			ExpressionAttributeNames:  exprNames,
+			ExpressionAttributeValues: exprValues,
+		}})
+	}
+
+	_, err = c.dynamoDbClient.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: transactItems,
+	}, func(o *dynamodb.Options) {
+		o.Region = c.regionRouter.RegionByCountry(activeUserKey.CountryId())
+	})
+
+	if err != nil {
+		var canceledErr *types.TransactionCanceledException
+		if errors.As(err, &canceledErr) {
+			for i := range transactItems {
+				if isConditionalCheckFailed(canceledErr, i) {
+					return counterDomain.ErrCountersChanged
+				}
+			}
+		}
+
+		return err
+	}
+
+	c.logger.Debug(fmt.Sprintf("Replaced lifetime counter of user %s in %d shards: %+v", userId, shards, reconciled))
+
+	return nil
+}
+
+func getCountersItemUnchangedCondition(countersItem CountersDocumentSchema) (string, map[string]string, map[string]types.AttributeValue) {
+	exprNames := map[string]string{}
+	exprValues := map[string]types.AttributeValue{}
+
+	var conditions []string
+	currentValues := []struct {
+		attrName string
+		value    uint32
+	}{
+		{attrName: incomingYesAttrName, value: countersItem.IncomingYes},
+		{attrName: incomingNoAttrName, value: countersItem.IncomingNo},
+		{attrName: outgoingYesAttrName, value: countersItem.OutgoingYes},
+		{attrName: outgoingNoAttrName, value: countersItem.OutgoingNo},
+		{attrName: incomingYesVotesAttrName, value: countersItem.IncomingYesVotes},
+		{attrName: incomingNoVotesAttrName, value: countersItem.IncomingNoVotes},
+		{attrName: incomingCrushVotesAttrName, value: countersItem.IncomingCrushVotes},
+		{attrName: incomingComplimentVotesAttrName, value: countersItem.IncomingComplimentVotes},
+		{attrName: outgoingYesVotesAttrName, value: countersItem.OutgoingYesVotes},
+		{attrName: outgoingNoVotesAttrName, value: countersItem.OutgoingNoVotes},
+		{attrName: outgoingCrushVotesAttrName, value: countersItem.OutgoingCrushVotes},
+		{attrName: outgoingComplimentVotesAttrName, value: countersItem.OutgoingComplimentVotes},
+		{attrName: matchesAttrName, value: countersItem.Matches},
+	}
+	for _, v := range currentValues {
+		exprNames["#"+v.attrName] = v.attrName
+		exprValues[":"+v.attrName] = &types.AttributeValueMemberN{Value: strconv.FormatUint(uint64(v.value), 10)}
+
+		condition := fmt.Sprintf("#%[1]s = :%[1]s", v.attrName)
+		if v.value == 0 {
+			condition = fmt.Sprintf("(attribute_not_exists(#%[1]s) OR %[2]s)", v.attrName, condition)
+		}
+		conditions = append(conditions, condition)
+	}
+
+	return strings.Join(conditions, " AND "), exprNames, exprValues
+}
+
+func getLifetimeCountersItem(
+	userId uuid.UUID,
+	countersGroup entity.CountersGroup,
+	shardKey int32,
+	shard uint16,
+	shards uint16,
+) CountersDocumentSchema {
+	part := func(value uint32) uint32 {
+		result := value / uint32(shards)
+		if uint32(shard) < value%uint32(shards) {
+			result++
+		}
+		return result
+	}
+
+	return CountersDocumentSchema{
+		UserId:            userId.String(),
+		HourUnixTimestamp: shardKey,
+		IncomingYes:       part(countersGroup.IncomingYes),
+		IncomingNo:        part(countersGroup.IncomingNo),
+		OutgoingYes:       part(countersGroup.OutgoingYes),
+		OutgoingNo:        part(countersGroup.OutgoingNo),
+
+		IncomingYesVotes:        part(countersGroup.IncomingByVoteType.Yes),
+		IncomingNoVotes:         part(countersGroup.IncomingByVoteType.No),
+		IncomingCrushVotes:      part(countersGroup.IncomingByVoteType.Crush),
+		IncomingComplimentVotes: part(countersGroup.IncomingByVoteType.Compliment),
+		OutgoingYesVotes:        part(countersGroup.OutgoingByVoteType.Yes),
+		OutgoingNoVotes:         part(countersGroup.OutgoingByVoteType.No),
+		OutgoingCrushVotes:      part(countersGroup.OutgoingByVoteType.Crush),
+		OutgoingComplimentVotes: part(countersGroup.OutgoingByVoteType.Compliment),
+		Matches:                 part(countersGroup.Matches),
+	}
+}
+
 func (c *CountersRepository) IncrYesCounters(
 	ctx context.Context,
 	voteId sharedValueObject.VoteId,
 	counterUpdateGroup countersValueObject.CounterUpdateGroup,
 ) {
-	err := c.incrCounters(ctx, voteId, counterUpdateGroup, outgoingYesAttrName, incomingYesAttrName)
+	err := c.updateCounters(ctx, voteId, countersValueObject.NewVoteCountersChange(counterUpdateGroup, 1, 0))
 	if err != nil {
 		c.logger.Error(fmt.Sprintf("incrYesCounters error: %s", err))
 	}
@@ -173,101 +577,356 @@ func (c *CountersRepository) IncrNoCounters(
 	voteId sharedValueObject.VoteId,
 	counterUpdateGroup countersValueObject.CounterUpdateGroup,
 ) {
-	err := c.incrCounters(ctx, voteId, counterUpdateGroup, outgoingNoAttrName, incomingNoAttrName)
+	err := c.updateCounters(ctx, voteId, countersValueObject.NewVoteCountersChange(counterUpdateGroup, 0, 1))
 	if err != nil {
 		c.logger.Error(fmt.Sprintf("incrNoCounters error: %s", err))
 	}
 }
 
-func (c *CountersRepository) incrCounters(
+func (c *CountersRepository) DecrYesCounters(
+	ctx context.Context,
+	voteId sharedValueObject.VoteId,
+	counterUpdateGroup countersValueObject.CounterUpdateGroup,
+) {
+	err := c.updateCounters(ctx, voteId, countersValueObject.NewVoteCountersChange(counterUpdateGroup, -1, 0))
+	if err != nil {
+		c.logger.Error(fmt.Sprintf("decrYesCounters error: %s", err))
+	}
+}
+
+func (c *CountersRepository) DecrNoCounters(
+	ctx context.Context,
+	voteId sharedValueObject.VoteId,
+	counterUpdateGroup countersValueObject.CounterUpdateGroup,
+) {
+	err := c.updateCounters(ctx, voteId, countersValueObject.NewVoteCountersChange(counterUpdateGroup, 0, -1))
+	if err != nil {
+		c.logger.Error(fmt.Sprintf("decrNoCounters error: %s", err))
+	}
+}
+
+func (c *CountersRepository) TransferNoToYesCounters(
 	ctx context.Context,
 	voteId sharedValueObject.VoteId,
 	counterUpdateGroup countersValueObject.CounterUpdateGroup,
-	activeUserCounter string,
-	peerUserCounter string,
+) {
+	err := c.updateCounters(ctx, voteId, countersValueObject.NewVoteCountersChange(counterUpdateGroup, 1, -1))
+	if err != nil {
+		c.logger.Error(fmt.Sprintf("transferNoToYesCounters error: %s", err))
+	}
+}
+
+func (c *CountersRepository) TransferYesToNoCounters(
+	ctx context.Context,
+	voteId sharedValueObject.VoteId,
+	counterUpdateGroup countersValueObject.CounterUpdateGroup,
+) {
+	err := c.updateCounters(ctx, voteId, countersValueObject.NewVoteCountersChange(counterUpdateGroup, -1, 1))
+	if err != nil {
+		c.logger.Error(fmt.Sprintf("transferYesToNoCounters error: %s", err))
+	}
+}
+
+// ChangeVoteCounters applies the change of the vote type to the counters of both users of the vote.
+func (c *CountersRepository) ChangeVoteCounters(
+	ctx context.Context,
+	voteId sharedValueObject.VoteId,
+	countersChange countersValueObject.VoteCountersChange,
+) {
+	err := c.updateCounters(ctx, voteId, countersChange)
+	if err != nil {
+		c.logger.Error(fmt.Sprintf("changeVoteCounters error: %s", err))
+	}
+}
+
+func (c *CountersRepository) updateCounters(
+	ctx context.Context,
+	voteId sharedValueObject.VoteId,
+	countersChange countersValueObject.VoteCountersChange,
+) error {
+	if countersChange.IsEmpty() {
+		return nil
+	}
+
+	itemUpdatesByRegion := c.countersWriter.groupByRegion(
+		getVoteCountersItemUpdates(voteId, countersChange, c.config.Counters),
+	)
+	for region, itemUpdates := range itemUpdatesByRegion {
+		if err := c.countersWriter.write(ctx, region, itemUpdates, nil); err != nil {
+			return err
+		}
+	}
+
+	c.logger.Debug(fmt.Sprintf("Counters updated for users: %s and %s", voteId.ActiveUserId(), voteId.PeerUserId()))
+	return nil
+}
+
+// ApplyVoteCountersChange applies the changes once, a redelivered change is skipped.
+func (c *CountersRepository) ApplyVoteCountersChange(
+	ctx context.Context,
+	changeId uuid.UUID,
+	voteId sharedValueObject.VoteId,
+	countersChanges []countersValueObject.VoteCountersChange,
+) error {
+	var itemUpdates []countersItemUpdate
+	for _, countersChange := range countersChanges {
+		if !countersChange.IsEmpty() {
+			itemUpdates = append(itemUpdates, getVoteCountersItemUpdates(voteId, countersChange, c.config.Counters)...)
+		}
+	}
+
+	for region, itemUpdates := range c.countersWriter.groupByRegion(itemUpdates) {
+		// the marker goes first, so its index in the cancellation reasons is known
+		marker := getAppliedCountersChangeTransactItem(changeId, region, c.clock.Now())
+		err := c.countersWriter.write(ctx, region, itemUpdates, []types.TransactWriteItem{marker})
+
+		var canceledErr *types.TransactionCanceledException
+		if errors.As(err, &canceledErr) && isConditionalCheckFailed(canceledErr, 0) {
+			c.logger.Debug(fmt.Sprintf("Counters change %s was already applied in %s", changeId, region))
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	c.logger.Debug(fmt.Sprintf("Counters change %s applied for users: %s and %s", changeId, voteId.ActiveUserId(), voteId.PeerUserId()))
+	return nil
+}
+
+func getAppliedCountersChangeTransactItem(changeId uuid.UUID, region string, now time.Time) types.TransactWriteItem {
+	return types.TransactWriteItem{
+		Put: &types.Put{
+			TableName: aws.String(CountersTableName),
+			Item: map[string]types.AttributeValue{
+				UserIdAttrName:               &types.AttributeValueMemberS{Value: appliedCountersChangeUserIdPrefix + changeId.String() + "#" + region},
+				HourUnixTimestampAttrName:    &types.AttributeValueMemberN{Value: "0"},
+				platformDynamoDb.TtlAttrName: &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Unix()+appliedCountersChangeTtlSeconds, 10)},
+			},
+			ConditionExpression:      aws.String("attribute_not_exists(#u)"),
+			ExpressionAttributeNames: map[string]string{"#u": UserIdAttrName},
+		},
+	}
+}
+
+func (c *CountersRepository) addCountersItem(
+	ctx context.Context,
+	itemUpdate countersItemUpdate,
 ) error {
+	exprNames := map[string]string{}
+	exprValues := map[string]types.AttributeValue{}
+
+	var addActions []string
+	for _, d := range itemUpdate.deltas {
+		if d.delta == 0 {
+			continue
+		}
+		exprNames["#"+d.placeholder] = d.attrName
+		exprValues[":"+d.placeholder] = &types.AttributeValueMemberN{Value: strconv.FormatInt(int64(d.delta), 10)}
+		addActions = append(addActions, fmt.Sprintf("#%[1]s :%[1]s", d.placeholder))
+	}
+	if len(addActions) == 0 {
+		return nil
+	}
+
+	updateExpression := "ADD " + strings.Join(addActions, ", ")
+	if itemUpdate.ttl != 0 {
+		exprNames["#ttl"] = platformDynamoDb.TtlAttrName
+		exprValues[":ttl"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(itemUpdate.ttl, 10)}
+		updateExpression += " SET #ttl = :ttl"
+	}
+
+	_, err := c.dynamoDbClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(CountersTableName),
+		Key: map[string]types.AttributeValue{
+			UserIdAttrName:            &types.AttributeValueMemberS{Value: itemUpdate.userId},
+			HourUnixTimestampAttrName: &types.AttributeValueMemberN{Value: strconv.FormatInt(itemUpdate.startTime+int64(itemUpdate.randomShard()), 10)},
+		},
+		UpdateExpression:          aws.String(updateExpression),
+		ExpressionAttributeNames:  exprNames,
+		ExpressionAttributeValues: exprValues,
+	}, func(o *dynamodb.Options) {
+		o.Region = c.regionRouter.RegionByCountry(itemUpdate.countryId)
+	})
+
+	return err
+}
+
+type countersItemUpdate struct {
+	countryId uint16
+	ownerId   uuid.UUID
+	userId    string
+	startTime int64
+	shards    uint16
+	ttl       int64
+	deltas    []counterDelta
+}
 
-	eventStartHourTime := counterUpdateGroup.HourStartTime().Unix()
-	ttl := eventStartHourTime + c.config.Counters.TtlSeconds
+func (u countersItemUpdate) randomShard() uint16 {
+	return uint16(rand.IntN(int(u.shards)))
+}
 
-	lifetimeValues := map[string]types.AttributeValue{
-		":zero": &types.AttributeValueMemberN{Value: "0"},
-		":incr": &types.AttributeValueMemberN{Value: "1"},
+func (u countersItemUpdate) hasDecrements() bool {
+	for _, d := range u.deltas {
+		if d.delta < 0 {
+			return true
+		}
 	}
 
-	hourlyValues := map[string]types.AttributeValue{
-		":zero": &types.AttributeValueMemberN{Value: "0"},
-		":incr": &types.AttributeValueMemberN{Value: "1"},
-		":ttl":  &types.AttributeValueMemberN{Value: strconv.FormatInt(ttl, 10)},
+	return false
+}
+
+type counterDelta struct {
+	placeholder string
+	attrName    string
+	delta       int32
+}
+
+func getVoteCountersItemUpdates(
+	voteId sharedValueObject.VoteId,
+	countersChange countersValueObject.VoteCountersChange,
+	countersConfig config.CountersConfig,
+) []countersItemUpdate {
+	eventHourStartTime := countersChange.UpdateGroup().HourStartTime()
+	eventStartHourTime := eventHourStartTime.Unix()
+	eventDayStartTime := countersValueObject.CountersPeriodDay.StartTime(eventHourStartTime).Unix()
+	eventWeekStartTime := countersValueObject.CountersPeriodWeek.StartTime(eventHourStartTime).Unix()
+	ttlSeconds := countersConfig.TtlSeconds
+	dailyTtlSeconds := int64(countersConfig.DailyRetentionDays) * timeutil.DaySeconds
+	weeklyTtlSeconds := int64(countersConfig.WeeklyRetentionWeeks) * timeutil.WeekSeconds
+
+	users := []struct {
+		countryId         uint16
+		userId            uuid.UUID
+		yesAttrName       string
+		noAttrName        string
+		voteTypeAttrNames map[romancesValueObject.VoteType]string
+	}{
+		{
+			countryId:         voteId.CountryId(),
+			userId:            voteId.ActiveUserId(),
+			yesAttrName:       outgoingYesAttrName,
+			noAttrName:        outgoingNoAttrName,
+			voteTypeAttrNames: outgoingVoteTypeAttrNames,
+		},
+		{
+			countryId:         voteId.PeerCountryId(),
+			userId:            voteId.PeerUserId(),
+			yesAttrName:       incomingYesAttrName,
+			noAttrName:        incomingNoAttrName,
+			voteTypeAttrNames: incomingVoteTypeAttrNames,
+		},
 	}
 
-	hourlyUpdateExpression := aws.String("SET #counterIndex = if_not_exists(#counterIndex, :zero) + :incr, #ttl = :ttl")
-	lifetimeUpdateExpression := aws.String("SET #counterIndex = if_not_exists(#counterIndex, :zero) + :incr")
+	itemUpdates := make([]countersItemUpdate, 0, 4*len(users))
+	for _, user := range users {
+		deltas := []counterDelta{
+			{placeholder: "yes", attrName: user.yesAttrName, delta: countersChange.YesDelta()},
+			{placeholder: "no", attrName: user.noAttrName, delta: countersChange.NoDelta()},
+			{placeholder: "matches", attrName: matchesAttrName, delta: countersChange.MatchesDelta()},
+		}
+		for voteType, attrName := range user.voteTypeAttrNames {
+			deltas = append(deltas, counterDelta{
+				placeholder: attrName,
+				attrName:    attrName,
+				delta:       countersChange.VoteTypeDelta(voteType),
+			})
+		}
 
-	input := &dynamodb.TransactWriteItemsInput{
-		TransactItems: []types.TransactWriteItem{
-			{
-				Update: &types.Update{
-					TableName:        aws.String(CountersTableName),
-					Key:              c.getCountersTableKey(voteId.ActiveUserId(), eventStartHourTime),
-					UpdateExpression: hourlyUpdateExpression,
-					ExpressionAttributeNames: map[string]string{
-						"#counterIndex": activeUserCounter,
-						"#ttl":          platformDynamoDb.TtlAttrName,
-					},
-					ExpressionAttributeValues: hourlyValues,
-				},
+		userId := user.userId.String()
+		shards := countersConfig.Shards.UserShards(user.userId)
+
+		itemUpdates = append(itemUpdates,
+			countersItemUpdate{
+				countryId: user.countryId,
+				ownerId:   user.userId,
+				userId:    userId,
+				startTime: eventStartHourTime,
+				shards:    shards,
+				ttl:       eventStartHourTime + ttlSeconds,
+				deltas:    deltas,
 			},
-			{
-				Update: &types.Update{
-					TableName:        aws.String(CountersTableName),
-					Key:              c.getCountersTableKey(voteId.ActiveUserId(), LifetimeCounterKey),
-					UpdateExpression: lifetimeUpdateExpression,
-					ExpressionAttributeNames: map[string]string{
-						"#counterIndex": activeUserCounter,
-					},
-					ExpressionAttributeValues: lifetimeValues,
-				},
+			countersItemUpdate{
+				countryId: user.countryId,
+				ownerId:   user.userId,
+				userId:    getPeriodCountersUserId(user.userId, countersValueObject.CountersPeriodDay),
+				startTime: eventDayStartTime,
+				shards:    shards,
+				ttl:       eventDayStartTime + dailyTtlSeconds,
+				deltas:    deltas,
 			},
-			{
-				Update: &types.Update{
-					TableName:        aws.String(CountersTableName),
-					Key:              c.getCountersTableKey(voteId.PeerUserId(), eventStartHourTime),
-					UpdateExpression: hourlyUpdateExpression,
-					ExpressionAttributeNames: map[string]string{
-						"#counterIndex": peerUserCounter,
-						"#ttl":          platformDynamoDb.TtlAttrName,
-					},
-					ExpressionAttributeValues: hourlyValues,
-				},
+			countersItemUpdate{
+				countryId: user.countryId,
+				ownerId:   user.userId,
+				userId:    getPeriodCountersUserId(user.userId, countersValueObject.CountersPeriodWeek),
+				startTime: eventWeekStartTime,
+				shards:    shards,
+				ttl:       eventWeekStartTime + weeklyTtlSeconds,
+				deltas:    deltas,
 			},
-			{
-				Update: &types.Update{
-					TableName:        aws.String(CountersTableName),
-					Key:              c.getCountersTableKey(voteId.PeerUserId(), LifetimeCounterKey),
-					UpdateExpression: lifetimeUpdateExpression,
-					ExpressionAttributeNames: map[string]string{
-						"#counterIndex": peerUserCounter,
-					},
-					ExpressionAttributeValues: lifetimeValues,
-				},
+			countersItemUpdate{
+				countryId: user.countryId,
+				ownerId:   user.userId,
+				userId:    userId,
+				startTime: LifetimeCounterKey,
+				shards:    shards,
+				deltas:    deltas,
 			},
-		},
+		)
 	}
 
-	_, err := c.dynamoDbClient.TransactWriteItems(
-		ctx,
-		input,
-		func(o *dynamodb.Options) {
-			o.Region = platformDynamoDb.GetDynamodbRegionByCountry(voteId.CountryId())
-		},
-	)
+	return itemUpdates
+}
+
+func (u countersItemUpdate) toUpdate(shard uint16) *types.Update {
+	exprNames := map[string]string{}
+	exprValues := map[string]types.AttributeValue{}
 
-	if err == nil {
-		c.logger.Debug(fmt.Sprintf("Counters updated for users: %s and %s", voteId.ActiveUserId(), voteId.PeerUserId()))
+	var setActions, conditions []string
+	for _, d := range u.deltas {
+		if d.delta == 0 {
+			continue
+		}
+
+		exprNames["#"+d.placeholder] = d.attrName
+		exprValues[":"+d.placeholder] = &types.AttributeValueMemberN{Value: strconv.FormatInt(int64(d.delta), 10)}
+
+		if d.delta > 0 {
+			exprValues[":zero"] = &types.AttributeValueMemberN{Value: "0"}
+			setActions = append(setActions, fmt.Sprintf("#%[1]s = if_not_exists(#%[1]s, :zero) + :%[1]s", d.placeholder))
+			continue
+		}
+
+		exprValues[":"+d.placeholder+"Min"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(-int64(d.delta), 10)}
+		setActions = append(setActions, fmt.Sprintf("#%[1]s = #%[1]s + :%[1]s", d.placeholder))
+		conditions = append(conditions, fmt.Sprintf("#%[1]s >= :%[1]sMin", d.placeholder))
 	}
 
-	return err
+	if len(setActions) == 0 {
+		return nil
+	}
+
+	if u.ttl != 0 {
+		exprNames["#ttl"] = platformDynamoDb.TtlAttrName
+		exprValues[":ttl"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(u.ttl, 10)}
+		setActions = append(setActions, "#ttl = :ttl")
+	}
+
+	update := &types.Update{
+		TableName: aws.String(CountersTableName),
+		Key: map[string]types.AttributeValue{
+			UserIdAttrName:            &types.AttributeValueMemberS{Value: u.userId},
+			HourUnixTimestampAttrName: &types.AttributeValueMemberN{Value: strconv.FormatInt(u.startTime+int64(shard), 10)},
+		},
+		UpdateExpression:          aws.String("SET " + strings.Join(setActions, ", ")),
+		ExpressionAttributeNames:  exprNames,
+		ExpressionAttributeValues: exprValues,
+	}
+	if len(conditions) > 0 {
+		update.ConditionExpression = aws.String(strings.Join(conditions, " AND "))
+	}
+
+	return update
 }
 
 func (c *CountersRepository) transformCountersGroupItemToEntity(
@@ -285,16 +944,19 @@ func (c *CountersRepository) transformCountersGroupItemToEntity(
 	}
 
 	return entity.CountersGroup{
-		ActiveUserKey:     activeUserKey,
-		HourUnixTimestamp: countersItem.HourUnixTimestamp,
-		IncomingYes:       countersItem.IncomingYes,
-		IncomingNo:        countersItem.IncomingNo,
-		OutgoingYes:       countersItem.OutgoingYes,
-		OutgoingNo:        countersItem.OutgoingNo,
+		ActiveUserKey:      activeUserKey,
+		HourUnixTimestamp:  countersItem.HourUnixTimestamp,
+		IncomingYes:        countersItem.IncomingYes,
+		IncomingNo:         countersItem.IncomingNo,
+		OutgoingYes:        countersItem.OutgoingYes,
+		OutgoingNo:         countersItem.OutgoingNo,
+		IncomingByVoteType: countersItem.incomingByVoteType(),
+		OutgoingByVoteType: countersItem.outgoingByVoteType(),
+		Matches:            countersItem.Matches,
 	}, nil
 }
 
-func (c *CountersRepository) getCountersTableKey(
+func getCountersTableKey(
 	activeUserId uuid.UUID,
 	dayStartTimeUnixTimestamp int64,
 ) map[string]types.AttributeValue {
@@ -303,3 +965,10 @@ func (c *CountersRepository) getCountersTableKey(
 		HourUnixTimestampAttrName: &types.AttributeValueMemberN{Value: strconv.FormatInt(dayStartTimeUnixTimestamp, 10)},
 	}
 }
+
+func getPeriodCountersUserId(activeUserId uuid.UUID, period countersValueObject.CountersPeriod) string {
+	if period == countersValueObject.CountersPeriodWeek {
+		return activeUserId.String() + weeklyCountersUserIdSuffix
+	}
+	return activeUserId.String() + dailyCountersUserIdSuffix
+}
@@ -0,0 +1,152 @@
+package persistence
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type pageCursor struct {
+	Stage uint8         `json:"s,omitempty"`
+	Key   pageCursorKey `json:"k,omitempty"`
+}
+
+type mergedPageCursor struct {
+	Sides []mergedPageCursorSide `json:"m"`
+}
+
+type mergedPageCursorSide struct {
+	Done bool          `json:"d,omitempty"`
+	Key  pageCursorKey `json:"k,omitempty"`
+}
+
+type pageCursorKey map[string]pageCursorAttributeValue
+
+type pageCursorAttributeValue struct {
+	S *string `json:"S,omitempty"`
+	N *string `json:"N,omitempty"`
+}
+
+func encodePageCursor(stage uint8, lastEvaluatedKey map[string]types.AttributeValue) (string, error) {
+	key, err := newPageCursorKey(lastEvaluatedKey)
+	if err != nil {
+		return "", err
+	}
+
+	return encodePageCursorPayload(pageCursor{Stage: stage, Key: key})
+}
+
+func decodePageCursor(encoded string) (uint8, map[string]types.AttributeValue, error) {
+	cursor := pageCursor{}
+	if err := decodePageCursorPayload(encoded, &cursor); err != nil {
+		return 0, nil, err
+	}
+
+	key, err := cursor.Key.toAttributeValues()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return cursor.Stage, key, nil
+}
+
+func encodeMergedPageCursor(done []bool, startKeys []map[string]types.AttributeValue) (string, error) {
+	cursor := mergedPageCursor{Sides: make([]mergedPageCursorSide, len(done))}
+	for i := range done {
+		key, err := newPageCursorKey(startKeys[i])
+		if err != nil {
+			return "", err
+		}
+		cursor.Sides[i] = mergedPageCursorSide{Done: done[i], Key: key}
+	}
+
+	return encodePageCursorPayload(cursor)
+}
+
+func decodeMergedPageCursor(encoded string, sides int) ([]bool, []map[string]types.AttributeValue, error) {
+	cursor := mergedPageCursor{}
+	if err := decodePageCursorPayload(encoded, &cursor); err != nil {
+		return nil, nil, err
+	}
+
+	if len(cursor.Sides) != sides {
+		return nil, nil, fmt.Errorf("cursor has %d sides, expected %d", len(cursor.Sides), sides)
+	}
+
+	done := make([]bool, sides)
+	startKeys := make([]map[string]types.AttributeValue, sides)
+	for i, side := range cursor.Sides {
+		key, err := side.Key.toAttributeValues()
+		if err != nil {
+			return nil, nil, err
+		}
+		done[i] = side.Done
+		startKeys[i] = key
+	}
+
+	return done, startKeys, nil
+}
+
+func newPageCursorKey(lastEvaluatedKey map[string]types.AttributeValue) (pageCursorKey, error) {
+	if len(lastEvaluatedKey) == 0 {
+		return nil, nil
+	}
+
+	key := make(pageCursorKey, len(lastEvaluatedKey))
+	for name, value := range lastEvaluatedKey {
+		switch v := value.(type) {
+		case *types.AttributeValueMemberS:
+			key[name] = pageCursorAttributeValue{S: &v.Value}
+		case *types.AttributeValueMemberN:
+			key[name] = pageCursorAttributeValue{N: &v.Value}
+		default:
+			return nil, fmt.Errorf("unsupported cursor key attribute type %T for %q", value, name)
+		}
+	}
+
+	return key, nil
+}
+
+func (k pageCursorKey) toAttributeValues() (map[string]types.AttributeValue, error) {
+	if len(k) == 0 {
+		return nil, nil
+	}
+
+	key := make(map[string]types.AttributeValue, len(k))
+	for name, value := range k {
+		switch {
+		case value.S != nil && value.N == nil:
+			key[name] = &types.AttributeValueMemberS{Value: *value.S}
+		case value.N != nil && value.S == nil:
+			key[name] = &types.AttributeValueMemberN{Value: *value.N}
+		default:
+			return nil, fmt.Errorf("malformed cursor key attribute %q", name)
+		}
+	}
+
+	return key, nil
+}
+
+func encodePageCursorPayload(cursor any) (string, error) {
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+func decodePageCursorPayload(encoded string, cursor any) error {
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(payload, cursor)
+}
+
+func pageCursorKeyStringEquals(key map[string]types.AttributeValue, name string, expected string) bool {
+	value, ok := key[name].(*types.AttributeValueMemberS)
+	return ok && value.Value == expected
+}
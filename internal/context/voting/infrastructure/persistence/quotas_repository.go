@@ -0,0 +1,131 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"github.bumble.dev/shcherbanich/user-votes-storage/config"
+	quotaValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota/valueobject"
+	romancesValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
+	platformDynamoDb "github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+	"strconv"
+)
+
+const (
+	QuotasTableName               = "Quotas"
+	QuotaUserIdAttrName           = "u"
+	QuotaDayUnixTimestampAttrName = "d"
+)
+
+type QuotasRepository struct {
+	dynamoDbClient platformDynamoDb.Client
+	regionRouter   platformDynamoDb.RegionRouter
+	config         config.Config
+	logger         platform.Logger
+}
+
+func NewQuotasRepository(
+	dynamoDbClient platformDynamoDb.Client,
+	regionRouter platformDynamoDb.RegionRouter,
+	config config.Config,
+	logger platform.Logger,
+) *QuotasRepository {
+	return &QuotasRepository{
+		dynamoDbClient: dynamoDbClient,
+		regionRouter:   regionRouter,
+		config:         config,
+		logger:         logger,
+	}
+}
+
+func (q *QuotasRepository) GetDailyUsage(
+	ctx context.Context,
+	activeUserKey sharedValueObject.ActiveUserKey,
+	day quotaValueObject.QuotaDay,
+) (map[romancesValueObject.VoteType]uint32, error) {
+	out, err := q.dynamoDbClient.GetItem(ctx, &dynamodb.GetItemInput{
+		Key:            getQuotasTableKey(activeUserKey.ActiveUserId(), day),
+		TableName:      aws.String(QuotasTableName),
+		ConsistentRead: aws.Bool(true),
+	}, func(o *dynamodb.Options) {
+		o.Region = q.regionRouter.RegionByCountry(activeUserKey.CountryId())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	q.logger.Debug(fmt.Sprintf("Got quotas from dynamodb: %+v", out))
+
+	usage := map[romancesValueObject.VoteType]uint32{}
+	for voteType := range romancesValueObject.UserVoteTypeToString {
+		value, ok := out.Item[getQuotaUsedAttrName(voteType)].(*types.AttributeValueMemberN)
+		if !ok {
+			continue
+		}
+
+		used, err := strconv.ParseUint(value.Value, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		usage[voteType] = uint32(used)
+	}
+
+	return usage, nil
+}
+
+func getQuotaConsumptionTransactItem(
+	consumption quotaValueObject.QuotaConsumption,
+	ttlSeconds int64,
+) types.TransactWriteItem {
+	ttl := consumption.Day().StartTime().Unix() + ttlSeconds
+
+	return types.TransactWriteItem{
+		Update: &types.Update{
+			TableName:           aws.String(QuotasTableName),
+			Key:                 getQuotasTableKey(consumption.ActiveUserKey().ActiveUserId(), consumption.Day()),
+			UpdateExpression:    aws.String("SET #used = if_not_exists(#used, :zero) + :one, #ttl = :ttl"),
+			ConditionExpression: aws.String("attribute_not_exists(#used) OR #used < :limit"),
+			ExpressionAttributeNames: map[string]string{
+				"#used": getQuotaUsedAttrName(consumption.VoteType()),
+				"#ttl":  platformDynamoDb.TtlAttrName,
+			},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":zero":  &types.AttributeValueMemberN{Value: "0"},
+				":one":   &types.AttributeValueMemberN{Value: "1"},
+				":limit": &types.AttributeValueMemberN{Value: strconv.FormatUint(uint64(consumption.Limit()), 10)},
+				":ttl":   &types.AttributeValueMemberN{Value: strconv.FormatInt(ttl, 10)},
+			},
+		},
+	}
+}
+
+func getQuotaRefundUpdate(consumption quotaValueObject.QuotaConsumption) *types.Update {
+	return &types.Update{
+		TableName:           aws.String(QuotasTableName),
+		Key:                 getQuotasTableKey(consumption.ActiveUserKey().ActiveUserId(), consumption.Day()),
+		UpdateExpression:    aws.String("SET #used = #used - :one"),
+		ConditionExpression: aws.String("#used >= :one"),
+		ExpressionAttributeNames: map[string]string{
+			"#used": getQuotaUsedAttrName(consumption.VoteType()),
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":one": &types.AttributeValueMemberN{Value: "1"},
+		},
+	}
+}
+
+func getQuotaUsedAttrName(voteType romancesValueObject.VoteType) string {
+	return "t" + strconv.Itoa(int(voteType))
+}
+
+func getQuotasTableKey(activeUserId uuid.UUID, day quotaValueObject.QuotaDay) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		QuotaUserIdAttrName:           &types.AttributeValueMemberS{Value: activeUserId.String()},
+		QuotaDayUnixTimestampAttrName: &types.AttributeValueMemberN{Value: strconv.FormatInt(day.StartTime().Unix(), 10)},
+	}
+}
@@ -6,6 +6,10 @@ import (
 	"errors"
 	"fmt"
 	"github.bumble.dev/shcherbanich/user-votes-storage/config"
+	counterDomain "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter"
+	countersValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/valueobject"
+	quotaDomain "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota"
+	quotaValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota/valueobject"
 	romanceDomain "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/entity"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
@@ -18,7 +22,10 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/google/uuid"
+	"maps"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -27,48 +34,87 @@ const (
 	PkUserIdAttrName            = "a"
 	SkUserIdAttrName            = "b"
 	pkUserVoteTypeAttrName      = "e"
+	pkUserMessageAttrName       = "f"
 	pkUserVotedAtAttrName       = "g"
 	pkUserVoteCreatedAtAttrName = "h"
 	pkUserVoteUpdatedAtAttrName = "i"
 	skUserVoteTypeAttrName      = "l"
+	skUserMessageAttrName       = "k"
 	skUserVotedAtAttrName       = "n"
 	skUserVoteCreatedAtAttrName = "o"
 	skUserVoteUpdatedAtAttrName = "p"
 	versionAttrName             = "v"
+	MatchedAtAttrName           = "m"
+	PkUserLikedAtAttrName       = "c"
+	SkUserLikedAtAttrName       = "d"
+	blockedByAttrName           = "z"
+	pkUserCountryIdAttrName     = "q"
+	skUserCountryIdAttrName     = "r"
+
+	RomancesByMaxMinUserIndexName    = "gsiByMaxMinUser"
+	RomancesMatchesByPkUserIndexName = "gsiMatchesByPkUser"
+	RomancesMatchesBySkUserIndexName = "gsiMatchesBySkUser"
+	RomancesLikesByPkUserIndexName   = "gsiLikesByPkUser"
+	RomancesLikesBySkUserIndexName   = "gsiLikesBySkUser"
+
+	romancesPkSideStage uint8 = 0
+	romancesSkSideStage uint8 = 1
+
+	romancesScanStage uint8 = 0
 )
 
 type RomancesRepository struct {
 	dynamoDbClient platformDynamoDb.Client
+	regionRouter   platformDynamoDb.RegionRouter
 	config         config.Config
+	clock          platform.Clock
 	logger         platform.Logger
+	countersWriter countersItemsWriter
 }
 
 type RomanceDocumentSchema struct {
 	PkUserId            string `dynamodbav:"a"`
 	SkUserId            string `dynamodbav:"b"`
 	PkUserVoteType      uint8  `dynamodbav:"e"`
+	PkUserMessage       string `dynamodbav:"f,omitempty"`
 	PkUserVotedAt       *int32 `dynamodbav:"g"`
 	PkUserVoteCreatedAt *int32 `dynamodbav:"h"`
 	PkUserVoteUpdatedAt *int32 `dynamodbav:"i"`
 	SkUserVoteType      uint8  `dynamodbav:"l"`
+	SkUserMessage       string `dynamodbav:"k,omitempty"`
 	SkUserVotedAt       *int32 `dynamodbav:"n"`
 	SkUserVoteCreatedAt *int32 `dynamodbav:"o"`
 	SkUserVoteUpdatedAt *int32 `dynamodbav:"p"`
 	Version             uint32 `dynamodbav:"v"`
+	MatchedAt           *int32 `dynamodbav:"m"`
+	PkUserLikedAt       *int32 `dynamodbav:"c"`
+	SkUserLikedAt       *int32 `dynamodbav:"d"`
+	BlockedBy           string `dynamodbav:"z,omitempty"`
+	PkUserCountryId     uint16 `dynamodbav:"q,omitempty"`
+	SkUserCountryId     uint16 `dynamodbav:"r,omitempty"`
 }
 
 func NewRomancesRepository(
 	dynamoDbClient platformDynamoDb.Client,
+	regionRouter platformDynamoDb.RegionRouter,
 	config config.Config,
+	clock platform.Clock,
 	logger platform.Logger,
 ) *RomancesRepository {
 	return &RomancesRepository{
 		dynamoDbClient: dynamoDbClient,
+		regionRouter:   regionRouter,
 		config:         config,
+		clock:          clock,
 		logger:         logger,
+		countersWriter: newCountersItemsWriter(dynamoDbClient, regionRouter, clock, logger),
 	}
 }
 
+func (r *RomancesRepository) romanceRegion(voteId sharedValueObject.VoteId) string {
+	return r.regionRouter.RomanceRegion(voteId.CountryId(), voteId.PeerCountryId())
+}
+
 func (r *RomancesRepository) getRomancesTableKey(key RomancePrimaryKey) map[string]types.AttributeValue {
 	return map[string]types.AttributeValue{
 		PkUserIdAttrName: &types.AttributeValueMemberS{Value: key.Pk.String()},
@@ -84,40 +130,1292 @@ func (r *RomancesRepository) GetRomance(ctx context.Context, voteId sharedValueO
 		TableName:      aws.String(RomancesTableName),
 		ConsistentRead: aws.Bool(true),
 	}, func(o *dynamodb.Options) {
-		o.Region = platformDynamoDb.GetDynamodbRegionByCountry(voteId.CountryId())
+		o.Region = r.romanceRegion(voteId)
+	})
+
+	if err != nil {
+		return entity.Romance{}, err
+	}
+
+	if out == nil || len(out.Item) == 0 {
+		return entity.CreateEmptyRomance(voteId), nil
+	}
+
+	romanceItem := &RomanceDocumentSchema{}
+	if err = attributevalue.UnmarshalMap(out.Item, romanceItem); err != nil {
+		return entity.Romance{}, err
+	}
+
+	r.logger.Debug(fmt.Sprintf("Got romance from dynamodb: %+v", romanceItem))
+
+	return r.transformRomanceItemToEntity(voteId.CountryId(), voteId.ActiveUserId(), *romanceItem)
+}
+
+func (r *RomancesRepository) AddActiveUserVoteToRomance(
+	ctx context.Context,
+	romance entity.Romance,
+	voteType valueobject.VoteType,
+	message valueobject.ComplimentMessage,
+	votedAt time.Time,
+) (entity.Romance, error) {
+
+	activeUserId := romance.ActiveUserVote.Id.ActiveUserId()
+	countryId := romance.ActiveUserVote.Id.CountryId()
+
+	update, _ := r.getAddActiveUserVoteUpdate(romance, voteType, message, votedAt, r.clock.Now())
+
+	out, err := r.dynamoDbClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		Key:                       update.Key,
+		TableName:                 update.TableName,
+		UpdateExpression:          update.UpdateExpression,
+		ExpressionAttributeNames:  update.ExpressionAttributeNames,
+		ExpressionAttributeValues: update.ExpressionAttributeValues,
+		ConditionExpression:       update.ConditionExpression,
+		ReturnValues:              types.ReturnValueAllNew,
+	}, func(o *dynamodb.Options) {
+		o.Region = r.romanceRegion(romance.ActiveUserVote.Id)
+	})
+
+	if err != nil {
+		var condCheckErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condCheckErr) {
+			return entity.Romance{}, romanceDomain.ErrVersionConflict
+		}
+
+		return entity.Romance{}, err
+	}
+
+	romanceItem := &RomanceDocumentSchema{}
+	if err = attributevalue.UnmarshalMap(out.Attributes, romanceItem); err != nil {
+		return entity.Romance{}, err
+	}
+
+	r.logger.Debug(fmt.Sprintf("Updated romance in dynamodb: %+v", romanceItem))
+
+	return r.transformRomanceItemToEntity(countryId, activeUserId, *romanceItem)
+}
+
+// AddActiveUserVoteToRomanceWithCounters returns ErrCountersNotApplied when the counters of another region fail.
+func (r *RomancesRepository) AddActiveUserVoteToRomanceWithCounters(
+	ctx context.Context,
+	romance entity.Romance,
+	voteType valueobject.VoteType,
+	message valueobject.ComplimentMessage,
+	votedAt time.Time,
+	countersChangeId uuid.UUID,
+	countersChanges []countersValueObject.VoteCountersChange,
+	quotaConsumption *quotaValueObject.QuotaConsumption,
+) (entity.Romance, error) {
+	update, updatedRomance := r.getAddActiveUserVoteUpdate(romance, voteType, message, votedAt, r.clock.Now())
+
+	err := r.writeRomanceTransaction(ctx, romance.ActiveUserVote.Id, update, countersChangeId, countersChanges, quotaConsumption)
+	if errors.Is(err, counterDomain.ErrCountersNotApplied) {
+		return updatedRomance, err
+	}
+	if err != nil {
+		return entity.Romance{}, err
+	}
+
+	r.logger.Debug(fmt.Sprintf("Updated romance and counters in dynamodb: %+v", updatedRomance))
+
+	return updatedRomance, nil
+}
+
+func (r *RomancesRepository) writeRomanceTransaction(
+	ctx context.Context,
+	voteId sharedValueObject.VoteId,
+	romanceUpdate *types.Update,
+	countersChangeId uuid.UUID,
+	countersChanges []countersValueObject.VoteCountersChange,
+	quotaConsumption *quotaValueObject.QuotaConsumption,
+) error {
+	ownerRegion := r.romanceRegion(voteId)
+	voterRegion := r.regionRouter.RegionByCountry(voteId.CountryId())
+
+	transactItems := []types.TransactWriteItem{{Update: romanceUpdate}}
+
+	var transactQuotaConsumption, crossRegionQuotaConsumption *quotaValueObject.QuotaConsumption
+	if quotaConsumption != nil {
+		if voterRegion == ownerRegion {
+			transactQuotaConsumption = quotaConsumption
+			transactItems = append(transactItems, getQuotaConsumptionTransactItem(*quotaConsumption, r.config.VoteQuotas.TtlSeconds))
+		} else {
+			crossRegionQuotaConsumption = quotaConsumption
+		}
+	}
+
+	var countersItemUpdates []countersItemUpdate
+	for _, countersChange := range countersChanges {
+		if !countersChange.IsEmpty() {
+			countersItemUpdates = append(countersItemUpdates, getVoteCountersItemUpdates(voteId, countersChange, r.config.Counters)...)
+		}
+	}
+	countersItemUpdatesByRegion := r.countersWriter.groupByRegion(countersItemUpdates)
+	ownerCountersItemUpdates := countersItemUpdatesByRegion[ownerRegion]
+	delete(countersItemUpdatesByRegion, ownerRegion)
+	if len(countersItemUpdatesByRegion) > 0 {
+		transactItems = append(transactItems, getAppliedCountersChangeTransactItem(countersChangeId, ownerRegion, r.clock.Now()))
+	}
+
+	if crossRegionQuotaConsumption != nil {
+		if err := r.consumeQuota(ctx, *crossRegionQuotaConsumption, voterRegion); err != nil {
+			return err
+		}
+	}
+
+	err := r.countersWriter.write(ctx, ownerRegion, ownerCountersItemUpdates, transactItems)
+	if err != nil {
+		if crossRegionQuotaConsumption != nil {
+			r.refundQuota(ctx, *crossRegionQuotaConsumption, voterRegion)
+		}
+		return toRomanceTransactionError(err, transactQuotaConsumption)
+	}
+
+	var countersErrs []error
+	for region, itemUpdates := range countersItemUpdatesByRegion {
+		marker := getAppliedCountersChangeTransactItem(countersChangeId, region, r.clock.Now())
+		if err = r.countersWriter.write(ctx, region, itemUpdates, []types.TransactWriteItem{marker}); err != nil {
+			countersErrs = append(countersErrs, fmt.Errorf("%s: %w", region, err))
+		}
+	}
+	if len(countersErrs) > 0 {
+		return fmt.Errorf("%w: %w", counterDomain.ErrCountersNotApplied, errors.Join(countersErrs...))
+	}
+
+	return nil
+}
+
+func (r *RomancesRepository) consumeQuota(
+	ctx context.Context,
+	quotaConsumption quotaValueObject.QuotaConsumption,
+	region string,
+) error {
+	update := getQuotaConsumptionTransactItem(quotaConsumption, r.config.VoteQuotas.TtlSeconds).Update
+
+	_, err := r.dynamoDbClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		Key:                       update.Key,
+		TableName:                 update.TableName,
+		UpdateExpression:          update.UpdateExpression,
+		ExpressionAttributeNames:  update.ExpressionAttributeNames,
+		ExpressionAttributeValues: update.ExpressionAttributeValues,
+		ConditionExpression:       update.ConditionExpression,
+	}, func(o *dynamodb.Options) {
+		o.Region = region
+	})
+
+	var condCheckErr *types.ConditionalCheckFailedException
+	if errors.As(err, &condCheckErr) {
+		return quotaDomain.NewQuotaExceededError(quotaConsumption.VoteType(), quotaConsumption.Limit())
+	}
+
+	return err
+}
+
+func (r *RomancesRepository) refundQuota(
+	ctx context.Context,
+	quotaConsumption quotaValueObject.QuotaConsumption,
+	region string,
+) {
+	update := getQuotaRefundUpdate(quotaConsumption)
+
+	_, err := r.dynamoDbClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		Key:                       update.Key,
+		TableName:                 update.TableName,
+		UpdateExpression:          update.UpdateExpression,
+		ExpressionAttributeNames:  update.ExpressionAttributeNames,
+		ExpressionAttributeValues: update.ExpressionAttributeValues,
+		ConditionExpression:       update.ConditionExpression,
+	}, func(o *dynamodb.Options) {
+		o.Region = region
+	})
+
+	if err != nil {
+		r.logger.Error(fmt.Sprintf("refundQuota error: %+v", err))
+	}
+}
+
+func (r *RomancesRepository) getAddActiveUserVoteUpdate(
+	romance entity.Romance,
+	voteType valueobject.VoteType,
+	message valueobject.ComplimentMessage,
+	votedAt time.Time,
+	now time.Time,
+) (*types.Update, entity.Romance) {
+	activeUserId := romance.ActiveUserVote.Id.ActiveUserId()
+	romanceKey := NewRomancePrimaryKey(romance.ActiveUserVote.Id)
+
+	exprNames := map[string]string{
+		"#version": versionAttrName,
+		"#ttl":     platformDynamoDb.TtlAttrName,
+	}
+
+	if romanceKey.isPartitionKey(activeUserId) {
+		exprNames["#voteType"] = pkUserVoteTypeAttrName
+		exprNames["#message"] = pkUserMessageAttrName
+		exprNames["#votedAt"] = pkUserVotedAtAttrName
+		exprNames["#voteCreatedAt"] = pkUserVoteCreatedAtAttrName
+	} else {
+		exprNames["#voteType"] = skUserVoteTypeAttrName
+		exprNames["#message"] = skUserMessageAttrName
+		exprNames["#votedAt"] = skUserVotedAtAttrName
+		exprNames["#voteCreatedAt"] = skUserVoteCreatedAtAttrName
+	}
+
+	currentVersion := int64(romance.Version)
+	ttlSeconds := r.getTtlSecondsForVotesPair(voteType, romance.PeerUserVote.VoteType)
+
+	exprValues := map[string]types.AttributeValue{
+		":voteType":  &types.AttributeValueMemberN{Value: strconv.Itoa(int(voteType))},
+		":votedAt":   &types.AttributeValueMemberN{Value: strconv.FormatInt(votedAt.Unix(), 10)},
+		":createdAt": &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Unix(), 10)},
+		":v":         &types.AttributeValueMemberN{Value: strconv.FormatInt(currentVersion+1, 10)},
+		":ttl":       &types.AttributeValueMemberN{Value: strconv.FormatInt(ttlSeconds, 10)},
+	}
+
+	var conditionExpression string
+	if romance.Version == 0 {
+		conditionExpression = "attribute_not_exists(a) AND attribute_not_exists(b)"
+	} else {
+		conditionExpression = "#version = :expectedV"
+		exprValues[":expectedV"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(currentVersion, 10)}
+	}
+
+	// the stored times have a second precision
+	votedAtUnix := int32(votedAt.Unix())
+	createdAtUnix := int32(now.Unix())
+
+	updatedRomance := romance
+	updatedRomance.ActiveUserVote.VoteType = voteType
+	updatedRomance.ActiveUserVote.Message = message
+	updatedRomance.ActiveUserVote.VotedAt = timeutil.UnixToTimePtr(&votedAtUnix)
+	updatedRomance.ActiveUserVote.CreatedAt = timeutil.UnixToTimePtr(&createdAtUnix)
+	updatedRomance.Version = romance.Version + 1
+
+	setActions, removeNames := r.getIndexedAttrsUpdateActions(romanceKey, updatedRomance, exprNames, exprValues)
+	setActions, removeNames = getMessageUpdateActions(message, setActions, removeNames, exprValues)
+	setActions = getCountryIdsUpdateActions(romanceKey, romance.ActiveUserVote.Id, setActions, exprNames, exprValues)
+	updateExpr := buildUpdateExpression(
+		append([]string{"#voteType = :voteType", "#votedAt = :votedAt", "#voteCreatedAt = :createdAt", "#version = :v", "#ttl = :ttl"}, setActions...),
+		removeNames,
+	)
+
+	return &types.Update{
+		Key:                       r.getRomancesTableKey(romanceKey),
+		TableName:                 aws.String(RomancesTableName),
+		UpdateExpression:          updateExpr,
+		ExpressionAttributeNames:  exprNames,
+		ExpressionAttributeValues: exprValues,
+		ConditionExpression:       aws.String(conditionExpression),
+	}, updatedRomance
+}
+
+func toRomanceTransactionError(err error, quotaConsumption *quotaValueObject.QuotaConsumption) error {
+	var canceledErr *types.TransactionCanceledException
+	if !errors.As(err, &canceledErr) {
+		return err
+	}
+
+	if quotaConsumption != nil && isConditionalCheckFailed(canceledErr, 1) {
+		return quotaDomain.NewQuotaExceededError(quotaConsumption.VoteType(), quotaConsumption.Limit())
+	}
+
+	if isConditionalCheckFailed(canceledErr, 0) {
+		return romanceDomain.ErrVersionConflict
+	}
+
+	return err
+}
+
+func isConditionalCheckFailed(canceledErr *types.TransactionCanceledException, itemIndex int) bool {
+	if itemIndex >= len(canceledErr.CancellationReasons) {
+		return false
+	}
+
+	code := canceledErr.CancellationReasons[itemIndex].Code
+	return code != nil && *code == "ConditionalCheckFailed"
+}
+
+func (r *RomancesRepository) DeleteRomance(
+	ctx context.Context,
+	voteId sharedValueObject.VoteId,
+) error {
+	romanceKey := NewRomancePrimaryKey(voteId)
+	out, err := r.dynamoDbClient.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		Key:       r.getRomancesTableKey(romanceKey),
+		TableName: aws.String(RomancesTableName),
+	}, func(o *dynamodb.Options) {
+		o.Region = r.romanceRegion(voteId)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	r.logger.Debug(fmt.Sprintf("Romance deleted from dynamodb: %+v", out))
+	return nil
+}
+
+// DeleteUserRomances deletes the romances of the user from every region which may keep them.
+func (r *RomancesRepository) DeleteUserRomances(
+	ctx context.Context,
+	activeUserKey sharedValueObject.ActiveUserKey,
+) error {
+	deleted := 0
+	for _, region := range r.regionRouter.UserRomanceRegions(activeUserKey.CountryId()) {
+		regionDeleted, err := r.deleteUserRomancesInRegion(ctx, activeUserKey, region)
+		if err != nil {
+			return err
+		}
+		deleted += regionDeleted
+	}
+
+	r.logger.Debug(fmt.Sprintf("Deleted %d romances of user %s from dynamodb", deleted, activeUserKey.ActiveUserId()))
+	return nil
+}
+
+func (r *RomancesRepository) deleteUserRomancesInRegion(
+	ctx context.Context,
+	activeUserKey sharedValueObject.ActiveUserKey,
+	region string,
+) (int, error) {
+	queries := []*dynamodb.QueryInput{
+		{
+			TableName:              aws.String(RomancesTableName),
+			KeyConditionExpression: aws.String("#pk = :uid"),
+			ExpressionAttributeNames: map[string]string{
+				"#pk": PkUserIdAttrName,
+				"#sk": SkUserIdAttrName,
+			},
+			ProjectionExpression: aws.String("#pk, #sk"),
+		},
+		{
+			TableName:              aws.String(RomancesTableName),
+			IndexName:              aws.String(RomancesByMaxMinUserIndexName),
+			KeyConditionExpression: aws.String("#sk = :uid"),
+			ExpressionAttributeNames: map[string]string{
+				"#pk": PkUserIdAttrName,
+				"#sk": SkUserIdAttrName,
+			},
+			ProjectionExpression: aws.String("#pk, #sk"),
+		},
+	}
+
+	deleted := 0
+	for _, query := range queries {
+		query.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":uid": &types.AttributeValueMemberS{Value: activeUserKey.ActiveUserId().String()},
+		}
+
+		for {
+			out, err := r.dynamoDbClient.Query(ctx, query, func(o *dynamodb.Options) {
+				o.Region = region
+			})
+			if err != nil {
+				return 0, err
+			}
+
+			requests := make([]types.WriteRequest, 0, len(out.Items))
+			for _, item := range out.Items {
+				requests = append(requests, types.WriteRequest{
+					DeleteRequest: &types.DeleteRequest{
+						Key: map[string]types.AttributeValue{
+							PkUserIdAttrName: item[PkUserIdAttrName],
+							SkUserIdAttrName: item[SkUserIdAttrName],
+						},
+					},
+				})
+			}
+
+			if err = r.batchWriteRomances(ctx, requests, region); err != nil {
+				return 0, err
+			}
+			deleted += len(requests)
+
+			if len(out.LastEvaluatedKey) == 0 {
+				break
+			}
+			query.ExclusiveStartKey = out.LastEvaluatedKey
+		}
+	}
+
+	return deleted, nil
+}
+
+// ListUserRomances lists the romances of the user region by region.
+func (r *RomancesRepository) ListUserRomances(
+	ctx context.Context,
+	activeUserKey sharedValueObject.ActiveUserKey,
+	pageRequest sharedValueObject.PageRequest,
+) (entity.RomancesPage, error) {
+	activeUserId := activeUserKey.ActiveUserId()
+	regions := r.regionRouter.UserRomanceRegions(activeUserKey.CountryId())
+	lastStage := uint8(2*len(regions) - 1)
+
+	stage, startKey, err := r.decodeRomancesPageCursor(activeUserId, pageRequest, lastStage)
+	if err != nil {
+		return entity.RomancesPage{}, err
+	}
+
+	page := entity.RomancesPage{Romances: []entity.Romance{}}
+	remaining := pageRequest.Limit()
+
+	for {
+		region := regions[stage/2]
+		side := stage % 2
+
+		input := &dynamodb.QueryInput{
+			TableName: aws.String(RomancesTableName),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":uid": &types.AttributeValueMemberS{Value: activeUserId.String()},
+			},
+			ExclusiveStartKey: startKey,
+			Limit:             aws.Int32(remaining),
+		}
+		if side == romancesPkSideStage {
+			input.KeyConditionExpression = aws.String(PkUserIdAttrName + " = :uid")
+		} else {
+			input.IndexName = aws.String(RomancesByMaxMinUserIndexName)
+			input.KeyConditionExpression = aws.String(SkUserIdAttrName + " = :uid")
+		}
+
+		out, err := r.dynamoDbClient.Query(ctx, input, func(o *dynamodb.Options) {
+			o.Region = region
+		})
+		if err != nil {
+			return entity.RomancesPage{}, err
+		}
+
+		romanceItems, err := r.getQueriedRomanceItems(ctx, side, out.Items, region)
+		if err != nil {
+			return entity.RomancesPage{}, err
+		}
+
+		for _, romanceItem := range romanceItems {
+			romance, err := r.transformRomanceItemToEntity(activeUserKey.CountryId(), activeUserId, romanceItem)
+			if err != nil {
+				return entity.RomancesPage{}, err
+			}
+			page.Romances = append(page.Romances, romance)
+		}
+
+		remaining -= int32(len(out.Items))
+		startKey = out.LastEvaluatedKey
+
+		if len(startKey) == 0 {
+			if stage == lastStage {
+				break
+			}
+			stage++
+		}
+
+		if remaining <= 0 {
+			page.NextCursor, err = encodePageCursor(stage, startKey)
+			if err != nil {
+				return entity.RomancesPage{}, err
+			}
+			break
+		}
+	}
+
+	r.logger.Debug(fmt.Sprintf("Listed %d romances of user %s from dynamodb", len(page.Romances), activeUserId))
+
+	return page, nil
+}
+
+type sortedIndexSide struct {
+	indexName            string
+	ownerAttrName        string
+	sortAttrName         string
+	peerVoteTypeAttrName string
+	region               string
+}
+
+type sortedIndexItem struct {
+	romanceItem RomanceDocumentSchema
+	indexKey    map[string]types.AttributeValue
+	sortValue   int64
+}
+
+var matchesIndexSides = []sortedIndexSide{
+	{
+		indexName:            RomancesMatchesByPkUserIndexName,
+		ownerAttrName:        PkUserIdAttrName,
+		sortAttrName:         MatchedAtAttrName,
+		peerVoteTypeAttrName: skUserVoteTypeAttrName,
+	},
+	{
+		indexName:            RomancesMatchesBySkUserIndexName,
+		ownerAttrName:        SkUserIdAttrName,
+		sortAttrName:         MatchedAtAttrName,
+		peerVoteTypeAttrName: pkUserVoteTypeAttrName,
+	},
+}
+
+var likesIndexSides = []sortedIndexSide{
+	{
+		indexName:            RomancesLikesByPkUserIndexName,
+		ownerAttrName:        PkUserIdAttrName,
+		sortAttrName:         PkUserLikedAtAttrName,
+		peerVoteTypeAttrName: skUserVoteTypeAttrName,
+	},
+	{
+		indexName:            RomancesLikesBySkUserIndexName,
+		ownerAttrName:        SkUserIdAttrName,
+		sortAttrName:         SkUserLikedAtAttrName,
+		peerVoteTypeAttrName: pkUserVoteTypeAttrName,
+	},
+}
+
+func (r *RomancesRepository) ListUserMatches(
+	ctx context.Context,
+	activeUserKey sharedValueObject.ActiveUserKey,
+	pageRequest sharedValueObject.PageRequest,
+) (entity.RomancesPage, error) {
+	page, err := r.listMergedIndexSides(ctx, activeUserKey, pageRequest, matchesIndexSides, nil)
+	if err != nil {
+		return entity.RomancesPage{}, err
+	}
+
+	r.logger.Debug(fmt.Sprintf("Listed %d matches of user %s from dynamodb", len(page.Romances), activeUserKey.ActiveUserId()))
+
+	return page, nil
+}
+
+func (r *RomancesRepository) ListUserLikes(
+	ctx context.Context,
+	activeUserKey sharedValueObject.ActiveUserKey,
+	peerVoteTypes []valueobject.VoteType,
+	pageRequest sharedValueObject.PageRequest,
+) (entity.RomancesPage, error) {
+	page, err := r.listMergedIndexSides(ctx, activeUserKey, pageRequest, likesIndexSides, peerVoteTypes)
+	if err != nil {
+		return entity.RomancesPage{}, err
+	}
+
+	r.logger.Debug(fmt.Sprintf("Listed %d likes of user %s from dynamodb", len(page.Romances), activeUserKey.ActiveUserId()))
+
+	return page, nil
+}
+
+func (r *RomancesRepository) listMergedIndexSides(
+	ctx context.Context,
+	activeUserKey sharedValueObject.ActiveUserKey,
+	pageRequest sharedValueObject.PageRequest,
+	indexSides []sortedIndexSide,
+	peerVoteTypes []valueobject.VoteType,
+) (entity.RomancesPage, error) {
+	activeUserId := activeUserKey.ActiveUserId()
+
+	var sides []sortedIndexSide
+	for _, region := range r.regionRouter.UserRomanceRegions(activeUserKey.CountryId()) {
+		for _, side := range indexSides {
+			side.region = region
+			sides = append(sides, side)
+		}
+	}
+
+	done := make([]bool, len(sides))
+	startKeys := make([]map[string]types.AttributeValue, len(sides))
+	if !pageRequest.IsFirstPage() {
+		var err error
+		done, startKeys, err = decodeMergedPageCursor(pageRequest.Cursor(), len(sides))
+		if err != nil {
+			return entity.RomancesPage{}, fmt.Errorf("%w: %s", romanceDomain.ErrInvalidCursor, err)
+		}
+		for i, startKey := range startKeys {
+			if startKey != nil && !isValidSortedIndexStartKey(startKey, sides[i], activeUserId) {
+				return entity.RomancesPage{}, romanceDomain.ErrInvalidCursor
+			}
+		}
+	}
+
+	sideItems := make([][]sortedIndexItem, len(sides))
+	sideLastEvaluatedKeys := make([]map[string]types.AttributeValue, len(sides))
+	for i, side := range sides {
+		if done[i] {
+			continue
+		}
+
+		var err error
+		sideItems[i], sideLastEvaluatedKeys[i], err = r.querySortedIndexSide(
+			ctx, activeUserId, side, startKeys[i], peerVoteTypes, pageRequest.Limit(),
+		)
+		if err != nil {
+			return entity.RomancesPage{}, err
+		}
+	}
+
+	page := entity.RomancesPage{Romances: []entity.Romance{}}
+	consumed := make([]int, len(sides))
+	for int32(len(page.Romances)) < pageRequest.Limit() {
+		next := -1
+		for i := range sides {
+			if consumed[i] >= len(sideItems[i]) {
+				continue
+			}
+			if next == -1 || sideItems[i][consumed[i]].sortValue > sideItems[next][consumed[next]].sortValue {
+				next = i
+			}
+		}
+		if next == -1 {
+			break
+		}
+
+		item := sideItems[next][consumed[next]]
+		consumed[next]++
+
+		romance, err := r.transformRomanceItemToEntity(activeUserKey.CountryId(), activeUserId, item.romanceItem)
+		if err != nil {
+			return entity.RomancesPage{}, err
+		}
+		page.Romances = append(page.Romances, romance)
+	}
+
+	for i := range sides {
+		if done[i] {
+			continue
+		}
+		switch {
+		case consumed[i] == len(sideItems[i]) && len(sideLastEvaluatedKeys[i]) == 0:
+			done[i] = true
+			startKeys[i] = nil
+		case consumed[i] == len(sideItems[i]):
+			startKeys[i] = sideLastEvaluatedKeys[i]
+		case consumed[i] > 0:
+			startKeys[i] = sideItems[i][consumed[i]-1].indexKey
+		}
+	}
+
+	if slices.Contains(done, false) {
+		var err error
+		page.NextCursor, err = encodeMergedPageCursor(done, startKeys)
+		if err != nil {
+			return entity.RomancesPage{}, err
+		}
+	}
+
+	return page, nil
+}
+
+func (r *RomancesRepository) querySortedIndexSide(
+	ctx context.Context,
+	activeUserId uuid.UUID,
+	side sortedIndexSide,
+	startKey map[string]types.AttributeValue,
+	peerVoteTypes []valueobject.VoteType,
+	limit int32,
+) ([]sortedIndexItem, map[string]types.AttributeValue, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(RomancesTableName),
+		IndexName:              aws.String(side.indexName),
+		KeyConditionExpression: aws.String(side.ownerAttrName + " = :uid"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":uid": &types.AttributeValueMemberS{Value: activeUserId.String()},
+		},
+		ScanIndexForward: aws.Bool(false),
+	}
+
+	if len(peerVoteTypes) > 0 {
+		placeholders := make([]string, len(peerVoteTypes))
+		for i, voteType := range peerVoteTypes {
+			placeholders[i] = ":peerVoteType" + strconv.Itoa(i)
+			input.ExpressionAttributeValues[placeholders[i]] = &types.AttributeValueMemberN{Value: strconv.Itoa(int(voteType))}
+		}
+		input.ExpressionAttributeNames = map[string]string{"#peerVoteType": side.peerVoteTypeAttrName}
+		input.FilterExpression = aws.String("#peerVoteType IN (" + strings.Join(placeholders, ", ") + ")")
+	}
+
+	items := make([]sortedIndexItem, 0, limit)
+	for {
+		input.ExclusiveStartKey = startKey
+		input.Limit = aws.Int32(limit - int32(len(items)))
+
+		out, err := r.dynamoDbClient.Query(ctx, input, func(o *dynamodb.Options) {
+			o.Region = side.region
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, item := range out.Items {
+			indexedItem, err := newSortedIndexItem(item, side)
+			if err != nil {
+				return nil, nil, err
+			}
+			items = append(items, indexedItem)
+		}
+
+		startKey = out.LastEvaluatedKey
+		if len(startKey) == 0 || int32(len(items)) >= limit {
+			return items, startKey, nil
+		}
+	}
+}
+
+func newSortedIndexItem(item map[string]types.AttributeValue, side sortedIndexSide) (sortedIndexItem, error) {
+	romanceItem := RomanceDocumentSchema{}
+	if err := attributevalue.UnmarshalMap(item, &romanceItem); err != nil {
+		return sortedIndexItem{}, err
+	}
+
+	sortAttr, ok := item[side.sortAttrName].(*types.AttributeValueMemberN)
+	if !ok {
+		return sortedIndexItem{}, fmt.Errorf(
+			"romance %s/%s indexed in %s has no %q attribute", romanceItem.PkUserId, romanceItem.SkUserId, side.indexName, side.sortAttrName,
+		)
+	}
+	sortValue, err := strconv.ParseInt(sortAttr.Value, 10, 64)
+	if err != nil {
+		return sortedIndexItem{}, err
+	}
+
+	return sortedIndexItem{
+		romanceItem: romanceItem,
+		indexKey: map[string]types.AttributeValue{
+			PkUserIdAttrName:  item[PkUserIdAttrName],
+			SkUserIdAttrName:  item[SkUserIdAttrName],
+			side.sortAttrName: sortAttr,
+		},
+		sortValue: sortValue,
+	}, nil
+}
+
+func isValidSortedIndexStartKey(startKey map[string]types.AttributeValue, side sortedIndexSide, activeUserId uuid.UUID) bool {
+	_, pkOk := startKey[PkUserIdAttrName].(*types.AttributeValueMemberS)
+	_, skOk := startKey[SkUserIdAttrName].(*types.AttributeValueMemberS)
+	_, sortOk := startKey[side.sortAttrName].(*types.AttributeValueMemberN)
+
+	return len(startKey) == 3 && pkOk && skOk && sortOk &&
+		pageCursorKeyStringEquals(startKey, side.ownerAttrName, activeUserId.String())
+}
+
+// ScanRomanceUsers lists the users of the romances in one scan segment of the region of the country.
+func (r *RomancesRepository) ScanRomanceUsers(
+	ctx context.Context,
+	countryId uint16,
+	segment int32,
+	totalSegments int32,
+	pageRequest sharedValueObject.PageRequest,
+) (entity.RomanceUsersPage, error) {
+	startKey, err := getScanStartKey(segment, totalSegments, pageRequest)
+	if err != nil {
+		return entity.RomanceUsersPage{}, err
+	}
+
+	out, err := r.dynamoDbClient.Scan(ctx, &dynamodb.ScanInput{
+		TableName:            aws.String(RomancesTableName),
+		Segment:              aws.Int32(segment),
+		TotalSegments:        aws.Int32(totalSegments),
+		ProjectionExpression: aws.String("#pk, #sk, #pkCountryId, #skCountryId"),
+		ExpressionAttributeNames: map[string]string{
+			"#pk":          PkUserIdAttrName,
+			"#sk":          SkUserIdAttrName,
+			"#pkCountryId": pkUserCountryIdAttrName,
+			"#skCountryId": skUserCountryIdAttrName,
+		},
+		ExclusiveStartKey: startKey,
+		Limit:             aws.Int32(pageRequest.Limit()),
+	}, func(o *dynamodb.Options) {
+		o.Region = r.regionRouter.RegionByCountry(countryId)
+	})
+	if err != nil {
+		return entity.RomanceUsersPage{}, err
+	}
+
+	page := entity.RomanceUsersPage{UserKeys: []sharedValueObject.ActiveUserKey{}}
+	seen := map[uuid.UUID]struct{}{}
+	for _, item := range out.Items {
+		romanceKey, err := romancePrimaryKeyFromItem(item)
+		if err != nil {
+			return entity.RomanceUsersPage{}, err
+		}
+
+		userCountryIds := map[uuid.UUID]uint16{
+			romanceKey.Pk: uint16(getNumberAttr(item, pkUserCountryIdAttrName)),
+			romanceKey.Sk: uint16(getNumberAttr(item, skUserCountryIdAttrName)),
+		}
+		for _, userId := range []uuid.UUID{romanceKey.Pk, romanceKey.Sk} {
+			if _, ok := seen[userId]; ok {
+				continue
+			}
+			seen[userId] = struct{}{}
+
+			userCountryId := userCountryIds[userId]
+			if userCountryId == 0 {
+				userCountryId = countryId
+			}
+			userKey, err := sharedValueObject.NewActiveUserKey(userCountryId, userId)
+			if err != nil {
+				return entity.RomanceUsersPage{}, err
+			}
+			page.UserKeys = append(page.UserKeys, userKey)
+		}
+	}
+
+	if len(out.LastEvaluatedKey) > 0 {
+		page.NextCursor, err = encodePageCursor(romancesScanStage, out.LastEvaluatedKey)
+		if err != nil {
+			return entity.RomanceUsersPage{}, err
+		}
+	}
+
+	r.logger.Debug(fmt.Sprintf("Scanned %d romance users of segment %d/%d from dynamodb", len(page.UserKeys), segment, totalSegments))
+
+	return page, nil
+}
+
+// BackfillRomanceIndexes sets the missing match and like index attributes in one scan segment.
+func (r *RomancesRepository) BackfillRomanceIndexes(
+	ctx context.Context,
+	countryId uint16,
+	segment int32,
+	totalSegments int32,
+	pageRequest sharedValueObject.PageRequest,
+) (entity.RomancesBackfillPage, error) {
+	startKey, err := getScanStartKey(segment, totalSegments, pageRequest)
+	if err != nil {
+		return entity.RomancesBackfillPage{}, err
+	}
+
+	region := r.regionRouter.RegionByCountry(countryId)
+	out, err := r.dynamoDbClient.Scan(ctx, &dynamodb.ScanInput{
+		TableName:         aws.String(RomancesTableName),
+		Segment:           aws.Int32(segment),
+		TotalSegments:     aws.Int32(totalSegments),
+		ExclusiveStartKey: startKey,
+		Limit:             aws.Int32(pageRequest.Limit()),
+	}, func(o *dynamodb.Options) {
+		o.Region = region
+	})
+	if err != nil {
+		return entity.RomancesBackfillPage{}, err
+	}
+
+	page := entity.RomancesBackfillPage{}
+	for _, item := range out.Items {
+		updated, err := r.backfillRomanceItemIndexes(ctx, countryId, item, region)
+		if err != nil {
+			return entity.RomancesBackfillPage{}, err
+		}
+		if updated {
+			page.Updated++
+		}
+	}
+
+	if len(out.LastEvaluatedKey) > 0 {
+		page.NextCursor, err = encodePageCursor(romancesScanStage, out.LastEvaluatedKey)
+		if err != nil {
+			return entity.RomancesBackfillPage{}, err
+		}
+	}
+
+	r.logger.Debug(fmt.Sprintf("Backfilled %d romance indexes of segment %d/%d in dynamodb", page.Updated, segment, totalSegments))
+
+	return page, nil
+}
+
+func (r *RomancesRepository) backfillRomanceItemIndexes(
+	ctx context.Context,
+	countryId uint16,
+	item map[string]types.AttributeValue,
+	region string,
+) (bool, error) {
+	romanceItem := RomanceDocumentSchema{}
+	if err := attributevalue.UnmarshalMap(item, &romanceItem); err != nil {
+		return false, err
+	}
+	pkUserId, err := uuid.Parse(romanceItem.PkUserId)
+	if err != nil {
+		return false, err
+	}
+	romance, err := r.transformRomanceItemToEntity(countryId, pkUserId, romanceItem)
+	if err != nil {
+		return false, err
+	}
+
+	romanceKey := NewRomancePrimaryKey(romance.ActiveUserVote.Id)
+	exprNames := map[string]string{"#version": versionAttrName}
+	exprValues := map[string]types.AttributeValue{
+		":expectedV": &types.AttributeValueMemberN{Value: strconv.FormatUint(uint64(romanceItem.Version), 10)},
+	}
+	setActions, removeNames := r.getIndexedAttrsUpdateActions(romanceKey, romance, exprNames, exprValues)
+	if hasIndexedAttrs(item, setActions, removeNames, exprNames, exprValues) {
+		return false, nil
+	}
+
+	_, err = r.dynamoDbClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		Key:       r.getRomancesTableKey(romanceKey),
+		TableName: aws.String(RomancesTableName),
+		// setting the version it is checked against keeps the expression valid when there is nothing else to set
+		UpdateExpression:          buildUpdateExpression(append([]string{"#version = :expectedV"}, setActions...), removeNames),
+		ExpressionAttributeNames:  exprNames,
+		ExpressionAttributeValues: exprValues,
+		ConditionExpression:       aws.String("#version = :expectedV"),
+	}, func(o *dynamodb.Options) {
+		o.Region = region
+	})
+	if err != nil {
+		var condCheckErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condCheckErr) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func hasIndexedAttrs(
+	item map[string]types.AttributeValue,
+	setActions []string,
+	removeNames []string,
+	exprNames map[string]string,
+	exprValues map[string]types.AttributeValue,
+) bool {
+	for _, name := range removeNames {
+		if _, ok := item[exprNames[name]]; ok {
+			return false
+		}
+	}
+
+	for _, action := range setActions {
+		name, value, _ := strings.Cut(action, " = ")
+		attrName := exprNames[name]
+		if _, ok := item[attrName]; !ok || getNumberAttr(item, attrName) != getNumberAttr(exprValues, value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func getScanStartKey(
+	segment int32,
+	totalSegments int32,
+	pageRequest sharedValueObject.PageRequest,
+) (map[string]types.AttributeValue, error) {
+	if totalSegments < 1 || segment < 0 || segment >= totalSegments {
+		return nil, fmt.Errorf("invalid scan segment %d of %d", segment, totalSegments)
+	}
+
+	if pageRequest.IsFirstPage() {
+		return nil, nil
+	}
+
+	stage, key, err := decodePageCursor(pageRequest.Cursor())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", romanceDomain.ErrInvalidCursor, err)
+	}
+	if _, err = romancePrimaryKeyFromItem(key); stage != romancesScanStage || len(key) != 2 || err != nil {
+		return nil, romanceDomain.ErrInvalidCursor
+	}
+
+	return key, nil
+}
+
+func (r *RomancesRepository) decodeRomancesPageCursor(
+	activeUserId uuid.UUID,
+	pageRequest sharedValueObject.PageRequest,
+	lastStage uint8,
+) (uint8, map[string]types.AttributeValue, error) {
+	if pageRequest.IsFirstPage() {
+		return romancesPkSideStage, nil, nil
+	}
+
+	stage, startKey, err := decodePageCursor(pageRequest.Cursor())
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: %s", romanceDomain.ErrInvalidCursor, err)
+	}
+
+	if stage > lastStage {
+		return 0, nil, romanceDomain.ErrInvalidCursor
+	}
+
+	if startKey == nil {
+		return stage, nil, nil
+	}
+
+	ownerAttrName := PkUserIdAttrName
+	if stage%2 == romancesSkSideStage {
+		ownerAttrName = SkUserIdAttrName
+	}
+
+	_, pkOk := startKey[PkUserIdAttrName].(*types.AttributeValueMemberS)
+	_, skOk := startKey[SkUserIdAttrName].(*types.AttributeValueMemberS)
+	if len(startKey) != 2 || !pkOk || !skOk || !pageCursorKeyStringEquals(startKey, ownerAttrName, activeUserId.String()) {
+		return 0, nil, romanceDomain.ErrInvalidCursor
+	}
+
+	return stage, startKey, nil
+}
+
+func (r *RomancesRepository) GetRomances(
+	ctx context.Context,
+	voteIds []sharedValueObject.VoteId,
+) ([]entity.Romance, error) {
+	if len(voteIds) == 0 {
+		return []entity.Romance{}, nil
+	}
+
+	// the romances with peers of other countries may be kept in other regions
+	keys := make([]RomancePrimaryKey, len(voteIds))
+	keysByRegion := map[string][]RomancePrimaryKey{}
+	for i, voteId := range voteIds {
+		keys[i] = NewRomancePrimaryKey(voteId)
+		region := r.romanceRegion(voteId)
+		keysByRegion[region] = append(keysByRegion[region], keys[i])
+	}
+
+	found := make(map[RomancePrimaryKey]RomanceDocumentSchema, len(keys))
+	for region, regionKeys := range keysByRegion {
+		regionFound, err := r.batchGetRomances(ctx, regionKeys, true, region)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(found, regionFound)
+	}
+
+	var err error
+
+	romances := make([]entity.Romance, len(voteIds))
+	for i, voteId := range voteIds {
+		romanceItem, ok := found[keys[i]]
+		if !ok {
+			romances[i] = entity.CreateEmptyRomance(voteId)
+			continue
+		}
+
+		romances[i], err = r.transformRomanceItemToEntity(voteId.CountryId(), voteId.ActiveUserId(), romanceItem)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r.logger.Debug(fmt.Sprintf("Got %d of %d romances from dynamodb", len(found), len(voteIds)))
+
+	return romances, nil
+}
+
+func (r *RomancesRepository) getQueriedRomanceItems(
+	ctx context.Context,
+	stage uint8,
+	items []map[string]types.AttributeValue,
+	region string,
+) ([]RomanceDocumentSchema, error) {
+	romanceItems := make([]RomanceDocumentSchema, 0, len(items))
+
+	if stage == romancesPkSideStage {
+		for _, item := range items {
+			romanceItem := RomanceDocumentSchema{}
+			if err := attributevalue.UnmarshalMap(item, &romanceItem); err != nil {
+				return nil, err
+			}
+			romanceItems = append(romanceItems, romanceItem)
+		}
+		return romanceItems, nil
+	}
+
+	// the index projects keys only, so the documents are fetched from the base table
+	keys := make([]RomancePrimaryKey, len(items))
+	for i, item := range items {
+		key, err := romancePrimaryKeyFromItem(item)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+	}
+
+	found, err := r.batchGetRomances(ctx, keys, false, region)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		// the romance may have been deleted between the index query and the batch read
+		if romanceItem, ok := found[key]; ok {
+			romanceItems = append(romanceItems, romanceItem)
+		}
+	}
+
+	return romanceItems, nil
+}
+
+func (r *RomancesRepository) batchGetRomances(
+	ctx context.Context,
+	keys []RomancePrimaryKey,
+	consistentRead bool,
+	region string,
+) (map[RomancePrimaryKey]RomanceDocumentSchema, error) {
+	// BatchGetItem rejects requests with duplicated keys
+	seen := make(map[RomancePrimaryKey]struct{}, len(keys))
+	uniqueKeys := make([]RomancePrimaryKey, 0, len(keys))
+	for _, key := range keys {
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			uniqueKeys = append(uniqueKeys, key)
+		}
+	}
+
+	found := make(map[RomancePrimaryKey]RomanceDocumentSchema, len(uniqueKeys))
+
+	for start := 0; start < len(uniqueKeys); start += platformDynamoDb.BatchGetItemsLimit {
+		end := min(start+platformDynamoDb.BatchGetItemsLimit, len(uniqueKeys))
+
+		requestKeys := make([]map[string]types.AttributeValue, 0, end-start)
+		for _, key := range uniqueKeys[start:end] {
+			requestKeys = append(requestKeys, r.getRomancesTableKey(key))
+		}
+
+		pending := map[string]types.KeysAndAttributes{
+			RomancesTableName: {Keys: requestKeys, ConsistentRead: aws.Bool(consistentRead)},
+		}
+
+		for tries := 0; ; tries++ {
+			out, err := r.dynamoDbClient.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
+				RequestItems: pending,
+			}, func(o *dynamodb.Options) {
+				o.Region = region
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			for _, item := range out.Responses[RomancesTableName] {
+				key, err := romancePrimaryKeyFromItem(item)
+				if err != nil {
+					return nil, err
+				}
+
+				romanceItem := RomanceDocumentSchema{}
+				if err = attributevalue.UnmarshalMap(item, &romanceItem); err != nil {
+					return nil, err
+				}
+				found[key] = romanceItem
+			}
+
+			if len(out.UnprocessedKeys) == 0 {
+				break
+			}
+
+			if tries >= config.DynamoDbUnprocessedItemsRetriesCount {
+				return nil, fmt.Errorf(
+					"%d romance keys left unprocessed after %d retries",
+					len(out.UnprocessedKeys[RomancesTableName].Keys),
+					tries,
+				)
+			}
+
+			pending = out.UnprocessedKeys
+			if err = timeutil.Sleep(ctx, platformDynamoDb.UnprocessedItemsBackoff(tries)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return found, nil
+}
+
+func (r *RomancesRepository) batchWriteRomances(
+	ctx context.Context,
+	requests []types.WriteRequest,
+	region string,
+) error {
+	for start := 0; start < len(requests); start += platformDynamoDb.BatchWriteItemsLimit {
+		end := min(start+platformDynamoDb.BatchWriteItemsLimit, len(requests))
+		pending := map[string][]types.WriteRequest{
+			RomancesTableName: requests[start:end],
+		}
+
+		for tries := 0; ; tries++ {
+			out, err := r.dynamoDbClient.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+				RequestItems: pending,
+			}, func(o *dynamodb.Options) {
+				o.Region = region
+			})
+			if err != nil {
+				return err
+			}
+
+			if len(out.UnprocessedItems) == 0 {
+				break
+			}
+
+			if tries >= config.DynamoDbUnprocessedItemsRetriesCount {
+				return fmt.Errorf(
+					"%d romances left unprocessed after %d retries",
+					len(out.UnprocessedItems[RomancesTableName]),
+					tries,
+				)
+			}
+
+			pending = out.UnprocessedItems
+			if err = timeutil.Sleep(ctx, platformDynamoDb.UnprocessedItemsBackoff(tries)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *RomancesRepository) DeleteActiveUserVoteFromRomance(ctx context.Context, romance entity.Romance) error {
+	if romance.IsEmpty() {
+		return nil
+	}
+
+	update := r.getDeleteActiveUserVoteUpdate(romance)
+
+	out, err := r.dynamoDbClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		Key:                       update.Key,
+		TableName:                 update.TableName,
+		UpdateExpression:          update.UpdateExpression,
+		ExpressionAttributeNames:  update.ExpressionAttributeNames,
+		ExpressionAttributeValues: update.ExpressionAttributeValues,
+		ConditionExpression:       update.ConditionExpression,
+		ReturnValues:              types.ReturnValueAllNew,
+	}, func(o *dynamodb.Options) {
+		o.Region = r.romanceRegion(romance.ActiveUserVote.Id)
 	})
 
 	if err != nil {
-		return entity.Romance{}, err
-	}
-
-	if out == nil || len(out.Item) == 0 {
-		return entity.CreateEmptyRomance(voteId), nil
-	}
+		var condCheckErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condCheckErr) {
+			return romanceDomain.ErrVersionConflict
+		}
 
-	romanceItem := &RomanceDocumentSchema{}
-	if err = attributevalue.UnmarshalMap(out.Item, romanceItem); err != nil {
-		return entity.Romance{}, err
+		return err
 	}
 
-	r.logger.Debug(fmt.Sprintf("Got romance from dynamodb: %+v", romanceItem))
-
-	return r.transformRomanceItemToEntity(voteId.CountryId(), voteId.ActiveUserId(), *romanceItem)
+	r.logger.Debug(fmt.Sprintf("Deleted romance vote from dynamodb: %+v", out))
+	return nil
 }
 
-func (r *RomancesRepository) AddActiveUserVoteToRomance(
+// DeleteActiveUserVoteFromRomanceWithCounters deletes the vote and takes it out of the counters in one transaction.
+func (r *RomancesRepository) DeleteActiveUserVoteFromRomanceWithCounters(
 	ctx context.Context,
 	romance entity.Romance,
-	voteType valueobject.VoteType,
-	votedAt time.Time,
-) (entity.Romance, error) {
+	countersChangeId uuid.UUID,
+	countersChanges []countersValueObject.VoteCountersChange,
+) error {
+	if romance.IsEmpty() {
+		return nil
+	}
+
+	update := r.getDeleteActiveUserVoteUpdate(romance)
 
+	err := r.writeRomanceTransaction(ctx, romance.ActiveUserVote.Id, update, countersChangeId, countersChanges, nil)
+	if err != nil {
+		return err
+	}
+
+	r.logger.Debug(fmt.Sprintf("Deleted romance vote and updated counters in dynamodb: %+v", romance.ActiveUserVote.Id))
+	return nil
+}
+
+func (r *RomancesRepository) getDeleteActiveUserVoteUpdate(romance entity.Romance) *types.Update {
 	activeUserId := romance.ActiveUserVote.Id.ActiveUserId()
-	countryId := romance.ActiveUserVote.Id.CountryId()
 
 	romanceKey := NewRomancePrimaryKey(romance.ActiveUserVote.Id)
-	now := time.Now()
-
 	exprNames := map[string]string{
 		"#version": versionAttrName,
 		"#ttl":     platformDynamoDb.TtlAttrName,
@@ -125,23 +1423,85 @@ func (r *RomancesRepository) AddActiveUserVoteToRomance(
 
 	if romanceKey.isPartitionKey(activeUserId) {
 		exprNames["#voteType"] = pkUserVoteTypeAttrName
+		exprNames["#message"] = pkUserMessageAttrName
 		exprNames["#votedAt"] = pkUserVotedAtAttrName
 		exprNames["#voteCreatedAt"] = pkUserVoteCreatedAtAttrName
+		exprNames["#voteUpdatedAt"] = pkUserVoteUpdatedAtAttrName
 	} else {
 		exprNames["#voteType"] = skUserVoteTypeAttrName
+		exprNames["#message"] = skUserMessageAttrName
 		exprNames["#votedAt"] = skUserVotedAtAttrName
 		exprNames["#voteCreatedAt"] = skUserVoteCreatedAtAttrName
+		exprNames["#voteUpdatedAt"] = skUserVoteUpdatedAtAttrName
 	}
 
 	currentVersion := int64(romance.Version)
-	ttlSeconds := r.getTtlSecondsForVotesPair(voteType, romance.PeerUserVote.VoteType)
+	ttlSeconds := r.getTtlSecondsForVotesPair(valueobject.VoteTypeEmpty, romance.PeerUserVote.VoteType)
 
 	exprValues := map[string]types.AttributeValue{
-		":voteType":  &types.AttributeValueMemberN{Value: strconv.Itoa(int(voteType))},
-		":votedAt":   &types.AttributeValueMemberN{Value: strconv.FormatInt(votedAt.Unix(), 10)},
-		":createdAt": &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Unix(), 10)},
-		":v":         &types.AttributeValueMemberN{Value: strconv.FormatInt(currentVersion+1, 10)},
-		":ttl":       &types.AttributeValueMemberN{Value: strconv.FormatInt(ttlSeconds, 10)},
+		":v":   &types.AttributeValueMemberN{Value: strconv.FormatInt(currentVersion+1, 10)},
+		":ttl": &types.AttributeValueMemberN{Value: strconv.FormatInt(ttlSeconds, 10)},
+	}
+
+	conditionExpression := "#version = :expectedV"
+	exprValues[":expectedV"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(currentVersion, 10)}
+
+	updatedRomance := romance
+	updatedRomance.ActiveUserVote = entity.Vote{Id: romance.ActiveUserVote.Id}
+
+	setActions, removeNames := r.getIndexedAttrsUpdateActions(romanceKey, updatedRomance, exprNames, exprValues)
+	setActions = getCountryIdsUpdateActions(romanceKey, romance.ActiveUserVote.Id, setActions, exprNames, exprValues)
+	updateExpr := buildUpdateExpression(
+		append([]string{"#version = :v", "#ttl = :ttl"}, setActions...),
+		append([]string{"#voteType", "#message", "#votedAt", "#voteCreatedAt", "#voteUpdatedAt"}, removeNames...),
+	)
+
+	return &types.Update{
+		Key:                       r.getRomancesTableKey(romanceKey),
+		TableName:                 aws.String(RomancesTableName),
+		UpdateExpression:          updateExpr,
+		ExpressionAttributeNames:  exprNames,
+		ExpressionAttributeValues: exprValues,
+		ConditionExpression:       aws.String(conditionExpression),
+	}
+}
+
+// BlockRomance marks the romance as blocked by its active user.
+func (r *RomancesRepository) BlockRomance(ctx context.Context, romance entity.Romance) (entity.Romance, error) {
+	updatedRomance := romance
+	updatedRomance.BlockedBy = romance.ActiveUserVote.Id.ActiveUserId()
+
+	return r.updateRomanceBlock(ctx, romance, updatedRomance, r.config.Romances.BlockedRomanceTtlSeconds)
+}
+
+func (r *RomancesRepository) UnblockRomance(ctx context.Context, romance entity.Romance) (entity.Romance, error) {
+	updatedRomance := romance
+	updatedRomance.BlockedBy = uuid.Nil
+
+	ttlSeconds := r.getTtlSecondsForVotesPair(romance.ActiveUserVote.VoteType, romance.PeerUserVote.VoteType)
+	return r.updateRomanceBlock(ctx, romance, updatedRomance, ttlSeconds)
+}
+
+func (r *RomancesRepository) updateRomanceBlock(
+	ctx context.Context,
+	romance entity.Romance,
+	updatedRomance entity.Romance,
+	ttlSeconds int64,
+) (entity.Romance, error) {
+	activeUserId := romance.ActiveUserVote.Id.ActiveUserId()
+	countryId := romance.ActiveUserVote.Id.CountryId()
+	romanceKey := NewRomancePrimaryKey(romance.ActiveUserVote.Id)
+
+	exprNames := map[string]string{
+		"#version":   versionAttrName,
+		"#ttl":       platformDynamoDb.TtlAttrName,
+		"#blockedBy": blockedByAttrName,
+	}
+
+	currentVersion := int64(romance.Version)
+	exprValues := map[string]types.AttributeValue{
+		":v":   &types.AttributeValueMemberN{Value: strconv.FormatInt(currentVersion+1, 10)},
+		":ttl": &types.AttributeValueMemberN{Value: strconv.FormatInt(ttlSeconds, 10)},
 	}
 
 	var conditionExpression string
@@ -152,18 +1512,27 @@ func (r *RomancesRepository) AddActiveUserVoteToRomance(
 		exprValues[":expectedV"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(currentVersion, 10)}
 	}
 
-	updateExpr := aws.String("SET #voteType = :voteType, #votedAt = :votedAt, #voteCreatedAt = :createdAt, #version = :v, #ttl = :ttl")
+	updatedRomance.Version = romance.Version + 1
+
+	setActions, removeNames := r.getIndexedAttrsUpdateActions(romanceKey, updatedRomance, exprNames, exprValues)
+	setActions = getCountryIdsUpdateActions(romanceKey, romance.ActiveUserVote.Id, setActions, exprNames, exprValues)
+	if updatedRomance.IsBlocked() {
+		exprValues[":blockedBy"] = &types.AttributeValueMemberS{Value: updatedRomance.BlockedBy.String()}
+		setActions = append(setActions, "#blockedBy = :blockedBy")
+	} else {
+		removeNames = append(removeNames, "#blockedBy")
+	}
 
 	out, err := r.dynamoDbClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
 		Key:                       r.getRomancesTableKey(romanceKey),
 		TableName:                 aws.String(RomancesTableName),
-		UpdateExpression:          updateExpr,
+		UpdateExpression:          buildUpdateExpression(append([]string{"#version = :v", "#ttl = :ttl"}, setActions...), removeNames),
 		ExpressionAttributeNames:  exprNames,
 		ExpressionAttributeValues: exprValues,
 		ConditionExpression:       aws.String(conditionExpression),
 		ReturnValues:              types.ReturnValueAllNew,
 	}, func(o *dynamodb.Options) {
-		o.Region = platformDynamoDb.GetDynamodbRegionByCountry(countryId)
+		o.Region = r.romanceRegion(romance.ActiveUserVote.Id)
 	})
 
 	if err != nil {
@@ -180,113 +1549,106 @@ func (r *RomancesRepository) AddActiveUserVoteToRomance(
 		return entity.Romance{}, err
 	}
 
-	r.logger.Debug(fmt.Sprintf("Updated romance in dynamodb: %+v", romanceItem))
+	r.logger.Debug(fmt.Sprintf("Updated romance block in dynamodb: %+v", romanceItem))
 
 	return r.transformRomanceItemToEntity(countryId, activeUserId, *romanceItem)
 }
 
-func (r *RomancesRepository) DeleteRomance(
+func (r *RomancesRepository) ChangeActiveUserVoteTypeInRomance(
 	ctx context.Context,
-	voteId sharedValueObject.VoteId,
-) error {
-	romanceKey := NewRomancePrimaryKey(voteId)
-	out, err := r.dynamoDbClient.DeleteItem(ctx, &dynamodb.DeleteItemInput{
-		Key:       r.getRomancesTableKey(romanceKey),
-		TableName: aws.String(RomancesTableName),
-	}, func(o *dynamodb.Options) {
-		o.Region = platformDynamoDb.GetDynamodbRegionByCountry(voteId.CountryId())
-	})
-
-	if err != nil {
-		return err
-	}
-
-	r.logger.Debug(fmt.Sprintf("Romance deleted from dynamodb: %+v", out))
-	return nil
-}
-
-func (r *RomancesRepository) DeleteActiveUserVoteFromRomance(ctx context.Context, romance entity.Romance) error {
-	if romance.IsEmpty() {
-		return nil
+	romance entity.Romance,
+	newVoteType valueobject.VoteType,
+	message valueobject.ComplimentMessage,
+) (entity.Romance, error) {
+	if err := validateVoteTypeChange(romance, newVoteType); err != nil {
+		return entity.Romance{}, err
 	}
 
 	activeUserId := romance.ActiveUserVote.Id.ActiveUserId()
 	countryId := romance.ActiveUserVote.Id.CountryId()
 
-	romanceKey := NewRomancePrimaryKey(romance.ActiveUserVote.Id)
-	exprNames := map[string]string{
-		"#version": versionAttrName,
-		"#ttl":     platformDynamoDb.TtlAttrName,
-	}
-
-	if romanceKey.isPartitionKey(activeUserId) {
-		exprNames["#voteType"] = pkUserVoteTypeAttrName
-		exprNames["#votedAt"] = pkUserVotedAtAttrName
-		exprNames["#voteCreatedAt"] = pkUserVoteCreatedAtAttrName
-		exprNames["#voteUpdatedAt"] = pkUserVoteUpdatedAtAttrName
-	} else {
-		exprNames["#voteType"] = skUserVoteTypeAttrName
-		exprNames["#votedAt"] = skUserVotedAtAttrName
-		exprNames["#voteCreatedAt"] = skUserVoteCreatedAtAttrName
-		exprNames["#voteUpdatedAt"] = skUserVoteUpdatedAtAttrName
-	}
-
-	currentVersion := int64(romance.Version)
-	ttlSeconds := r.getTtlSecondsForVotesPair(valueobject.VoteTypeEmpty, romance.PeerUserVote.VoteType)
-
-	exprValues := map[string]types.AttributeValue{
-		":v":   &types.AttributeValueMemberN{Value: strconv.FormatInt(currentVersion+1, 10)},
-		":ttl": &types.AttributeValueMemberN{Value: strconv.FormatInt(ttlSeconds, 10)},
-	}
-
-	conditionExpression := "#version = :expectedV"
-	exprValues[":expectedV"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(currentVersion, 10)}
-
-	updateExpr := aws.String("SET #version = :v, #ttl = :ttl REMOVE #voteType, #votedAt, #voteCreatedAt, #voteUpdatedAt")
+	update, _ := r.getChangeActiveUserVoteTypeUpdate(romance, newVoteType, message, r.clock.Now())
 
 	out, err := r.dynamoDbClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
-		Key:                       r.getRomancesTableKey(romanceKey),
-		TableName:                 aws.String(RomancesTableName),
-		UpdateExpression:          updateExpr,
-		ExpressionAttributeNames:  exprNames,
-		ExpressionAttributeValues: exprValues,
-		ConditionExpression:       aws.String(conditionExpression),
+		Key:                       update.Key,
+		TableName:                 update.TableName,
+		UpdateExpression:          update.UpdateExpression,
+		ExpressionAttributeNames:  update.ExpressionAttributeNames,
+		ExpressionAttributeValues: update.ExpressionAttributeValues,
+		ConditionExpression:       update.ConditionExpression,
 		ReturnValues:              types.ReturnValueAllNew,
 	}, func(o *dynamodb.Options) {
-		o.Region = platformDynamoDb.GetDynamodbRegionByCountry(countryId)
+		o.Region = r.romanceRegion(romance.ActiveUserVote.Id)
 	})
 
 	if err != nil {
 		var condCheckErr *types.ConditionalCheckFailedException
 		if errors.As(err, &condCheckErr) {
-			return romanceDomain.ErrVersionConflict
+			return entity.Romance{}, romanceDomain.ErrVersionConflict
 		}
 
-		return err
+		return entity.Romance{}, err
 	}
 
-	r.logger.Debug(fmt.Sprintf("Deleted romance vote from dynamodb: %+v", out))
-	return nil
+	romanceItem := &RomanceDocumentSchema{}
+	if err = attributevalue.UnmarshalMap(out.Attributes, romanceItem); err != nil {
+		return entity.Romance{}, err
+	}
+
+	r.logger.Debug(fmt.Sprintf("Updated romance in dynamodb: %+v", romanceItem))
+
+	return r.transformRomanceItemToEntity(countryId, activeUserId, *romanceItem)
 }
 
-func (r *RomancesRepository) ChangeActiveUserVoteTypeInRomance(
+// ChangeActiveUserVoteTypeInRomanceWithCounters returns ErrCountersNotApplied when the counters of another region fail.
+func (r *RomancesRepository) ChangeActiveUserVoteTypeInRomanceWithCounters(
 	ctx context.Context,
 	romance entity.Romance,
 	newVoteType valueobject.VoteType,
+	message valueobject.ComplimentMessage,
+	countersChangeId uuid.UUID,
+	countersChanges []countersValueObject.VoteCountersChange,
+	quotaConsumption *quotaValueObject.QuotaConsumption,
 ) (entity.Romance, error) {
+	if err := validateVoteTypeChange(romance, newVoteType); err != nil {
+		return entity.Romance{}, err
+	}
+
+	update, updatedRomance := r.getChangeActiveUserVoteTypeUpdate(romance, newVoteType, message, r.clock.Now())
+
+	err := r.writeRomanceTransaction(ctx, romance.ActiveUserVote.Id, update, countersChangeId, countersChanges, quotaConsumption)
+	if errors.Is(err, counterDomain.ErrCountersNotApplied) {
+		return updatedRomance, err
+	}
+	if err != nil {
+		return entity.Romance{}, err
+	}
+
+	r.logger.Debug(fmt.Sprintf("Updated romance, counters and quota in dynamodb: %+v", updatedRomance))
+
+	return updatedRomance, nil
+}
+
+func validateVoteTypeChange(romance entity.Romance, newVoteType valueobject.VoteType) error {
 	if romance.ActiveUserVote.VoteType.IsEmpty() {
-		return entity.Romance{}, romanceDomain.ErrVoteNotFound
+		return romanceDomain.ErrVoteNotFound
 	}
 
 	if newVoteType.IsEmpty() {
-		return entity.Romance{}, romanceDomain.ErrWrongVote
+		return romanceDomain.ErrWrongVote
 	}
 
-	activeUserId := romance.ActiveUserVote.Id.ActiveUserId()
-	countryId := romance.ActiveUserVote.Id.CountryId()
+	return nil
+}
 
+func (r *RomancesRepository) getChangeActiveUserVoteTypeUpdate(
+	romance entity.Romance,
+	newVoteType valueobject.VoteType,
+	message valueobject.ComplimentMessage,
+	now time.Time,
+) (*types.Update, entity.Romance) {
+	activeUserId := romance.ActiveUserVote.Id.ActiveUserId()
 	romanceKey := NewRomancePrimaryKey(romance.ActiveUserVote.Id)
-	now := time.Now()
 
 	exprNames := map[string]string{
 		"#version": versionAttrName,
@@ -295,9 +1657,11 @@ func (r *RomancesRepository) ChangeActiveUserVoteTypeInRomance(
 
 	if romanceKey.isPartitionKey(activeUserId) {
 		exprNames["#voteType"] = pkUserVoteTypeAttrName
+		exprNames["#message"] = pkUserMessageAttrName
 		exprNames["#voteUpdatedAt"] = pkUserVoteUpdatedAtAttrName
 	} else {
 		exprNames["#voteType"] = skUserVoteTypeAttrName
+		exprNames["#message"] = skUserMessageAttrName
 		exprNames["#voteUpdatedAt"] = skUserVoteUpdatedAtAttrName
 	}
 
@@ -314,37 +1678,31 @@ func (r *RomancesRepository) ChangeActiveUserVoteTypeInRomance(
 	conditionExpression := "#version = :expectedV"
 	exprValues[":expectedV"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(currentVersion, 10)}
 
-	updateExpr := aws.String("SET #voteType = :voteType, #voteUpdatedAt = :updatedAt, #version = :v, #ttl = :ttl")
+	// the stored times have a second precision
+	updatedAtUnix := int32(now.Unix())
 
-	out, err := r.dynamoDbClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+	updatedRomance := romance
+	updatedRomance.ActiveUserVote.VoteType = newVoteType
+	updatedRomance.ActiveUserVote.Message = message
+	updatedRomance.ActiveUserVote.UpdatedAt = timeutil.UnixToTimePtr(&updatedAtUnix)
+	updatedRomance.Version = romance.Version + 1
+
+	setActions, removeNames := r.getIndexedAttrsUpdateActions(romanceKey, updatedRomance, exprNames, exprValues)
+	setActions, removeNames = getMessageUpdateActions(message, setActions, removeNames, exprValues)
+	setActions = getCountryIdsUpdateActions(romanceKey, romance.ActiveUserVote.Id, setActions, exprNames, exprValues)
+	updateExpr := buildUpdateExpression(
+		append([]string{"#voteType = :voteType", "#voteUpdatedAt = :updatedAt", "#version = :v", "#ttl = :ttl"}, setActions...),
+		removeNames,
+	)
+
+	return &types.Update{
 		Key:                       r.getRomancesTableKey(romanceKey),
 		TableName:                 aws.String(RomancesTableName),
 		UpdateExpression:          updateExpr,
 		ExpressionAttributeNames:  exprNames,
 		ExpressionAttributeValues: exprValues,
 		ConditionExpression:       aws.String(conditionExpression),
-		ReturnValues:              types.ReturnValueAllNew,
-	}, func(o *dynamodb.Options) {
-		o.Region = platformDynamoDb.GetDynamodbRegionByCountry(countryId)
-	})
-
-	if err != nil {
-		var condCheckErr *types.ConditionalCheckFailedException
-		if errors.As(err, &condCheckErr) {
-			return entity.Romance{}, romanceDomain.ErrVersionConflict
-		}
-
-		return entity.Romance{}, err
-	}
-
-	romanceItem := &RomanceDocumentSchema{}
-	if err = attributevalue.UnmarshalMap(out.Attributes, romanceItem); err != nil {
-		return entity.Romance{}, err
-	}
-
-	r.logger.Debug(fmt.Sprintf("Updated romance in dynamodb: %+v", romanceItem))
-
-	return r.transformRomanceItemToEntity(countryId, activeUserId, *romanceItem)
+	}, updatedRomance
 }
 
 func (r *RomancesRepository) transformRomanceItemToEntity(
@@ -365,6 +1723,7 @@ func (r *RomancesRepository) transformRomanceItemToEntity(
 
 	pkUserVote := entity.Vote{
 		VoteType:  valueobject.VoteType(romanceItem.PkUserVoteType),
+		Message:   valueobject.ComplimentMessage(romanceItem.PkUserMessage),
 		VotedAt:   timeutil.UnixToTimePtr(romanceItem.PkUserVotedAt),
 		CreatedAt: timeutil.UnixToTimePtr(romanceItem.PkUserVoteCreatedAt),
 		UpdatedAt: timeutil.UnixToTimePtr(romanceItem.PkUserVoteUpdatedAt),
@@ -372,11 +1731,22 @@ func (r *RomancesRepository) transformRomanceItemToEntity(
 
 	skUserVote := entity.Vote{
 		VoteType:  valueobject.VoteType(romanceItem.SkUserVoteType),
+		Message:   valueobject.ComplimentMessage(romanceItem.SkUserMessage),
 		VotedAt:   timeutil.UnixToTimePtr(romanceItem.SkUserVotedAt),
 		CreatedAt: timeutil.UnixToTimePtr(romanceItem.SkUserVoteCreatedAt),
 		UpdatedAt: timeutil.UnixToTimePtr(romanceItem.SkUserVoteUpdatedAt),
 	}
 
+	// the romances written before the countries were kept on every romance are the ones within a country
+	activeUserCountryId, peerCountryId := countryId, countryId
+	if romanceItem.PkUserCountryId != 0 && romanceItem.SkUserCountryId != 0 {
+		if activeUserId == pkUserId {
+			activeUserCountryId, peerCountryId = romanceItem.PkUserCountryId, romanceItem.SkUserCountryId
+		} else {
+			activeUserCountryId, peerCountryId = romanceItem.SkUserCountryId, romanceItem.PkUserCountryId
+		}
+	}
+
 	var peerUserId uuid.UUID
 	if activeUserId == pkUserId {
 		peerUserId = skUserId
@@ -384,7 +1754,7 @@ func (r *RomancesRepository) transformRomanceItemToEntity(
 		peerUserId = pkUserId
 	}
 
-	activeUserVoteId, err := sharedValueObject.NewVoteId(countryId, activeUserId, peerUserId)
+	activeUserVoteId, err := sharedValueObject.NewVoteId(activeUserCountryId, activeUserId, peerCountryId, peerUserId)
 	if err != nil {
 		return entity.Romance{}, err
 	}
@@ -405,13 +1775,111 @@ func (r *RomancesRepository) transformRomanceItemToEntity(
 		resultPeerUserVote.Id = peerUserVoteId
 	}
 
+	blockedBy := uuid.Nil
+	if romanceItem.BlockedBy != "" {
+		blockedBy, err = uuid.Parse(romanceItem.BlockedBy)
+		if err != nil {
+			return entity.Romance{}, err
+		}
+	}
+
 	return entity.Romance{
 		ActiveUserVote: resultActiveUserVote,
 		PeerUserVote:   resultPeerUserVote,
+		BlockedBy:      blockedBy,
 		Version:        romanceItem.Version,
 	}, nil
 }
 
+func (r *RomancesRepository) getIndexedAttrsUpdateActions(
+	romanceKey RomancePrimaryKey,
+	updatedRomance entity.Romance,
+	exprNames map[string]string,
+	exprValues map[string]types.AttributeValue,
+) ([]string, []string) {
+	activeUserLikedAtAttrName, peerUserLikedAtAttrName := PkUserLikedAtAttrName, SkUserLikedAtAttrName
+	if !romanceKey.isPartitionKey(updatedRomance.ActiveUserVote.Id.ActiveUserId()) {
+		activeUserLikedAtAttrName, peerUserLikedAtAttrName = SkUserLikedAtAttrName, PkUserLikedAtAttrName
+	}
+	peerRomance := updatedRomance.ToPeerRomance()
+
+	indexedAttrs := []struct {
+		placeholder string
+		attrName    string
+		value       *time.Time
+	}{
+		{placeholder: "matchedAt", attrName: MatchedAtAttrName, value: updatedRomance.MatchedAt()},
+		{placeholder: "activeUserLikedAt", attrName: activeUserLikedAtAttrName, value: getIncomingLikeTime(updatedRomance)},
+		{placeholder: "peerUserLikedAt", attrName: peerUserLikedAtAttrName, value: getIncomingLikeTime(peerRomance)},
+	}
+
+	var setActions, removeNames []string
+	for _, attr := range indexedAttrs {
+		exprNames["#"+attr.placeholder] = attr.attrName
+		// a blocked romance is left out of the matches and likes
+		if attr.value == nil || updatedRomance.IsBlocked() {
+			removeNames = append(removeNames, "#"+attr.placeholder)
+			continue
+		}
+
+		exprValues[":"+attr.placeholder] = &types.AttributeValueMemberN{Value: strconv.FormatInt(attr.value.Unix(), 10)}
+		setActions = append(setActions, "#"+attr.placeholder+" = :"+attr.placeholder)
+	}
+
+	return setActions, removeNames
+}
+
+func getMessageUpdateActions(
+	message valueobject.ComplimentMessage,
+	setActions []string,
+	removeNames []string,
+	exprValues map[string]types.AttributeValue,
+) ([]string, []string) {
+	if message.IsEmpty() {
+		return setActions, append(removeNames, "#message")
+	}
+
+	exprValues[":message"] = &types.AttributeValueMemberS{Value: string(message)}
+	return append(setActions, "#message = :message"), removeNames
+}
+
+func getCountryIdsUpdateActions(
+	romanceKey RomancePrimaryKey,
+	voteId sharedValueObject.VoteId,
+	setActions []string,
+	exprNames map[string]string,
+	exprValues map[string]types.AttributeValue,
+) []string {
+	pkUserCountryId, skUserCountryId := voteId.CountryId(), voteId.PeerCountryId()
+	if !romanceKey.isPartitionKey(voteId.ActiveUserId()) {
+		pkUserCountryId, skUserCountryId = skUserCountryId, pkUserCountryId
+	}
+
+	exprNames["#pkUserCountryId"] = pkUserCountryIdAttrName
+	exprNames["#skUserCountryId"] = skUserCountryIdAttrName
+	exprValues[":pkUserCountryId"] = &types.AttributeValueMemberN{Value: strconv.Itoa(int(pkUserCountryId))}
+	exprValues[":skUserCountryId"] = &types.AttributeValueMemberN{Value: strconv.Itoa(int(skUserCountryId))}
+
+	return append(setActions, "#pkUserCountryId = :pkUserCountryId", "#skUserCountryId = :skUserCountryId")
+}
+
+func getIncomingLikeTime(romance entity.Romance) *time.Time {
+	if !romance.IsIncomingLike() {
+		return nil
+	}
+
+	return romance.PeerUserVote.VotedAt
+}
+
+func buildUpdateExpression(setActions []string, removeNames []string) *string {
+	updateExpr := "SET " + strings.Join(setActions, ", ")
+	if len(removeNames) > 0 {
+		updateExpr += " REMOVE " + strings.Join(removeNames, ", ")
+	}
+
+	return aws.String(updateExpr)
+}
+
 func (r *RomancesRepository) getTtlSecondsForVotesPair(
 	activeUserVoteType valueobject.VoteType,
 	peerUserVoteType valueobject.VoteType,
@@ -450,6 +1918,28 @@ func NewRomancePrimaryKey(voteId sharedValueObject.VoteId) RomancePrimaryKey {
 	}
 }
 
+func romancePrimaryKeyFromItem(item map[string]types.AttributeValue) (RomancePrimaryKey, error) {
+	pk, ok := item[PkUserIdAttrName].(*types.AttributeValueMemberS)
+	if !ok {
+		return RomancePrimaryKey{}, fmt.Errorf("romance item has no %q attribute", PkUserIdAttrName)
+	}
+	sk, ok := item[SkUserIdAttrName].(*types.AttributeValueMemberS)
+	if !ok {
+		return RomancePrimaryKey{}, fmt.Errorf("romance item has no %q attribute", SkUserIdAttrName)
+	}
+
+	pkUserId, err := uuid.Parse(pk.Value)
+	if err != nil {
+		return RomancePrimaryKey{}, err
+	}
+	skUserId, err := uuid.Parse(sk.Value)
+	if err != nil {
+		return RomancePrimaryKey{}, err
+	}
+
+	return RomancePrimaryKey{Pk: pkUserId, Sk: skUserId}, nil
+}
+
 func (r *RomancePrimaryKey) isPartitionKey(someUuid uuid.UUID) bool {
 	return someUuid == r.Pk
 }
@@ -0,0 +1,411 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.bumble.dev/shcherbanich/user-votes-storage/config"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/entity"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
+	platformDynamoDb "github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform/dynamodb"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/timeutil"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	streamsTypes "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+	"github.com/google/uuid"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// the checkpoints of the stream shards are kept in the counters table next to the counters they led to
+	streamCheckpointUserIdPrefix     = "stream#"
+	streamCheckpointSequenceAttrName = "sq"
+	// the stream keeps the records for 24 hours.
+	streamCheckpointTtlSeconds = 2 * timeutil.DaySeconds
+
+	streamRecordsLimit          = 100
+	streamShardsRefreshInterval = 10 * time.Second
+)
+
+// RomancesStreamReader reads the changes of the romances from the stream of the Romances table of a single region.
+type RomancesStreamReader struct {
+	dynamoDbClient     platformDynamoDb.Client
+	streamsClient      platformDynamoDb.StreamsClient
+	romancesRepository *RomancesRepository
+	config             config.Config
+	clock              platform.Clock
+	logger             platform.Logger
+}
+
+type romanceChangeHandler func(ctx context.Context, romanceChange entity.RomanceChange) error
+
+func NewRomancesStreamReader(
+	dynamoDbClient platformDynamoDb.Client,
+	streamsClient platformDynamoDb.StreamsClient,
+	romancesRepository *RomancesRepository,
+	config config.Config,
+	clock platform.Clock,
+	logger platform.Logger,
+) *RomancesStreamReader {
+	return &RomancesStreamReader{
+		dynamoDbClient:     dynamoDbClient,
+		streamsClient:      streamsClient,
+		romancesRepository: romancesRepository,
+		config:             config,
+		clock:              clock,
+		logger:             logger,
+	}
+}
+
+// Read hands the changes of the romances to handle until ctx is done.
+func (r *RomancesStreamReader) Read(ctx context.Context, handle func(ctx context.Context, romanceChange entity.RomanceChange) error) error {
+	streamArn, err := r.getStreamArn(ctx)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	var mu sync.Mutex
+	started := map[string]struct{}{}
+	finished := map[string]struct{}{}
+
+	for {
+		shards, err := r.describeShards(ctx, streamArn)
+		if err != nil {
+			r.logger.Error(fmt.Sprintf("DescribeStream error: %+v", err))
+		}
+
+		listed := make(map[string]struct{}, len(shards))
+		for _, shard := range shards {
+			listed[aws.ToString(shard.ShardId)] = struct{}{}
+		}
+
+		mu.Lock()
+		for _, shard := range shards {
+			shardId := aws.ToString(shard.ShardId)
+			if _, ok := started[shardId]; ok {
+				continue
+			}
+
+			parentShardId := aws.ToString(shard.ParentShardId)
+			if _, ok := listed[parentShardId]; ok {
+				if _, ok = finished[parentShardId]; !ok {
+					continue
+				}
+			}
+
+			started[shardId] = struct{}{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if r.readShard(ctx, streamArn, shardId, handle) {
+					mu.Lock()
+					finished[shardId] = struct{}{}
+					mu.Unlock()
+				}
+			}()
+		}
+		mu.Unlock()
+
+		if !sleepContext(ctx, streamShardsRefreshInterval) {
+			return ctx.Err()
+		}
+	}
+}
+
+func (r *RomancesStreamReader) region() string {
+	return r.config.Counters.Stream.RegionOrDefault(r.config.DynamoDbRouting.DefaultRegion)
+}
+
+func (r *RomancesStreamReader) getStreamArn(ctx context.Context) (string, error) {
+	out, err := r.dynamoDbClient.DescribeTable(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(RomancesTableName),
+	}, func(o *dynamodb.Options) {
+		o.Region = r.region()
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if out.Table == nil || out.Table.LatestStreamArn == nil {
+		return "", fmt.Errorf("stream of table %s is not enabled in %s", RomancesTableName, r.region())
+	}
+
+	return *out.Table.LatestStreamArn, nil
+}
+
+func (r *RomancesStreamReader) describeShards(ctx context.Context, streamArn string) ([]streamsTypes.Shard, error) {
+	var shards []streamsTypes.Shard
+	var lastShardId *string
+
+	for {
+		out, err := r.streamsClient.DescribeStream(ctx, &dynamodbstreams.DescribeStreamInput{
+			StreamArn:             aws.String(streamArn),
+			ExclusiveStartShardId: lastShardId,
+		})
+		if err != nil {
+			return shards, err
+		}
+
+		shards = append(shards, out.StreamDescription.Shards...)
+
+		lastShardId = out.StreamDescription.LastEvaluatedShardId
+		if lastShardId == nil {
+			return shards, nil
+		}
+	}
+}
+
+func (r *RomancesStreamReader) readShard(
+	ctx context.Context,
+	streamArn string,
+	shardId string,
+	handle romanceChangeHandler,
+) bool {
+	iterator, err := r.getShardIterator(ctx, streamArn, shardId)
+	for err != nil {
+		r.logger.Error(fmt.Sprintf("GetShardIterator error of shard %s: %+v", shardId, err))
+		if !sleepContext(ctx, r.config.Counters.Stream.PollInterval) {
+			return false
+		}
+		iterator, err = r.getShardIterator(ctx, streamArn, shardId)
+	}
+
+	for iterator != nil {
+		out, err := r.streamsClient.GetRecords(ctx, &dynamodbstreams.GetRecordsInput{
+			ShardIterator: iterator,
+			Limit:         aws.Int32(streamRecordsLimit),
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return false
+			}
+			r.logger.Error(fmt.Sprintf("GetRecords error of shard %s: %+v", shardId, err))
+
+			var expiredErr *streamsTypes.ExpiredIteratorException
+			if errors.As(err, &expiredErr) {
+				if renewed, err := r.getShardIterator(ctx, streamArn, shardId); err == nil {
+					iterator = renewed
+				}
+			}
+			if !sleepContext(ctx, r.config.Counters.Stream.PollInterval) {
+				return false
+			}
+			continue
+		}
+
+		for _, record := range out.Records {
+			for err = r.handleRecord(ctx, record, handle); err != nil; err = r.handleRecord(ctx, record, handle) {
+				r.logger.Error(fmt.Sprintf("Stream record %s handling error: %+v", aws.ToString(record.EventID), err))
+				if !sleepContext(ctx, r.config.Counters.Stream.PollInterval) {
+					return false
+				}
+			}
+		}
+
+		if len(out.Records) > 0 {
+			sequenceNumber := aws.ToString(out.Records[len(out.Records)-1].Dynamodb.SequenceNumber)
+			if err = r.saveCheckpoint(ctx, shardId, sequenceNumber); err != nil {
+				r.logger.Error(fmt.Sprintf("Checkpoint error of shard %s: %+v", shardId, err))
+			}
+		}
+
+		iterator = out.NextShardIterator
+		if len(out.Records) == 0 && iterator != nil && !sleepContext(ctx, r.config.Counters.Stream.PollInterval) {
+			return false
+		}
+	}
+
+	r.logger.Debug(fmt.Sprintf("Shard %s is read to the end", shardId))
+	return true
+}
+
+func (r *RomancesStreamReader) getShardIterator(ctx context.Context, streamArn string, shardId string) (*string, error) {
+	in := &dynamodbstreams.GetShardIteratorInput{
+		StreamArn:         aws.String(streamArn),
+		ShardId:           aws.String(shardId),
+		ShardIteratorType: streamsTypes.ShardIteratorTypeTrimHorizon,
+	}
+
+	sequenceNumber, err := r.getCheckpoint(ctx, shardId)
+	if err != nil {
+		return nil, err
+	}
+	if sequenceNumber != "" {
+		in.ShardIteratorType = streamsTypes.ShardIteratorTypeAfterSequenceNumber
+		in.SequenceNumber = aws.String(sequenceNumber)
+	}
+
+	out, err := r.streamsClient.GetShardIterator(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	return out.ShardIterator, nil
+}
+
+func (r *RomancesStreamReader) getCheckpoint(ctx context.Context, shardId string) (string, error) {
+	out, err := r.dynamoDbClient.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(CountersTableName),
+		Key:            getStreamCheckpointKey(shardId),
+		ConsistentRead: aws.Bool(true),
+	}, func(o *dynamodb.Options) {
+		o.Region = r.region()
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sequenceNumber, ok := out.Item[streamCheckpointSequenceAttrName].(*types.AttributeValueMemberS)
+	if !ok {
+		return "", nil
+	}
+
+	return sequenceNumber.Value, nil
+}
+
+func (r *RomancesStreamReader) saveCheckpoint(ctx context.Context, shardId string, sequenceNumber string) error {
+	item := getStreamCheckpointKey(shardId)
+	item[streamCheckpointSequenceAttrName] = &types.AttributeValueMemberS{Value: sequenceNumber}
+	item[platformDynamoDb.TtlAttrName] = &types.AttributeValueMemberN{
+		Value: strconv.FormatInt(r.clock.Now().Unix()+streamCheckpointTtlSeconds, 10),
+	}
+
+	_, err := r.dynamoDbClient.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(CountersTableName),
+		Item:      item,
+	}, func(o *dynamodb.Options) {
+		o.Region = r.region()
+	})
+
+	return err
+}
+
+func (r *RomancesStreamReader) handleRecord(
+	ctx context.Context,
+	record streamsTypes.Record,
+	handle romanceChangeHandler,
+) error {
+	if record.EventName != streamsTypes.OperationTypeInsert && record.EventName != streamsTypes.OperationTypeModify {
+		return nil
+	}
+
+	romanceChange, err := r.toRomanceChange(record)
+	if err != nil {
+		r.logger.Error(fmt.Sprintf("Stream record %s is invalid: %+v", aws.ToString(record.EventID), err))
+		return nil
+	}
+
+	return handle(ctx, romanceChange)
+}
+
+func (r *RomancesStreamReader) toRomanceChange(record streamsTypes.Record) (entity.RomanceChange, error) {
+	if record.Dynamodb == nil || record.Dynamodb.NewImage == nil {
+		return entity.RomanceChange{}, errors.New("stream record has no new image")
+	}
+
+	newRomanceItem := RomanceDocumentSchema{}
+	if err := attributevalue.UnmarshalMap(streamToDynamoDbItem(record.Dynamodb.NewImage), &newRomanceItem); err != nil {
+		return entity.RomanceChange{}, err
+	}
+	pkUserId, err := uuid.Parse(newRomanceItem.PkUserId)
+	if err != nil {
+		return entity.RomanceChange{}, err
+	}
+
+	// only the records written before the countries were kept lack them
+	if newRomanceItem.PkUserCountryId == 0 || newRomanceItem.SkUserCountryId == 0 {
+		return entity.RomanceChange{}, errors.New("stream record has no countries of the users")
+	}
+	countryId := newRomanceItem.PkUserCountryId
+
+	newRomance, err := r.romancesRepository.transformRomanceItemToEntity(countryId, pkUserId, newRomanceItem)
+	if err != nil {
+		return entity.RomanceChange{}, err
+	}
+
+	oldRomance := entity.CreateEmptyRomance(newRomance.ActiveUserVote.Id)
+	if record.Dynamodb.OldImage != nil {
+		oldRomanceItem := RomanceDocumentSchema{}
+		if err = attributevalue.UnmarshalMap(streamToDynamoDbItem(record.Dynamodb.OldImage), &oldRomanceItem); err != nil {
+			return entity.RomanceChange{}, err
+		}
+		oldRomanceItem.PkUserCountryId = newRomanceItem.PkUserCountryId
+		oldRomanceItem.SkUserCountryId = newRomanceItem.SkUserCountryId
+		if oldRomance, err = r.romancesRepository.transformRomanceItemToEntity(countryId, pkUserId, oldRomanceItem); err != nil {
+			return entity.RomanceChange{}, err
+		}
+	}
+
+	return entity.RomanceChange{
+		// the event id is kept by the redelivered records, so the change is applied once
+		Id:  uuid.NewSHA1(uuid.NameSpaceOID, []byte(aws.ToString(record.EventID))),
+		Old: oldRomance,
+		New: newRomance,
+	}, nil
+}
+
+func getStreamCheckpointKey(shardId string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		UserIdAttrName:            &types.AttributeValueMemberS{Value: streamCheckpointUserIdPrefix + shardId},
+		HourUnixTimestampAttrName: &types.AttributeValueMemberN{Value: "0"},
+	}
+}
+
+func streamToDynamoDbItem(image map[string]streamsTypes.AttributeValue) map[string]types.AttributeValue {
+	item := make(map[string]types.AttributeValue, len(image))
+	for name, value := range image {
+		item[name] = streamToDynamoDbAttributeValue(value)
+	}
+	return item
+}
+
+func streamToDynamoDbAttributeValue(value streamsTypes.AttributeValue) types.AttributeValue {
+	switch v := value.(type) {
+	case *streamsTypes.AttributeValueMemberS:
+		return &types.AttributeValueMemberS{Value: v.Value}
+	case *streamsTypes.AttributeValueMemberN:
+		return &types.AttributeValueMemberN{Value: v.Value}
+	case *streamsTypes.AttributeValueMemberB:
+		return &types.AttributeValueMemberB{Value: v.Value}
+	case *streamsTypes.AttributeValueMemberBOOL:
+		return &types.AttributeValueMemberBOOL{Value: v.Value}
+	case *streamsTypes.AttributeValueMemberNULL:
+		return &types.AttributeValueMemberNULL{Value: v.Value}
+	case *streamsTypes.AttributeValueMemberSS:
+		return &types.AttributeValueMemberSS{Value: v.Value}
+	case *streamsTypes.AttributeValueMemberNS:
+		return &types.AttributeValueMemberNS{Value: v.Value}
+	case *streamsTypes.AttributeValueMemberBS:
+		return &types.AttributeValueMemberBS{Value: v.Value}
+	case *streamsTypes.AttributeValueMemberL:
+		list := make([]types.AttributeValue, len(v.Value))
+		for i, element := range v.Value {
+			list[i] = streamToDynamoDbAttributeValue(element)
+		}
+		return &types.AttributeValueMemberL{Value: list}
+	case *streamsTypes.AttributeValueMemberM:
+		return &types.AttributeValueMemberM{Value: streamToDynamoDbItem(v.Value)}
+	default:
+		return &types.AttributeValueMemberNULL{Value: true}
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,21 @@
+package command
+
+import (
+	"github.com/google/uuid"
+)
+
+type ReconcileUserCounters struct {
+	CountryId    uint16    `path:"country_id" doc:"Current active user country ID"`
+	ActiveUserId uuid.UUID `path:"active_user_id" format:"uuid" doc:"Active User Id"`
+	Body         struct {
+		Rewrite bool `json:"rewrite,omitempty" doc:"Rewrite the drifted lifetime counters, they are only reported otherwise"`
+	}
+}
+
+type ReconcileCountryCounters struct {
+	CountryId uint16 `path:"country_id" doc:"Country ID, the users of the romances stored in its region are reconciled"`
+	Body      struct {
+		TotalSegments int32 `json:"total_segments" minimum:"1" maximum:"1000" default:"16" doc:"Number of the romances table scan segments, one message is published per segment"`
+		Rewrite       bool  `json:"rewrite,omitempty" doc:"Rewrite the drifted lifetime counters, they are only reported otherwise"`
+	}
+}
@@ -1,32 +1,75 @@
 package command
 
 import (
+	"fmt"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/interface/api/rest/v1/contract"
+	huma "github.com/danielgtaylor/huma/v2"
 	"github.com/google/uuid"
 	"time"
 )
 
+type VoteAddBody struct {
+	ActiveUserId  uuid.UUID                      `json:"active_user_id" format:"uuid" doc:"Active User Id"`
+	PeerId        uuid.UUID                      `json:"peer_id" format:"uuid" doc:"Peer user ID"`
+	PeerCountryId uint16                         `json:"peer_country_id,omitempty" doc:"Peer user country ID, the active user country ID when omitted"`
+	VoteType      contract.AddUserVoteType       `json:"vote_type"`
+	Message       contract.ComplimentMessageType `json:"message,omitempty"`
+	VotedAt       time.Time                      `json:"voted_at"`
+}
+
 type VoteAdd struct {
+	CountryId uint16 `path:"country_id" doc:"Current active user country ID"`
+	Body      VoteAddBody
+}
+
+type VotesBatchAdd struct {
 	CountryId uint16 `path:"country_id" doc:"Current active user country ID"`
 	Body      struct {
-		ActiveUserId uuid.UUID                `json:"active_user_id" format:"uuid" doc:"Active User Id"`
-		PeerId       uuid.UUID                `json:"peer_id" format:"uuid" doc:"Peer user ID"`
-		VoteType     contract.AddUserVoteType `json:"vote_type"`
-		VotedAt      time.Time                `json:"voted_at"`
+		Votes []VoteAddBody `json:"votes" minItems:"1" maxItems:"100" doc:"Votes to add, each one is added independently"`
+	}
+}
+
+func (in *VotesBatchAdd) Resolve(ctx huma.Context, prefix *huma.PathBuffer) []error {
+	var errs []error
+	for i, vote := range in.Body.Votes {
+		switch {
+		case vote.ActiveUserId == uuid.Nil:
+			errs = append(errs, &huma.ErrorDetail{
+				Location: prefix.With(fmt.Sprintf("body.votes[%d].active_user_id", i)),
+				Message:  "The active user ID must not be empty",
+				Value:    vote.ActiveUserId,
+			})
+		case vote.ActiveUserId == vote.PeerId:
+			errs = append(errs, &huma.ErrorDetail{
+				Location: prefix.With(fmt.Sprintf("body.votes[%d].peer_id", i)),
+				Message:  "The peer ID must differ from the active user ID",
+				Value:    vote.PeerId,
+			})
+		case vote.PeerId == uuid.Nil:
+			errs = append(errs, &huma.ErrorDetail{
+				Location: prefix.With(fmt.Sprintf("body.votes[%d].peer_id", i)),
+				Message:  "The peer ID must not be empty",
+				Value:    vote.PeerId,
+			})
+		}
 	}
+	return errs
 }
 
 type ChangeVoteType struct {
-	CountryId    uint16    `path:"country_id" doc:"Current active user country ID"`
-	ActiveUserId uuid.UUID `path:"active_user_id" format:"uuid" doc:"Active User Id"`
-	PeerId       uuid.UUID `path:"peer_id" format:"uuid" doc:"Peer user ID"`
-	Body         struct {
-		NewType contract.ChangeUserVoteType `json:"new_vote_type"`
+	CountryId     uint16    `path:"country_id" doc:"Current active user country ID"`
+	ActiveUserId  uuid.UUID `path:"active_user_id" format:"uuid" doc:"Active User Id"`
+	PeerId        uuid.UUID `path:"peer_id" format:"uuid" doc:"Peer user ID"`
+	PeerCountryId uint16    `query:"peer_country_id" doc:"Peer user country ID, the active user country ID when omitted"`
+	Body          struct {
+		NewType contract.ChangeUserVoteType    `json:"new_vote_type"`
+		Message contract.ComplimentMessageType `json:"message,omitempty"`
 	}
 }
 
 type DeleteVote struct {
-	CountryId    uint16    `path:"country_id" doc:"Current active user country ID"`
-	ActiveUserId uuid.UUID `path:"active_user_id" format:"uuid" doc:"Active User Id"`
-	PeerId       uuid.UUID `path:"peer_id" format:"uuid" doc:"Peer user ID"`
+	CountryId     uint16    `path:"country_id" doc:"Current active user country ID"`
+	ActiveUserId  uuid.UUID `path:"active_user_id" format:"uuid" doc:"Active User Id"`
+	PeerId        uuid.UUID `path:"peer_id" format:"uuid" doc:"Peer user ID"`
+	PeerCountryId uint16    `query:"peer_country_id" doc:"Peer user country ID, the active user country ID when omitted"`
 }
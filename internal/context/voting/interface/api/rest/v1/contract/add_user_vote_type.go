@@ -8,25 +8,11 @@ import (
 
 type AddUserVoteType romancesValueObject.VoteType
 
-var addUserVoteTypeToString = map[romancesValueObject.VoteType]string{
-	romancesValueObject.VoteTypeYes:        "yes",
-	romancesValueObject.VoteTypeNo:         "no",
-	romancesValueObject.VoteTypeCrush:      "crush",
-	romancesValueObject.VoteTypeCompliment: "compliment",
-}
-
-var addUserVoteTypeFromString = map[string]romancesValueObject.VoteType{
-	"yes":        romancesValueObject.VoteTypeYes,
-	"no":         romancesValueObject.VoteTypeNo,
-	"crush":      romancesValueObject.VoteTypeCrush,
-	"compliment": romancesValueObject.VoteTypeCompliment,
-}
-
 func (v *AddUserVoteType) MarshalText() ([]byte, error) {
-	return []byte(addUserVoteTypeToString[romancesValueObject.VoteType(*v)]), nil
+	return []byte(romancesValueObject.VoteType(*v).String()), nil
 }
 func (v *AddUserVoteType) UnmarshalText(b []byte) error {
-	vv, ok := addUserVoteTypeFromString[string(b)]
+	vv, ok := voteTypeFromText(b)
 	if !ok {
 		return fmt.Errorf("invalid vote type: %q", b)
 	}
@@ -35,13 +21,8 @@ func (v *AddUserVoteType) UnmarshalText(b []byte) error {
 }
 
 func (v *AddUserVoteType) Schema(r huma.Registry) *huma.Schema {
-	enums := make([]any, 0, len(addUserVoteTypeToString))
-	for voteType := range addUserVoteTypeToString {
-		enums = append(enums, addUserVoteTypeToString[voteType])
-	}
-
 	return &huma.Schema{
-		Type: huma.TypeString,
-		Enum: enums,
+		Type:        huma.TypeString,
+		Description: "Vote type, the vote transition policy of the country decides which ones may be added",
 	}
 }
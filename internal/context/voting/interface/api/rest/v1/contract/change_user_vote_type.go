@@ -8,23 +8,11 @@ import (
 
 type ChangeUserVoteType romancesValueObject.VoteType
 
-var changeUserVoteTypeToString = map[romancesValueObject.VoteType]string{
-	romancesValueObject.VoteTypeYes:        "yes",
-	romancesValueObject.VoteTypeCrush:      "crush",
-	romancesValueObject.VoteTypeCompliment: "compliment",
-}
-
-var changeUserVoteTypeFromString = map[string]romancesValueObject.VoteType{
-	"yes":        romancesValueObject.VoteTypeYes,
-	"crush":      romancesValueObject.VoteTypeCrush,
-	"compliment": romancesValueObject.VoteTypeCompliment,
-}
-
 func (v *ChangeUserVoteType) MarshalText() ([]byte, error) {
-	return []byte(changeUserVoteTypeToString[romancesValueObject.VoteType(*v)]), nil
+	return []byte(romancesValueObject.VoteType(*v).String()), nil
 }
 func (v *ChangeUserVoteType) UnmarshalText(b []byte) error {
-	vv, ok := changeUserVoteTypeFromString[string(b)]
+	vv, ok := voteTypeFromText(b)
 	if !ok {
 		return fmt.Errorf("invalid vote type: %q", b)
 	}
@@ -33,13 +21,8 @@ func (v *ChangeUserVoteType) UnmarshalText(b []byte) error {
 }
 
 func (v *ChangeUserVoteType) Schema(r huma.Registry) *huma.Schema {
-	enums := make([]any, 0, len(changeUserVoteTypeToString))
-	for voteType := range changeUserVoteTypeToString {
-		enums = append(enums, changeUserVoteTypeToString[voteType])
-	}
-
 	return &huma.Schema{
-		Type: huma.TypeString,
-		Enum: enums,
+		Type:        huma.TypeString,
+		Description: "Vote type, the vote transition policy of the country decides which ones may be changed to",
 	}
 }
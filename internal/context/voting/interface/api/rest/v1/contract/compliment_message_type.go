@@ -0,0 +1,33 @@
+package contract
+
+import (
+	romancesValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
+	huma "github.com/danielgtaylor/huma/v2"
+)
+
+type ComplimentMessageType romancesValueObject.ComplimentMessage
+
+func (m *ComplimentMessageType) MarshalText() ([]byte, error) {
+	return []byte(*m), nil
+}
+
+func (m *ComplimentMessageType) UnmarshalText(b []byte) error {
+	message, err := romancesValueObject.NewComplimentMessage(string(b))
+	if err != nil {
+		return err
+	}
+	*m = ComplimentMessageType(message)
+	return nil
+}
+
+func (m *ComplimentMessageType) Schema(r huma.Registry) *huma.Schema {
+	minLength := 1
+	maxLength := romancesValueObject.ComplimentMessageMaxLength
+
+	return &huma.Schema{
+		Type:        huma.TypeString,
+		MinLength:   &minLength,
+		MaxLength:   &maxLength,
+		Description: "Text of the compliment, allowed for compliment votes only",
+	}
+}
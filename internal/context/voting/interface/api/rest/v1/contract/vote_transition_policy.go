@@ -0,0 +1,53 @@
+package contract
+
+import (
+	"fmt"
+	romancesValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
+	huma "github.com/danielgtaylor/huma/v2"
+	"slices"
+)
+
+var votableVoteTypes = []romancesValueObject.VoteType{
+	romancesValueObject.VoteTypeYes,
+	romancesValueObject.VoteTypeNo,
+	romancesValueObject.VoteTypeCrush,
+	romancesValueObject.VoteTypeCompliment,
+}
+
+// ValidateAddUserVoteType checks that the policy lets a vote be added with the type in at least one country.
+func ValidateAddUserVoteType(
+	policy romancesValueObject.VoteTransitionPolicy,
+	voteType AddUserVoteType,
+	location string,
+) error {
+	return validateVoteType(romancesValueObject.VoteType(voteType), policy.AddableVoteTypes(), location)
+}
+
+// ValidateChangeUserVoteType checks that the policy lets a vote be changed to the type in at least one country.
+func ValidateChangeUserVoteType(
+	policy romancesValueObject.VoteTransitionPolicy,
+	voteType ChangeUserVoteType,
+	location string,
+) error {
+	return validateVoteType(romancesValueObject.VoteType(voteType), policy.ChangeableVoteTypes(), location)
+}
+
+func validateVoteType(voteType romancesValueObject.VoteType, allowed []romancesValueObject.VoteType, location string) error {
+	if slices.Contains(allowed, voteType) {
+		return nil
+	}
+
+	return &huma.ErrorDetail{
+		Location: location,
+		Message:  fmt.Sprintf("The vote type is not allowed, expected one of %v", allowed),
+		Value:    voteType.String(),
+	}
+}
+
+func voteTypeFromText(b []byte) (romancesValueObject.VoteType, bool) {
+	voteType, ok := romancesValueObject.VoteTypeFromString(string(b))
+	if !ok || !slices.Contains(votableVoteTypes, voteType) {
+		return romancesValueObject.VoteTypeEmpty, false
+	}
+	return voteType, true
+}
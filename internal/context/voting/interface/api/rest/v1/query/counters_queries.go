@@ -6,6 +6,7 @@ import (
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/interface/api/rest/v1/contract"
 	huma "github.com/danielgtaylor/huma/v2"
 	"github.com/google/uuid"
+	"time"
 )
 
 type LifetimeCountersGet struct {
@@ -13,11 +14,30 @@ type LifetimeCountersGet struct {
 	ActiveUserId uuid.UUID `path:"active_user_id" format:"uuid" doc:"Active User Id"`
 }
 
+type HourlyCountersSeriesGet struct {
+	CountryId    uint16    `path:"country_id" doc:"Current active user country ID"`
+	ActiveUserId uuid.UUID `path:"active_user_id" format:"uuid" doc:"Active User Id"`
+	Hours        uint8     `query:"hours" minimum:"1" maximum:"48" default:"24" doc:"Number of the latest hours to return counters for, the current hour included"`
+}
+
+type DailyCountersGet struct {
+	CountryId    uint16    `path:"country_id" doc:"Current active user country ID"`
+	ActiveUserId uuid.UUID `path:"active_user_id" format:"uuid" doc:"Active User Id"`
+	Days         uint16    `query:"days" minimum:"1" maximum:"366" default:"30" doc:"Number of the latest UTC days to return counters for, the current day included"`
+}
+
+type WeeklyCountersGet struct {
+	CountryId    uint16    `path:"country_id" doc:"Current active user country ID"`
+	ActiveUserId uuid.UUID `path:"active_user_id" format:"uuid" doc:"Active User Id"`
+	Weeks        uint16    `query:"weeks" minimum:"1" maximum:"104" default:"12" doc:"Number of the latest ISO weeks to return counters for, the current week included"`
+}
+
 type HourlyCountersGet struct {
 	CountryId            uint16                       `path:"country_id" doc:"Current active user country ID"`
 	ActiveUserId         uuid.UUID                    `path:"active_user_id" format:"uuid" doc:"Active User Id"`
 	HoursOffsetGroupsRaw contract.NonNullIntArrayType `query:"hours_offset_groups" required:"true" minItems:"1" example:"[12,24]" maxItems:"10" doc:"Specifies the hours for which counters need to be returned"`
 	HoursOffsetGroups    []uint8                      `json:"-"`
+	At                   time.Time                    `query:"at" doc:"Reference time the hour offsets are counted back from, the current time by default. It must not be in the future nor older than the counters TTL"`
 }
 
 func (in *HourlyCountersGet) Resolve(ctx huma.Context, prefix *huma.PathBuffer) []error {
@@ -44,5 +64,6 @@ func (in *HourlyCountersGet) Resolve(ctx huma.Context, prefix *huma.PathBuffer)
 		}}
 	}
 	in.HoursOffsetGroups = offsets
+
 	return nil
 }
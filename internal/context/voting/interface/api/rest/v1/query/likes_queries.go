@@ -0,0 +1,46 @@
+package query
+
+import (
+	"fmt"
+	romancesValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
+	huma "github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+)
+
+type LikesList struct {
+	CountryId     uint16                         `path:"country_id" doc:"Current active user country ID"`
+	ActiveUserId  uuid.UUID                      `path:"active_user_id" format:"uuid" doc:"Active User Id"`
+	VoteTypesRaw  []string                       `query:"vote_types" enum:"yes,crush,compliment" maxItems:"3" uniqueItems:"true" example:"crush,compliment" doc:"Only return likes with the given peer vote types, all positive vote types by default"`
+	Limit         int32                          `query:"limit" minimum:"1" maximum:"100" default:"20" doc:"Maximum number of likes to return"`
+	Cursor        string                         `query:"cursor" maxLength:"1024" doc:"Opaque cursor returned as next_cursor by the previous page"`
+	PeerVoteTypes []romancesValueObject.VoteType `json:"-"`
+}
+
+func (in *LikesList) Resolve(ctx huma.Context, prefix *huma.PathBuffer) []error {
+	voteTypes := make([]romancesValueObject.VoteType, 0, len(in.VoteTypesRaw))
+
+	for _, raw := range in.VoteTypesRaw {
+		voteType, ok := findPositiveVoteType(raw)
+		if !ok {
+			return []error{&huma.ErrorDetail{
+				Location: prefix.With("query.vote_types"),
+				Message:  fmt.Sprintf("The value %q is not a positive vote type", raw),
+				Value:    in.VoteTypesRaw,
+			}}
+		}
+		voteTypes = append(voteTypes, voteType)
+	}
+
+	in.PeerVoteTypes = voteTypes
+	return nil
+}
+
+func findPositiveVoteType(raw string) (romancesValueObject.VoteType, bool) {
+	for voteType, name := range romancesValueObject.UserVoteTypeToString {
+		if name == raw && voteType.IsPositive() {
+			return voteType, true
+		}
+	}
+
+	return romancesValueObject.VoteTypeEmpty, false
+}
@@ -0,0 +1,10 @@
+package query
+
+import "github.com/google/uuid"
+
+type MatchesList struct {
+	CountryId    uint16    `path:"country_id" doc:"Current active user country ID"`
+	ActiveUserId uuid.UUID `path:"active_user_id" format:"uuid" doc:"Active User Id"`
+	Limit        int32     `query:"limit" minimum:"1" maximum:"100" default:"20" doc:"Maximum number of matches to return"`
+	Cursor       string    `query:"cursor" maxLength:"1024" doc:"Opaque cursor returned as next_cursor by the previous page"`
+}
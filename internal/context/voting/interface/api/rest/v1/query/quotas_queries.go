@@ -0,0 +1,10 @@
+package query
+
+import (
+	"github.com/google/uuid"
+)
+
+type DailyQuotasGet struct {
+	CountryId    uint16    `path:"country_id" doc:"Current active user country ID"`
+	ActiveUserId uuid.UUID `path:"active_user_id" format:"uuid" doc:"Active User Id"`
+}
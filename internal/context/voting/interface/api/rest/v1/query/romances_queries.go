@@ -1,9 +1,43 @@
 package query
 
-import "github.com/google/uuid"
+import (
+	"fmt"
+	huma "github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+)
 
 type RomanceGet struct {
+	CountryId     uint16    `path:"country_id" doc:"Current active user country ID"`
+	ActiveUserId  uuid.UUID `path:"active_user_id" format:"uuid" doc:"Active User Id"`
+	PeerId        uuid.UUID `path:"peer_id" format:"uuid" doc:"Peer user ID"`
+	PeerCountryId uint16    `query:"peer_country_id" doc:"Peer user country ID, the active user country ID when omitted"`
+}
+
+type RomancesBatchGet struct {
+	CountryId     uint16    `path:"country_id" doc:"Current active user country ID"`
+	ActiveUserId  uuid.UUID `path:"active_user_id" format:"uuid" doc:"Active User Id"`
+	PeerCountryId uint16    `query:"peer_country_id" doc:"Country ID of all the peers, the active user country ID when omitted"`
+	Body          struct {
+		PeerIds []uuid.UUID `json:"peer_ids" minItems:"1" maxItems:"100" uniqueItems:"true" doc:"Peer user IDs"`
+	}
+}
+
+func (in *RomancesBatchGet) Resolve(ctx huma.Context, prefix *huma.PathBuffer) []error {
+	for i, peerId := range in.Body.PeerIds {
+		if peerId == in.ActiveUserId {
+			return []error{&huma.ErrorDetail{
+				Location: prefix.With(fmt.Sprintf("body.peer_ids[%d]", i)),
+				Message:  "The peer ID must differ from the active user ID",
+				Value:    peerId,
+			}}
+		}
+	}
+	return nil
+}
+
+type RomancesList struct {
 	CountryId    uint16    `path:"country_id" doc:"Current active user country ID"`
 	ActiveUserId uuid.UUID `path:"active_user_id" format:"uuid" doc:"Active User Id"`
-	PeerId       uuid.UUID `path:"peer_id" format:"uuid" doc:"Peer user ID"`
+	Limit        int32     `query:"limit" minimum:"1" maximum:"100" default:"20" doc:"Maximum number of romances to return"`
+	Cursor       string    `query:"cursor" maxLength:"1024" doc:"Opaque cursor returned as next_cursor by the previous page"`
 }
@@ -2,9 +2,12 @@ package v1
 
 import (
 	"context"
+	"fmt"
 	apiResponse "github.bumble.dev/shcherbanich/user-votes-storage/internal/app/api/response"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/application"
+	romancesValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/interface/api/rest/v1/command"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/interface/api/rest/v1/contract"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/interface/api/rest/v1/query"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/interface/api/rest/v1/response"
 	"github.com/danielgtaylor/huma/v2"
@@ -12,21 +15,27 @@ import (
 )
 
 type VotesStorageRoutsRegister struct {
-	votesService application.VotingService
+	votesService         application.VotingService
+	voteTransitionPolicy romancesValueObject.VoteTransitionPolicy
 }
 
 func NewVotesStorageRoutsRegister(
 	votesService application.VotingService,
+	voteTransitionPolicy romancesValueObject.VoteTransitionPolicy,
 ) VotesStorageRoutsRegister {
 	return VotesStorageRoutsRegister{
-		votesService: votesService,
+		votesService:         votesService,
+		voteTransitionPolicy: voteTransitionPolicy,
 	}
 }
 
 func (v VotesStorageRoutsRegister) RegisterV1Routs(grp *huma.Group) {
 	registerRomancesRouts(grp, v.votesService)
-	registerVotesRouts(grp, v.votesService)
+	registerMatchesRouts(grp, v.votesService)
+	registerLikesRouts(grp, v.votesService)
+	registerVotesRouts(grp, v.votesService, v.voteTransitionPolicy)
 	registerCountersRouts(grp, v.votesService)
+	registerQuotasRouts(grp, v.votesService)
 }
 
 func registerRomancesRouts(
@@ -56,6 +65,23 @@ func registerRomancesRouts(
 		return resp, nil
 	})
 
+	// POST /v1/romances/{country_id}/{active_user_id}:batchGet
+	huma.Register(grp, huma.Operation{
+		OperationID: "batch-get-romances",
+		Method:      http.MethodPost,
+		Path:        "/{country_id}/{active_user_id}:batchGet",
+		Summary:     "Get romances of the active user with many peers at once",
+		Description: "Returns the romances from the active user's perspective in the order of the requested peer IDs. " +
+			"Romances without votes are returned with empty votes.",
+	}, func(reqCtx context.Context, get *query.RomancesBatchGet) (*response.RomancesBatchGetResponse, error) {
+		romances, err := votesService.BatchGetRomances(reqCtx, *get)
+		if err != nil {
+			return nil, response.ToApiError(err)
+		}
+		resp := response.CreateRomancesBatchGetResponseFromRomances(romances)
+		return resp, nil
+	})
+
 	// DELETE /v1/romances/{country_id}/{active_user_id}/{peer_id}
 	huma.Register(grp, huma.Operation{
 		OperationID: "delete-romance",
@@ -70,6 +96,55 @@ func registerRomancesRouts(
 		return nil, nil
 	})
 
+	// POST /v1/romances/{country_id}/{active_user_id}/{peer_id}/block
+	huma.Register(grp, huma.Operation{
+		OperationID: "block-romance",
+		Method:      http.MethodPost,
+		Path:        "/{country_id}/{active_user_id}/{peer_id}/block",
+		Summary:     "Block the peer",
+		Description: "Freezes the romance: no votes from either side are accepted and the pair disappears " +
+			"from the romances, matches and likes listings until the active user lifts the block.",
+	}, func(reqCtx context.Context, command *command.BlockRomance) (*struct{}, error) {
+		err := votesService.BlockRomance(reqCtx, *command)
+		if err != nil {
+			return nil, response.ToApiError(err)
+		}
+		return nil, nil
+	})
+
+	// DELETE /v1/romances/{country_id}/{active_user_id}/{peer_id}/block
+	huma.Register(grp, huma.Operation{
+		OperationID: "unblock-romance",
+		Method:      http.MethodDelete,
+		Path:        "/{country_id}/{active_user_id}/{peer_id}/block",
+		Summary:     "Lift the block of the peer",
+		Description: "Only the user who blocked the peer can lift the block.",
+		Responses:   apiResponse.GenerateErrorResponsesGroup(grp, 409),
+	}, func(reqCtx context.Context, command *command.UnblockRomance) (*struct{}, error) {
+		err := votesService.UnblockRomance(reqCtx, *command)
+		if err != nil {
+			return nil, response.ToApiError(err)
+		}
+		return nil, nil
+	})
+
+	// GET /v1/romances/{country_id}/{active_user_id}
+	huma.Register(grp, huma.Operation{
+		OperationID: "list-romances",
+		Method:      http.MethodGet,
+		Path:        "/{country_id}/{active_user_id}",
+		Summary:     "List active user romances",
+		Description: "Returns the romances in which the active user takes part, from the active user's perspective. " +
+			"The result is paginated: pass the returned next_cursor to get the following page.",
+	}, func(reqCtx context.Context, query *query.RomancesList) (*response.RomancesListResponse, error) {
+		page, err := votesService.ListRomances(reqCtx, *query)
+		if err != nil {
+			return nil, response.ToApiError(err)
+		}
+		resp := response.CreateRomancesListResponseFromRomancesPage(page)
+		return resp, nil
+	})
+
 	// DELETE /v1/romances/{country_id}/{active_user_id}
 	huma.Register(grp, huma.Operation{
 		OperationID: "delete-romances",
@@ -85,9 +160,66 @@ func registerRomancesRouts(
 	})
 }
 
+func registerMatchesRouts(
+	grp *huma.Group,
+	votesService application.VotingService,
+) {
+	grp = huma.NewGroup(grp, "/matches")
+	grp.UseSimpleModifier(func(op *huma.Operation) {
+		op.Tags = []string{"Matches"}
+	})
+
+	// GET /v1/matches/{country_id}/{active_user_id}
+	huma.Register(grp, huma.Operation{
+		OperationID: "list-matches",
+		Method:      http.MethodGet,
+		Path:        "/{country_id}/{active_user_id}",
+		Summary:     "List active user matches",
+		Description: "Returns the romances in which both users voted positively, the most recent match first. " +
+			"The match time is the later of the two votes. " +
+			"The result is paginated: pass the returned next_cursor to get the following page.",
+	}, func(reqCtx context.Context, query *query.MatchesList) (*response.MatchesListResponse, error) {
+		page, err := votesService.ListMatches(reqCtx, *query)
+		if err != nil {
+			return nil, response.ToApiError(err)
+		}
+		resp := response.CreateMatchesListResponseFromRomancesPage(page)
+		return resp, nil
+	})
+}
+
+func registerLikesRouts(
+	grp *huma.Group,
+	votesService application.VotingService,
+) {
+	grp = huma.NewGroup(grp, "/likes")
+	grp.UseSimpleModifier(func(op *huma.Operation) {
+		op.Tags = []string{"Likes"}
+	})
+
+	// GET /v1/likes/{country_id}/{active_user_id}
+	huma.Register(grp, huma.Operation{
+		OperationID: "list-likes",
+		Method:      http.MethodGet,
+		Path:        "/{country_id}/{active_user_id}",
+		Summary:     "List peers who liked the active user",
+		Description: "Returns the romances in which the peer voted positively and the active user has not voted yet, " +
+			"the most recent peer vote first. Use vote_types to get e.g. crushes only. " +
+			"The result is paginated: pass the returned next_cursor with the same vote_types to get the following page.",
+	}, func(reqCtx context.Context, query *query.LikesList) (*response.LikesListResponse, error) {
+		page, err := votesService.ListLikes(reqCtx, *query)
+		if err != nil {
+			return nil, response.ToApiError(err)
+		}
+		resp := response.CreateLikesListResponseFromRomancesPage(page)
+		return resp, nil
+	})
+}
+
 func registerVotesRouts(
 	grp *huma.Group,
 	votesService application.VotingService,
+	voteTransitionPolicy romancesValueObject.VoteTransitionPolicy,
 ) {
 	grp = huma.NewGroup(grp, "/votes")
 	grp.UseSimpleModifier(func(op *huma.Operation) {
@@ -115,7 +247,13 @@ func registerVotesRouts(
 		Method:      http.MethodPost,
 		Path:        "/{country_id}",
 		Summary:     "Add new vote",
+		Responses:   apiResponse.GenerateErrorResponsesGroup(grp, 409, 429),
 	}, func(reqCtx context.Context, command *command.VoteAdd) (*response.VoteAddResponse, error) {
+		err := contract.ValidateAddUserVoteType(voteTransitionPolicy, command.Body.VoteType, "body.vote_type")
+		if err != nil {
+			return nil, huma.Error422UnprocessableEntity("validation failed", err)
+		}
+
 		vote, err := votesService.AddUserVote(reqCtx, *command)
 		if err != nil {
 			return nil, response.ToApiError(err)
@@ -124,14 +262,47 @@ func registerVotesRouts(
 		return resp, nil
 	})
 
+	// POST /v1/votes/{country_id}:batch
+	huma.Register(grp, huma.Operation{
+		OperationID: "add-votes-batch",
+		Method:      http.MethodPost,
+		Path:        "/{country_id}:batch",
+		Summary:     "Add many votes at once",
+		Description: "Adds every vote independently and returns a status per vote in the order of the submitted votes. " +
+			"A failed vote does not fail the whole batch.",
+	}, func(reqCtx context.Context, command *command.VotesBatchAdd) (*response.VotesBatchAddResponse, error) {
+		var errs []error
+		for i, vote := range command.Body.Votes {
+			location := fmt.Sprintf("body.votes[%d].vote_type", i)
+			if err := contract.ValidateAddUserVoteType(voteTransitionPolicy, vote.VoteType, location); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if len(errs) > 0 {
+			return nil, huma.Error422UnprocessableEntity("validation failed", errs...)
+		}
+
+		results, err := votesService.AddUserVotes(reqCtx, *command)
+		if err != nil {
+			return nil, response.ToApiError(err)
+		}
+		resp := response.CreateVotesBatchAddResponseFromResults(results)
+		return resp, nil
+	})
+
 	// PATCH /v1/votes/{country_id}/{active_user_id}/{peer_id}/change-contract
 	huma.Register(grp, huma.Operation{
 		OperationID: "change-vote",
 		Method:      http.MethodPatch,
 		Path:        "/{country_id}/{active_user_id}/{peer_id}/change-contract",
 		Summary:     "Change active user vote contract",
-		Responses:   apiResponse.GenerateErrorResponsesGroup(grp, 404),
+		Responses:   apiResponse.GenerateErrorResponsesGroup(grp, 404, 409, 429),
 	}, func(reqCtx context.Context, command *command.ChangeVoteType) (*response.ChangeVoteResponse, error) {
+		err := contract.ValidateChangeUserVoteType(voteTransitionPolicy, command.Body.NewType, "body.new_vote_type")
+		if err != nil {
+			return nil, huma.Error422UnprocessableEntity("validation failed", err)
+		}
+
 		vote, err := votesService.ChangeUserVote(reqCtx, *command)
 		if err != nil {
 			return nil, response.ToApiError(err)
@@ -193,4 +364,106 @@ func registerCountersRouts(
 		resp := response.CreateHourlyCountersGetResponseFromCountersGroup(countersGroup)
 		return resp, nil
 	})
+
+	// GET /v1/counters/{country_id}/{active_user_id}/hourly/series
+	huma.Register(grp, huma.Operation{
+		OperationID: "get-hourly-counters-series",
+		Method:      http.MethodGet,
+		Path:        "/{country_id}/{active_user_id}/hourly/series",
+		Summary:     "Get counters of every one of the latest hours for the active user",
+	}, func(reqCtx context.Context, query *query.HourlyCountersSeriesGet) (*response.HourlyCountersSeriesGetResponse, error) {
+		countersGroups, err := votesService.GetHourlyCountersSeries(reqCtx, *query)
+		if err != nil {
+			return nil, response.ToApiError(err)
+		}
+		resp := response.CreateHourlyCountersSeriesGetResponseFromCountersGroups(countersGroups)
+		return resp, nil
+	})
+
+	// GET /v1/counters/{country_id}/{active_user_id}/daily
+	huma.Register(grp, huma.Operation{
+		OperationID: "get-daily-counters",
+		Method:      http.MethodGet,
+		Path:        "/{country_id}/{active_user_id}/daily",
+		Summary:     "Get daily counters for the active user",
+	}, func(reqCtx context.Context, query *query.DailyCountersGet) (*response.PeriodCountersGetResponse, error) {
+		periodCounters, err := votesService.GetDailyCounters(reqCtx, *query)
+		if err != nil {
+			return nil, response.ToApiError(err)
+		}
+		resp := response.CreatePeriodCountersGetResponseFromPeriodCounters(periodCounters)
+		return resp, nil
+	})
+
+	// GET /v1/counters/{country_id}/{active_user_id}/weekly
+	huma.Register(grp, huma.Operation{
+		OperationID: "get-weekly-counters",
+		Method:      http.MethodGet,
+		Path:        "/{country_id}/{active_user_id}/weekly",
+		Summary:     "Get weekly counters for the active user",
+	}, func(reqCtx context.Context, query *query.WeeklyCountersGet) (*response.PeriodCountersGetResponse, error) {
+		periodCounters, err := votesService.GetWeeklyCounters(reqCtx, *query)
+		if err != nil {
+			return nil, response.ToApiError(err)
+		}
+		resp := response.CreatePeriodCountersGetResponseFromPeriodCounters(periodCounters)
+		return resp, nil
+	})
+
+	// POST /v1/counters/{country_id}/{active_user_id}:reconcile
+	huma.Register(grp, huma.Operation{
+		OperationID:   "reconcile-user-counters",
+		Method:        http.MethodPost,
+		Path:          "/{country_id}/{active_user_id}:reconcile",
+		Summary:       "Reconcile the lifetime counters of the active user with the romances",
+		Description:   "The reconciliation runs in the message processor and reports the drift, the counters are rewritten only when asked to.",
+		DefaultStatus: http.StatusAccepted,
+	}, func(reqCtx context.Context, command *command.ReconcileUserCounters) (*struct{}, error) {
+		err := votesService.ReconcileUserCounters(reqCtx, *command)
+		if err != nil {
+			return nil, response.ToApiError(err)
+		}
+		return nil, nil
+	})
+
+	// POST /v1/counters/{country_id}:reconcile
+	huma.Register(grp, huma.Operation{
+		OperationID:   "reconcile-country-counters",
+		Method:        http.MethodPost,
+		Path:          "/{country_id}:reconcile",
+		Summary:       "Reconcile the lifetime counters of all users of the romances stored in the region of the country",
+		Description:   "The reconciliation runs in the message processor and reports the drift, the counters are rewritten only when asked to.",
+		DefaultStatus: http.StatusAccepted,
+	}, func(reqCtx context.Context, command *command.ReconcileCountryCounters) (*struct{}, error) {
+		err := votesService.ReconcileCountryCounters(reqCtx, *command)
+		if err != nil {
+			return nil, response.ToApiError(err)
+		}
+		return nil, nil
+	})
+}
+
+func registerQuotasRouts(
+	grp *huma.Group,
+	votesService application.VotingService,
+) {
+	grp = huma.NewGroup(grp, "/quotas")
+	grp.UseSimpleModifier(func(op *huma.Operation) {
+		op.Tags = []string{"Quotas"}
+	})
+
+	// GET /v1/quotas/{country_id}/{active_user_id}
+	huma.Register(grp, huma.Operation{
+		OperationID: "get-daily-quotas",
+		Method:      http.MethodGet,
+		Path:        "/{country_id}/{active_user_id}",
+		Summary:     "Get remaining daily quotas of the limited vote types for the active user",
+	}, func(reqCtx context.Context, query *query.DailyQuotasGet) (*response.DailyQuotasGetResponse, error) {
+		quotas, err := votesService.GetDailyQuotas(reqCtx, *query)
+		if err != nil {
+			return nil, response.ToApiError(err)
+		}
+		resp := response.CreateDailyQuotasGetResponseFromDailyQuotas(quotas)
+		return resp, nil
+	})
 }
@@ -1,12 +1,35 @@
 package response
 
-import "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/entity"
+import (
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/entity"
+	"time"
+)
 
 type CountersGroup struct {
-	IncomingYes uint32 `json:"incoming_yes" doc:"Incoming yes votes count"`
+	IncomingYes uint32 `json:"incoming_yes" doc:"Incoming positive votes count, it sums up the yes, crush and compliment votes"`
 	IncomingNo  uint32 `json:"incoming_no" doc:"Incoming no votes count"`
-	OutgoingYes uint32 `json:"outgoing_yes" doc:"Outgoing yes votes count"`
+	OutgoingYes uint32 `json:"outgoing_yes" doc:"Outgoing positive votes count, it sums up the yes, crush and compliment votes"`
 	OutgoingNo  uint32 `json:"outgoing_no" doc:"Outgoing no votes count"`
+
+	IncomingByVoteType VoteTypeCounters `json:"incoming_by_vote_type" doc:"Incoming votes count of every vote type"`
+	OutgoingByVoteType VoteTypeCounters `json:"outgoing_by_vote_type" doc:"Outgoing votes count of every vote type"`
+	Matches            uint32           `json:"matches" doc:"Count of the romances where both votes are positive"`
+}
+
+type VoteTypeCounters struct {
+	Yes        uint32 `json:"yes" doc:"Yes votes count"`
+	No         uint32 `json:"no" doc:"No votes count"`
+	Crush      uint32 `json:"crush" doc:"Crush votes count"`
+	Compliment uint32 `json:"compliment" doc:"Compliment votes count"`
+}
+
+func createVoteTypeCounters(counters entity.VoteTypeCounters) VoteTypeCounters {
+	return VoteTypeCounters{
+		Yes:        counters.Yes,
+		No:         counters.No,
+		Crush:      counters.Crush,
+		Compliment: counters.Compliment,
+	}
 }
 
 type LifetimeCountersGetResponse struct {
@@ -20,6 +43,10 @@ func CreateLifetimeCountersGetResponseFromCountersGroup(counters entity.Counters
 			IncomingNo:  counters.IncomingNo,
 			OutgoingYes: counters.OutgoingYes,
 			OutgoingNo:  counters.OutgoingNo,
+
+			IncomingByVoteType: createVoteTypeCounters(counters.IncomingByVoteType),
+			OutgoingByVoteType: createVoteTypeCounters(counters.OutgoingByVoteType),
+			Matches:            counters.Matches,
 		},
 	}
 	return resp
@@ -40,8 +67,80 @@ func CreateHourlyCountersGetResponseFromCountersGroup(counters map[uint8]*entity
 			IncomingNo:  counter.IncomingNo,
 			OutgoingYes: counter.OutgoingYes,
 			OutgoingNo:  counter.OutgoingNo,
+
+			IncomingByVoteType: createVoteTypeCounters(counter.IncomingByVoteType),
+			OutgoingByVoteType: createVoteTypeCounters(counter.OutgoingByVoteType),
+			Matches:            counter.Matches,
 		}
 	}
 
 	return resp
 }
+
+type HourCounters struct {
+	HourStart time.Time `json:"hour_start" doc:"Start of the hour in UTC"`
+	CountersGroup
+}
+
+type HourlyCountersSeriesGetResponse struct {
+	Body struct {
+		Counters []HourCounters `json:"counters" doc:"Counters of every hour, the oldest hour first"`
+	}
+}
+
+func CreateHourlyCountersSeriesGetResponseFromCountersGroups(countersGroups []entity.CountersGroup) *HourlyCountersSeriesGetResponse {
+	resp := &HourlyCountersSeriesGetResponse{}
+	resp.Body.Counters = make([]HourCounters, 0, len(countersGroups))
+
+	for _, counters := range countersGroups {
+		resp.Body.Counters = append(resp.Body.Counters, HourCounters{
+			HourStart: time.Unix(int64(counters.HourUnixTimestamp), 0).UTC(),
+			CountersGroup: CountersGroup{
+				IncomingYes: counters.IncomingYes,
+				IncomingNo:  counters.IncomingNo,
+				OutgoingYes: counters.OutgoingYes,
+				OutgoingNo:  counters.OutgoingNo,
+
+				IncomingByVoteType: createVoteTypeCounters(counters.IncomingByVoteType),
+				OutgoingByVoteType: createVoteTypeCounters(counters.OutgoingByVoteType),
+				Matches:            counters.Matches,
+			},
+		})
+	}
+
+	return resp
+}
+
+type PeriodCounters struct {
+	PeriodStart time.Time `json:"period_start" doc:"Start of the period in UTC"`
+	CountersGroup
+}
+
+type PeriodCountersGetResponse struct {
+	Body struct {
+		Counters []PeriodCounters `json:"counters" doc:"Counters of the periods, the current period first"`
+	}
+}
+
+func CreatePeriodCountersGetResponseFromPeriodCounters(periodCounters []entity.PeriodCounters) *PeriodCountersGetResponse {
+	resp := &PeriodCountersGetResponse{}
+	resp.Body.Counters = make([]PeriodCounters, 0, len(periodCounters))
+
+	for _, counters := range periodCounters {
+		resp.Body.Counters = append(resp.Body.Counters, PeriodCounters{
+			PeriodStart: counters.PeriodStart,
+			CountersGroup: CountersGroup{
+				IncomingYes: counters.IncomingYes,
+				IncomingNo:  counters.IncomingNo,
+				OutgoingYes: counters.OutgoingYes,
+				OutgoingNo:  counters.OutgoingNo,
+
+				IncomingByVoteType: createVoteTypeCounters(counters.IncomingByVoteType),
+				OutgoingByVoteType: createVoteTypeCounters(counters.OutgoingByVoteType),
+				Matches:            counters.Matches,
+			},
+		})
+	}
+
+	return resp
+}
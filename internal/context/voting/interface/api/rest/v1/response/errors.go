@@ -3,6 +3,8 @@ package response
 import (
 	"errors"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/app/api/response"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance"
 	"net/http"
 )
@@ -15,6 +17,14 @@ func ToApiError(err error) error {
 		return NewErr400BadRequest(err.Error())
 	case errors.Is(err, romance.ErrWrongVote):
 		return NewErr400BadRequest(err.Error())
+	case errors.Is(err, romance.ErrInvalidCursor):
+		return NewErr400BadRequest(err.Error())
+	case errors.Is(err, romance.ErrRomanceBlocked):
+		return NewErr409Conflict(err.Error())
+	case errors.Is(err, counter.ErrInvalidReferenceTime):
+		return NewErr400BadRequest(err.Error())
+	case errors.Is(err, quota.ErrQuotaExceeded):
+		return NewErr429TooManyRequests(err.Error())
 	default:
 		return NewErr500InternalServerError("Internal error")
 	}
@@ -34,6 +44,20 @@ func NewErr400BadRequest(msg string) *response.HumaApiError {
 	}
 }
 
+func NewErr409Conflict(msg string) *response.HumaApiError {
+	return &response.HumaApiError{
+		Message: msg,
+		Status:  http.StatusConflict,
+	}
+}
+
+func NewErr429TooManyRequests(msg string) *response.HumaApiError {
+	return &response.HumaApiError{
+		Message: msg,
+		Status:  http.StatusTooManyRequests,
+	}
+}
+
 func NewErr500InternalServerError(msg string) *response.HumaApiError {
 	return &response.HumaApiError{
 		Message: msg,
@@ -0,0 +1,35 @@
+package response
+
+import (
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/entity"
+	"github.com/google/uuid"
+)
+
+type LikesListItem struct {
+	PeerId        uuid.UUID `json:"peer_id" format:"uuid" doc:"Peer user ID"`
+	PeerCountryId uint16    `json:"peer_country_id" doc:"Peer user country ID"`
+	PeerUserVote  Vote      `json:"peer_vote" doc:"Positive peer user vote the active user has not answered yet"`
+}
+
+type LikesListResponse struct {
+	Body struct {
+		Likes      []LikesListItem `json:"likes" doc:"Peers who liked the active user, the most recent vote first"`
+		NextCursor string          `json:"next_cursor,omitempty" doc:"Cursor of the next page, absent on the last page"`
+	}
+}
+
+func CreateLikesListResponseFromRomancesPage(page entity.RomancesPage) *LikesListResponse {
+	resp := &LikesListResponse{}
+	resp.Body.Likes = make([]LikesListItem, 0, len(page.Romances))
+	resp.Body.NextCursor = page.NextCursor
+
+	for _, romance := range page.Romances {
+		resp.Body.Likes = append(resp.Body.Likes, LikesListItem{
+			PeerId:        romance.ActiveUserVote.Id.PeerUserId(),
+			PeerCountryId: romance.ActiveUserVote.Id.PeerCountryId(),
+			PeerUserVote:  createVoteFromVoteEntity(romance.PeerUserVote),
+		})
+	}
+
+	return resp
+}
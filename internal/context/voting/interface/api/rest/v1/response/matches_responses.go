@@ -0,0 +1,40 @@
+package response
+
+import (
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/entity"
+	"github.com/google/uuid"
+	"time"
+)
+
+type MatchesListItem struct {
+	PeerId         uuid.UUID  `json:"peer_id" format:"uuid" doc:"Peer user ID"`
+	PeerCountryId  uint16     `json:"peer_country_id" doc:"Peer user country ID"`
+	MatchedAt      *time.Time `json:"matched_at" doc:"Time of the later of the two positive votes"`
+	ActiveUserVote Vote       `json:"active_user_vote" doc:"Active user vote"`
+	PeerUserVote   Vote       `json:"peer_vote" doc:"Peer user vote"`
+}
+
+type MatchesListResponse struct {
+	Body struct {
+		Matches    []MatchesListItem `json:"matches" doc:"Mutual romances of the active user, the most recent match first"`
+		NextCursor string            `json:"next_cursor,omitempty" doc:"Cursor of the next page, absent on the last page"`
+	}
+}
+
+func CreateMatchesListResponseFromRomancesPage(page entity.RomancesPage) *MatchesListResponse {
+	resp := &MatchesListResponse{}
+	resp.Body.Matches = make([]MatchesListItem, 0, len(page.Romances))
+	resp.Body.NextCursor = page.NextCursor
+
+	for _, romance := range page.Romances {
+		resp.Body.Matches = append(resp.Body.Matches, MatchesListItem{
+			PeerId:         romance.ActiveUserVote.Id.PeerUserId(),
+			PeerCountryId:  romance.ActiveUserVote.Id.PeerCountryId(),
+			MatchedAt:      romance.MatchedAt(),
+			ActiveUserVote: createVoteFromVoteEntity(romance.ActiveUserVote),
+			PeerUserVote:   createVoteFromVoteEntity(romance.PeerUserVote),
+		})
+	}
+
+	return resp
+}
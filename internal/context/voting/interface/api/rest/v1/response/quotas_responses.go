@@ -0,0 +1,38 @@
+package response
+
+import (
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota/entity"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/interface/api/rest/v1/contract"
+	"time"
+)
+
+type DailyQuota struct {
+	VoteType  contract.ReadUserVoteType `json:"vote_type"`
+	Limit     uint32                    `json:"limit" doc:"Votes of the type allowed per UTC day"`
+	Used      uint32                    `json:"used" doc:"Votes of the type added today"`
+	Remaining uint32                    `json:"remaining" doc:"Votes of the type which can still be added today"`
+	ResetsAt  time.Time                 `json:"resets_at" doc:"Time the quota resets at"`
+}
+
+type DailyQuotasGetResponse struct {
+	Body struct {
+		Quotas []DailyQuota `json:"quotas" doc:"Daily quotas of the limited vote types"`
+	}
+}
+
+func CreateDailyQuotasGetResponseFromDailyQuotas(quotas []entity.DailyQuota) *DailyQuotasGetResponse {
+	resp := &DailyQuotasGetResponse{}
+	resp.Body.Quotas = make([]DailyQuota, 0, len(quotas))
+
+	for _, quota := range quotas {
+		resp.Body.Quotas = append(resp.Body.Quotas, DailyQuota{
+			VoteType:  contract.ReadUserVoteType(quota.VoteType),
+			Limit:     quota.Limit,
+			Used:      quota.Used,
+			Remaining: quota.Remaining(),
+			ResetsAt:  quota.ResetsAt,
+		})
+	}
+
+	return resp
+}
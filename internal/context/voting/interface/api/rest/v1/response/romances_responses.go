@@ -3,11 +3,13 @@ package response
 import (
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/entity"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/interface/api/rest/v1/contract"
+	"github.com/google/uuid"
 )
 
 type Romance struct {
-	ActiveUserVote Vote `json:"active_user_vote" doc:"Active user vote"`
-	PeerUserVote   Vote `json:"peer_vote" doc:"Peer user vote"`
+	ActiveUserVote Vote       `json:"active_user_vote" doc:"Active user vote"`
+	PeerUserVote   Vote       `json:"peer_vote" doc:"Peer user vote"`
+	BlockedBy      *uuid.UUID `json:"blocked_by,omitempty" format:"uuid" doc:"User who blocked the peer, absent for romances which are not blocked"`
 }
 
 type RomanceGetResponse struct {
@@ -19,17 +21,85 @@ func CreateRomanceGetResponseFromVoteEntity(vote entity.Romance) *RomanceGetResp
 		Body: Romance{
 			ActiveUserVote: Vote{
 				VoteType:  contract.ReadUserVoteType(vote.ActiveUserVote.VoteType),
+				Message:   string(vote.ActiveUserVote.Message),
 				VotedAt:   vote.ActiveUserVote.VotedAt,
 				CreatedAt: vote.ActiveUserVote.CreatedAt,
 				UpdatedAt: vote.ActiveUserVote.UpdatedAt,
 			},
 			PeerUserVote: Vote{
 				VoteType:  contract.ReadUserVoteType(vote.PeerUserVote.VoteType),
+				Message:   string(vote.PeerUserVote.Message),
 				VotedAt:   vote.PeerUserVote.VotedAt,
 				CreatedAt: vote.PeerUserVote.CreatedAt,
 				UpdatedAt: vote.PeerUserVote.UpdatedAt,
 			},
+			BlockedBy: getBlockedBy(vote),
 		},
 	}
 	return resp
 }
+
+type RomancesListItem struct {
+	PeerId         uuid.UUID  `json:"peer_id" format:"uuid" doc:"Peer user ID"`
+	PeerCountryId  uint16     `json:"peer_country_id" doc:"Peer user country ID"`
+	ActiveUserVote Vote       `json:"active_user_vote" doc:"Active user vote"`
+	PeerUserVote   Vote       `json:"peer_vote" doc:"Peer user vote"`
+	BlockedBy      *uuid.UUID `json:"blocked_by,omitempty" format:"uuid" doc:"User who blocked the peer, absent for romances which are not blocked"`
+}
+
+type RomancesBatchGetResponse struct {
+	Body struct {
+		Romances []RomancesListItem `json:"romances" doc:"Romances from the active user's perspective in the order of the requested peer IDs"`
+	}
+}
+
+func CreateRomancesBatchGetResponseFromRomances(romances []entity.Romance) *RomancesBatchGetResponse {
+	resp := &RomancesBatchGetResponse{}
+	resp.Body.Romances = make([]RomancesListItem, 0, len(romances))
+
+	for _, romance := range romances {
+		resp.Body.Romances = append(resp.Body.Romances, RomancesListItem{
+			PeerId:         romance.ActiveUserVote.Id.PeerUserId(),
+			PeerCountryId:  romance.ActiveUserVote.Id.PeerCountryId(),
+			ActiveUserVote: createVoteFromVoteEntity(romance.ActiveUserVote),
+			PeerUserVote:   createVoteFromVoteEntity(romance.PeerUserVote),
+			BlockedBy:      getBlockedBy(romance),
+		})
+	}
+
+	return resp
+}
+
+type RomancesListResponse struct {
+	Body struct {
+		Romances   []RomancesListItem `json:"romances" doc:"Romances from the active user's perspective"`
+		NextCursor string             `json:"next_cursor,omitempty" doc:"Cursor of the next page, absent on the last page"`
+	}
+}
+
+func CreateRomancesListResponseFromRomancesPage(page entity.RomancesPage) *RomancesListResponse {
+	resp := &RomancesListResponse{}
+	resp.Body.Romances = make([]RomancesListItem, 0, len(page.Romances))
+	resp.Body.NextCursor = page.NextCursor
+
+	for _, romance := range page.Romances {
+		resp.Body.Romances = append(resp.Body.Romances, RomancesListItem{
+			PeerId:         romance.ActiveUserVote.Id.PeerUserId(),
+			PeerCountryId:  romance.ActiveUserVote.Id.PeerCountryId(),
+			ActiveUserVote: createVoteFromVoteEntity(romance.ActiveUserVote),
+			PeerUserVote:   createVoteFromVoteEntity(romance.PeerUserVote),
+			BlockedBy:      getBlockedBy(romance),
+		})
+	}
+
+	return resp
+}
+
+func getBlockedBy(romance entity.Romance) *uuid.UUID {
+	if !romance.IsBlocked() {
+		return nil
+	}
+
+	blockedBy := romance.BlockedBy
+	return &blockedBy
+}
@@ -1,13 +1,19 @@
 package response
 
 import (
+	"errors"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/application/operation"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/entity"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/interface/api/rest/v1/contract"
+	"github.com/google/uuid"
 	"time"
 )
 
 type Vote struct {
 	VoteType  contract.ReadUserVoteType `json:"vote_type"`
+	Message   string                    `json:"message,omitempty" doc:"Text of the compliment, absent for other votes"`
 	VotedAt   *time.Time                `json:"voted_at" doc:"Vote time"`
 	CreatedAt *time.Time                `json:"created_at" doc:"Vote creation time"`
 	UpdatedAt *time.Time                `json:"updated_at" doc:"Vote update time"`
@@ -29,6 +35,7 @@ func CreateVoteGetResponseFromVoteEntity(vote entity.Vote) *VoteGetResponse {
 	return &VoteGetResponse{
 		Body: Vote{
 			VoteType:  contract.ReadUserVoteType(vote.VoteType),
+			Message:   string(vote.Message),
 			VotedAt:   vote.VotedAt,
 			CreatedAt: vote.CreatedAt,
 			UpdatedAt: vote.UpdatedAt,
@@ -40,6 +47,7 @@ func CreateVoteAddResponseFromVoteEntity(vote entity.Vote) *VoteAddResponse {
 	return &VoteAddResponse{
 		Body: Vote{
 			VoteType:  contract.ReadUserVoteType(vote.VoteType),
+			Message:   string(vote.Message),
 			VotedAt:   vote.VotedAt,
 			CreatedAt: vote.CreatedAt,
 			UpdatedAt: vote.UpdatedAt,
@@ -51,9 +59,85 @@ func CreateChangeVoteResponseFromVoteEntity(vote entity.Vote) *ChangeVoteRespons
 	return &ChangeVoteResponse{
 		Body: Vote{
 			VoteType:  contract.ReadUserVoteType(vote.VoteType),
+			Message:   string(vote.Message),
 			VotedAt:   vote.VotedAt,
 			CreatedAt: vote.CreatedAt,
 			UpdatedAt: vote.UpdatedAt,
 		},
 	}
 }
+
+func createVoteFromVoteEntity(vote entity.Vote) Vote {
+	return Vote{
+		VoteType:  contract.ReadUserVoteType(vote.VoteType),
+		Message:   string(vote.Message),
+		VotedAt:   vote.VotedAt,
+		CreatedAt: vote.CreatedAt,
+		UpdatedAt: vote.UpdatedAt,
+	}
+}
+
+const (
+	VoteAddStatusOk              = "ok"
+	VoteAddStatusDuplicate       = "duplicate"
+	VoteAddStatusWrongTransition = "wrong_transition"
+	VoteAddStatusConflict        = "conflict"
+	VoteAddStatusQuotaExceeded   = "quota_exceeded"
+	VoteAddStatusBlocked         = "blocked"
+	VoteAddStatusError           = "error"
+)
+
+type VotesBatchAddItem struct {
+	ActiveUserId  uuid.UUID `json:"active_user_id" format:"uuid" doc:"Active User Id"`
+	PeerId        uuid.UUID `json:"peer_id" format:"uuid" doc:"Peer user ID"`
+	PeerCountryId uint16    `json:"peer_country_id" doc:"Peer user country ID"`
+	Status        string    `json:"status" enum:"ok,duplicate,wrong_transition,conflict,quota_exceeded,blocked,error" doc:"Result of adding the vote"`
+	Error         string    `json:"error,omitempty" doc:"Reason of the failure, absent for added votes"`
+	Vote          *Vote     `json:"vote,omitempty" doc:"Added vote, absent for failed votes"`
+}
+
+type VotesBatchAddResponse struct {
+	Body struct {
+		Results []VotesBatchAddItem `json:"results" doc:"Results in the order of the submitted votes"`
+	}
+}
+
+func CreateVotesBatchAddResponseFromResults(results []operation.AddUserVoteResult) *VotesBatchAddResponse {
+	resp := &VotesBatchAddResponse{}
+	resp.Body.Results = make([]VotesBatchAddItem, 0, len(results))
+
+	for _, result := range results {
+		item := VotesBatchAddItem{
+			ActiveUserId:  result.VoteId.ActiveUserId(),
+			PeerId:        result.VoteId.PeerUserId(),
+			PeerCountryId: result.VoteId.PeerCountryId(),
+		}
+		if result.Err == nil {
+			vote := createVoteFromVoteEntity(result.Vote)
+			item.Status = VoteAddStatusOk
+			item.Vote = &vote
+		} else {
+			item.Status, item.Error = toVoteAddStatus(result.Err)
+		}
+		resp.Body.Results = append(resp.Body.Results, item)
+	}
+
+	return resp
+}
+
+func toVoteAddStatus(err error) (string, string) {
+	switch {
+	case errors.Is(err, romance.ErrVoteDuplicate):
+		return VoteAddStatusDuplicate, err.Error()
+	case errors.Is(err, romance.ErrWrongVote):
+		return VoteAddStatusWrongTransition, err.Error()
+	case errors.Is(err, romance.ErrVersionConflict):
+		return VoteAddStatusConflict, err.Error()
+	case errors.Is(err, quota.ErrQuotaExceeded):
+		return VoteAddStatusQuotaExceeded, err.Error()
+	case errors.Is(err, romance.ErrRomanceBlocked):
+		return VoteAddStatusBlocked, err.Error()
+	default:
+		return VoteAddStatusError, "Internal error"
+	}
+}
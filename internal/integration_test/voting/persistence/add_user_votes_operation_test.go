@@ -0,0 +1,161 @@
+package persistence
+
+import (
+	"context"
+	"github.bumble.dev/shcherbanich/user-votes-storage/config"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/application/operation"
+	quotaValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota/valueobject"
+	romanceDomain "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance"
+	rvo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
+	platformDynamodb "github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform/dynamodb"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/testlib/helper"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type AddUserVotesOperationTestSuite struct {
+	suite.Suite
+	activeUserKey sharedValueObject.ActiveUserKey
+}
+
+func TestAddUserVotesOperationTestSuite(t *testing.T) {
+	suite.Run(t, new(AddUserVotesOperationTestSuite))
+}
+
+func (s *AddUserVotesOperationTestSuite) SetupSuite() {
+	romancesTableHelper, err := helper.NewRomancesTableHelper(ddbClient)
+	s.Require().NoError(err)
+	err = romancesTableHelper.CreateRomancesTable()
+	s.Require().NoError(err)
+
+	countersTableHelper, err := helper.NewCountersTableHelper(ddbClient)
+	s.Require().NoError(err)
+	err = countersTableHelper.CreateCountersTable()
+	s.Require().NoError(err)
+
+	quotasTableHelper, err := helper.NewQuotasTableHelper(ddbClient)
+	s.Require().NoError(err)
+	err = quotasTableHelper.CreateQuotasTable()
+	s.Require().NoError(err)
+}
+
+func (s *AddUserVotesOperationTestSuite) SetupTest() {
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(uint16(11), uuid.New())
+	s.Require().NoError(err)
+	s.activeUserKey = activeUserKey
+}
+
+func (s *AddUserVotesOperationTestSuite) TearDownTest() {
+	err := newRomancesRepository(ddbClient).DeleteUserRomances(context.Background(), s.activeUserKey)
+	s.Require().NoError(err)
+}
+
+func (s *AddUserVotesOperationTestSuite) TestResultsKeepVotesOrderAndStatuses() {
+	ctx := context.Background()
+	addUserVotesOperation, addUserVoteOperation := newAddUserVotesOperation(ddbClient)
+
+	duplicatedVoteId := s.newVoteId()
+	_, err := addUserVoteOperation.Run(ctx, duplicatedVoteId, rvo.VoteTypeYes, "", time.Now())
+	s.Require().NoError(err)
+
+	message, err := rvo.NewComplimentMessage("hello")
+	s.Require().NoError(err)
+
+	votes := []operation.UserVoteToAdd{
+		{VoteId: s.newVoteId(), VoteType: rvo.VoteTypeYes},
+		{VoteId: s.newVoteId(), VoteType: rvo.VoteTypeYes, Message: message},
+		{VoteId: duplicatedVoteId, VoteType: rvo.VoteTypeYes},
+		{VoteId: s.newVoteId(), VoteType: rvo.VoteTypeNo},
+	}
+
+	results := addUserVotesOperation.Run(ctx, votes)
+	s.Require().Len(results, len(votes))
+	for i, result := range results {
+		s.Require().Equal(votes[i].VoteId, result.VoteId)
+	}
+
+	s.Require().NoError(results[0].Err)
+	s.Require().Equal(rvo.VoteTypeYes, results[0].Vote.VoteType)
+
+	s.Require().ErrorIs(results[1].Err, romanceDomain.ErrWrongVote)
+	s.Require().Empty(results[1].Vote)
+
+	s.Require().ErrorIs(results[2].Err, romanceDomain.ErrVoteDuplicate)
+	s.Require().Empty(results[2].Vote)
+
+	s.Require().NoError(results[3].Err)
+	s.Require().Equal(rvo.VoteTypeNo, results[3].Vote.VoteType)
+
+	// the failed votes leave nothing behind while the others are stored
+	repo := newRomancesRepository(ddbClient)
+	for i, expectedVoteType := range []rvo.VoteType{rvo.VoteTypeYes, rvo.VoteTypeEmpty, rvo.VoteTypeYes, rvo.VoteTypeNo} {
+		romance, err := repo.GetRomance(ctx, votes[i].VoteId)
+		s.Require().NoError(err)
+		s.Require().Equal(expectedVoteType, romance.ActiveUserVote.VoteType)
+	}
+}
+
+func (s *AddUserVotesOperationTestSuite) TestDoneContextFailsVotesNotStarted() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	addUserVotesOperation, _ := newAddUserVotesOperation(ddbClient)
+
+	votes := make([]operation.UserVoteToAdd, config.BatchVotesConcurrency+2)
+	for i := range votes {
+		votes[i] = operation.UserVoteToAdd{VoteId: s.newVoteId(), VoteType: rvo.VoteTypeYes}
+	}
+
+	results := addUserVotesOperation.Run(ctx, votes)
+	s.Require().Len(results, len(votes))
+
+	repo := newRomancesRepository(ddbClient)
+	for i, result := range results {
+		s.Require().Equal(votes[i].VoteId, result.VoteId)
+		s.Require().ErrorIs(result.Err, context.Canceled)
+
+		romance, err := repo.GetRomance(context.Background(), votes[i].VoteId)
+		s.Require().NoError(err)
+		s.Require().True(romance.ActiveUserVote.VoteType.IsEmpty())
+	}
+}
+
+func (s *AddUserVotesOperationTestSuite) newVoteId() sharedValueObject.VoteId {
+	voteId, err := sharedValueObject.NewVoteId(s.activeUserKey.CountryId(), s.activeUserKey.ActiveUserId(), s.activeUserKey.CountryId(), uuid.New())
+	s.Require().NoError(err)
+	return voteId
+}
+
+func newAddUserVotesOperation(client platformDynamodb.Client) (operation.AddUserVotesOperation, operation.AddUserVoteOperation) {
+	appConfig := config.Load()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	voteTransitionPolicy, err := rvo.NewVoteTransitionPolicy(appConfig)
+	if err != nil {
+		panic(err)
+	}
+	quotaPolicy, err := quotaValueObject.NewQuotaPolicy(appConfig)
+	if err != nil {
+		panic(err)
+	}
+
+	// the inline counters mode publishes nothing
+	addUserVoteOperation := operation.NewAddUserVoteOperation(
+		newRomancesRepository(client),
+		newCountersRepository(client),
+		voteTransitionPolicy,
+		quotaPolicy,
+		nil,
+		appConfig,
+		platform.NewClock(),
+		logger,
+	)
+
+	return operation.NewAddUserVotesOperation(addUserVoteOperation), addUserVoteOperation
+}
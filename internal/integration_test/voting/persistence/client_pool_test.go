@@ -0,0 +1,89 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"github.bumble.dev/shcherbanich/user-votes-storage/config"
+	platformDynamodb "github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+// unreachableEndpoint is the endpoint of a region nobody listens on, the calls sent there fail to connect
+const unreachableEndpoint = "http://127.0.0.1:1"
+
+type ClientPoolTestSuite struct {
+	suite.Suite
+	pool *platformDynamodb.ClientPool
+}
+
+func TestClientPoolTestSuite(t *testing.T) {
+	suite.Run(t, new(ClientPoolTestSuite))
+}
+
+func (s *ClientPoolTestSuite) SetupSuite() {
+	pool, err := newClientPool(map[string]config.DynamoDbRegionConfig{
+		"us-east-2": {Endpoint: ddbEndpoint},
+		"eu-west-1": {Endpoint: unreachableEndpoint, MaxAttempts: 1},
+	}, "us-east-2")
+	s.Require().NoError(err)
+	s.pool = pool
+}
+
+func (s *ClientPoolTestSuite) TestCallWithoutRegionGoesToDefaultRegion() {
+	_, err := s.pool.DescribeTable(context.Background(), describeMissingTable())
+	s.requireReachedDynamoDb(err)
+}
+
+func (s *ClientPoolTestSuite) TestCallGoesToClientOfItsRegion() {
+	_, err := s.pool.DescribeTable(context.Background(), describeMissingTable(), withRegion("us-east-2"))
+	s.requireReachedDynamoDb(err)
+
+	_, err = s.pool.DescribeTable(context.Background(), describeMissingTable(), withRegion("eu-west-1"))
+	s.Require().Error(err)
+
+	var notFoundErr *types.ResourceNotFoundException
+	s.Require().False(errors.As(err, &notFoundErr), "the call must not reach the default region")
+}
+
+func (s *ClientPoolTestSuite) TestCallToUnknownRegionFails() {
+	_, err := s.pool.DescribeTable(context.Background(), describeMissingTable(), withRegion("ap-south-1"))
+	s.Require().ErrorContains(err, `no dynamodb client for region "ap-south-1"`)
+}
+
+func (s *ClientPoolTestSuite) TestUnknownDefaultRegionIsRejected() {
+	_, err := newClientPool(map[string]config.DynamoDbRegionConfig{
+		"us-east-2": {Endpoint: ddbEndpoint},
+	}, "eu-west-1")
+	s.Require().ErrorContains(err, `default dynamodb region "eu-west-1" is not configured`)
+}
+
+// requireReachedDynamoDb checks that the call got the answer of DynamoDB Local about the missing table.
+func (s *ClientPoolTestSuite) requireReachedDynamoDb(err error) {
+	var notFoundErr *types.ResourceNotFoundException
+	s.Require().ErrorAs(err, &notFoundErr)
+}
+
+func newClientPool(regions map[string]config.DynamoDbRegionConfig, defaultRegion string) (*platformDynamodb.ClientPool, error) {
+	appConfig := config.Load()
+	appConfig.DynamoDbRouting.DefaultRegion = defaultRegion
+	appConfig.DynamoDbRouting.Regions = regions
+
+	return platformDynamodb.NewClientPool(appConfig, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func describeMissingTable() *dynamodb.DescribeTableInput {
+	return &dynamodb.DescribeTableInput{TableName: aws.String("missing_" + uuid.NewString())}
+}
+
+func withRegion(region string) func(*dynamodb.Options) {
+	return func(o *dynamodb.Options) {
+		o.Region = region
+	}
+}
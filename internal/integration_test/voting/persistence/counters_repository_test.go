@@ -2,20 +2,30 @@ package persistence
 
 import (
 	"context"
+	"errors"
 	"github.bumble.dev/shcherbanich/user-votes-storage/config"
+	counterDomain "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter"
 	counterEntity "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/entity"
 	countersRepository "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/repository"
+	countersValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/valueobject"
+	romancesValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
 	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
 	infraDynamodb "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/infrastructure/persistence"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
 	platformDynamodb "github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform/dynamodb"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/timeutil"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/testlib"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/testlib/helper"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/testlib/mocks"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
 	"io"
 	"log/slog"
 	"testing"
+	"time"
 )
 
 type CountersRepositoryTestSuite struct {
@@ -61,10 +71,680 @@ func (s *CountersRepositoryTestSuite) TestGetEmptyLifetimeCounter() {
 	s.assertEmptyCountersGroup(s.activeUserKey, countersGroup)
 }
 
-func newCountersRepository(client platformDynamodb.Client) countersRepository.CountersRepository {
+func (s *CountersRepositoryTestSuite) TestTransferAndDecrCounters() {
+	ctx := context.Background()
+	repo := newCountersRepository(ddbClient)
+
+	voteId, err := sharedValueObject.NewVoteId(s.activeUserKey.CountryId(), s.activeUserKey.ActiveUserId(), s.activeUserKey.CountryId(), uuid.New())
+	s.Require().NoError(err)
+
+	counterUpdateGroup, err := countersValueObject.NewCounterUpdateGroup(time.Now())
+	s.Require().NoError(err)
+
+	// step 1: Counting a NO vote and moving it to YES
+	repo.IncrNoCounters(ctx, voteId, counterUpdateGroup)
+	repo.TransferNoToYesCounters(ctx, voteId, counterUpdateGroup)
+
+	countersGroup, err := repo.GetLifetimeCounter(ctx, s.activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(1), countersGroup.OutgoingYes)
+	s.Require().Equal(uint32(0), countersGroup.OutgoingNo)
+
+	hourlyCounters, err := repo.GetHourlyCounters(ctx, s.activeUserKey, s.newHoursOffsetGroups(1))
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(1), hourlyCounters[1].OutgoingYes)
+	s.Require().Equal(uint32(0), hourlyCounters[1].OutgoingNo)
+
+	// step 2: Deleting the vote twice never takes the counters below zero
+	repo.DecrYesCounters(ctx, voteId, counterUpdateGroup)
+	repo.DecrYesCounters(ctx, voteId, counterUpdateGroup)
+	repo.DecrNoCounters(ctx, voteId, counterUpdateGroup)
+
+	countersGroup, err = repo.GetLifetimeCounter(ctx, s.activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(0), countersGroup.OutgoingYes)
+	s.Require().Equal(uint32(0), countersGroup.OutgoingNo)
+
+	peerUserKey, err := sharedValueObject.NewActiveUserKey(voteId.CountryId(), voteId.PeerUserId())
+	s.Require().NoError(err)
+	peerCountersGroup, err := repo.GetLifetimeCounter(ctx, peerUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(0), peerCountersGroup.IncomingYes)
+	s.Require().Equal(uint32(0), peerCountersGroup.IncomingNo)
+
+	// step 3: Transferring a vote which was never counted only counts the new vote
+	repo.TransferNoToYesCounters(ctx, voteId, counterUpdateGroup)
+
+	countersGroup, err = repo.GetLifetimeCounter(ctx, s.activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(1), countersGroup.OutgoingYes)
+	s.Require().Equal(uint32(0), countersGroup.OutgoingNo)
+
+	err = s.countersTableHelper.DeleteAllUserRecords(peerUserKey)
+	s.Require().NoError(err)
+}
+
+func (s *CountersRepositoryTestSuite) TestReplaceLifetimeCounter() {
+	ctx := context.Background()
+	repo := newCountersRepository(ddbClient)
+
+	voteId, err := sharedValueObject.NewVoteId(s.activeUserKey.CountryId(), s.activeUserKey.ActiveUserId(), s.activeUserKey.CountryId(), uuid.New())
+	s.Require().NoError(err)
+
+	counterUpdateGroup, err := countersValueObject.NewCounterUpdateGroup(time.Now())
+	s.Require().NoError(err)
+
+	// step 1: Replacing the counters which do not exist yet
+	reconciled := counterEntity.CountersGroup{ActiveUserKey: s.activeUserKey, OutgoingYes: 2, IncomingNo: 1}
+	err = repo.ReplaceLifetimeCounter(ctx, counterEntity.CountersGroup{ActiveUserKey: s.activeUserKey}, reconciled)
+	s.Require().NoError(err)
+
+	countersGroup, err := repo.GetLifetimeCounter(ctx, s.activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(reconciled, countersGroup)
+
+	// step 2: A vote counted in between makes the replacement fail
+	repo.IncrNoCounters(ctx, voteId, counterUpdateGroup)
+
+	err = repo.ReplaceLifetimeCounter(ctx, reconciled, counterEntity.CountersGroup{ActiveUserKey: s.activeUserKey})
+	s.Require().ErrorIs(err, counterDomain.ErrCountersChanged)
+
+	countersGroup, err = repo.GetLifetimeCounter(ctx, s.activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(2), countersGroup.OutgoingYes)
+	s.Require().Equal(uint32(1), countersGroup.OutgoingNo)
+
+	peerUserKey, err := sharedValueObject.NewActiveUserKey(voteId.CountryId(), voteId.PeerUserId())
+	s.Require().NoError(err)
+	err = s.countersTableHelper.DeleteAllUserRecords(peerUserKey)
+	s.Require().NoError(err)
+}
+
+func (s *CountersRepositoryTestSuite) TestShardedCounters() {
+	ctx := context.Background()
+
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(s.activeUserKey.CountryId(), uuid.New())
+	s.Require().NoError(err)
+
+	appConfig := config.Load()
+	appConfig.Counters.Shards.Users = map[uuid.UUID]uint16{activeUserKey.ActiveUserId(): 4}
+	repo := newCountersRepositoryWithConfig(ddbClient, appConfig)
+
+	counterUpdateGroup, err := countersValueObject.NewCounterUpdateGroup(time.Now())
+	s.Require().NoError(err)
+
+	votesCount := 20
+	voteIds := make([]sharedValueObject.VoteId, votesCount)
+	for i := range voteIds {
+		voteIds[i], err = sharedValueObject.NewVoteId(activeUserKey.CountryId(), activeUserKey.ActiveUserId(), activeUserKey.CountryId(), uuid.New())
+		s.Require().NoError(err)
+	}
+
+	// step 1: The votes spread over the shards are summed up on read
+	for _, voteId := range voteIds {
+		repo.IncrYesCounters(ctx, voteId, counterUpdateGroup)
+	}
+
+	countersGroup, err := repo.GetLifetimeCounter(ctx, activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(votesCount), countersGroup.OutgoingYes)
+	s.Require().Equal(int32(infraDynamodb.LifetimeCounterKey), countersGroup.HourUnixTimestamp)
+
+	hourlyCounters, err := repo.GetHourlyCounters(ctx, activeUserKey, s.newHoursOffsetGroups(1))
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(votesCount), hourlyCounters[1].OutgoingYes)
+
+	series, err := repo.GetHourlyCountersSeries(ctx, activeUserKey, 1)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(votesCount), series[0].OutgoingYes)
+
+	dailyCounters, err := repo.GetPeriodCounters(ctx, activeUserKey, countersValueObject.CountersPeriodDay, 1)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(votesCount), dailyCounters[0].OutgoingYes)
+
+	// step 2: Every decrement finds a shard holding a vote
+	for _, voteId := range voteIds[:votesCount-1] {
+		repo.DecrYesCounters(ctx, voteId, counterUpdateGroup)
+	}
+
+	countersGroup, err = repo.GetLifetimeCounter(ctx, activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(1), countersGroup.OutgoingYes)
+
+	// step 3: The reconciled counters are spread over all the shards
+	reconciled := counterEntity.CountersGroup{ActiveUserKey: activeUserKey, OutgoingYes: 5}
+	err = repo.ReplaceLifetimeCounter(ctx, countersGroup, reconciled)
+	s.Require().NoError(err)
+
+	countersGroup, err = repo.GetLifetimeCounter(ctx, activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(reconciled, countersGroup)
+
+	for _, voteId := range voteIds[:4] {
+		repo.DecrYesCounters(ctx, voteId, counterUpdateGroup)
+	}
+	countersGroup, err = repo.GetLifetimeCounter(ctx, activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(1), countersGroup.OutgoingYes)
+
+	// step 4: Fewer shards of the user drop the shards left over
+	appConfig.Counters.Shards.Users = map[uuid.UUID]uint16{activeUserKey.ActiveUserId(): 2}
+	repo = newCountersRepositoryWithConfig(ddbClient, appConfig)
+
+	reconciled.OutgoingYes = 3
+	err = repo.ReplaceLifetimeCounter(ctx, countersGroup, reconciled)
+	s.Require().NoError(err)
+
+	countersGroup, err = repo.GetLifetimeCounter(ctx, activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(reconciled, countersGroup)
+
+	err = s.countersTableHelper.DeleteAllUserRecords(activeUserKey)
+	s.Require().NoError(err)
+	for _, voteId := range voteIds {
+		peerUserKey, err := sharedValueObject.NewActiveUserKey(voteId.PeerCountryId(), voteId.PeerUserId())
+		s.Require().NoError(err)
+		err = s.countersTableHelper.DeleteAllUserRecords(peerUserKey)
+		s.Require().NoError(err)
+	}
+}
+
+func (s *CountersRepositoryTestSuite) TestDecrementSpanningShards() {
+	ctx := context.Background()
+
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(s.activeUserKey.CountryId(), uuid.New())
+	s.Require().NoError(err)
+	peerUserKey, err := sharedValueObject.NewActiveUserKey(s.activeUserKey.CountryId(), uuid.New())
+	s.Require().NoError(err)
+	voteId, err := sharedValueObject.NewVoteId(activeUserKey.CountryId(), activeUserKey.ActiveUserId(), peerUserKey.CountryId(), peerUserKey.ActiveUserId())
+	s.Require().NoError(err)
+
 	appConfig := config.Load()
+	appConfig.Counters.Shards.Users = map[uuid.UUID]uint16{activeUserKey.ActiveUserId(): 4}
+	repo := newCountersRepositoryWithConfig(ddbClient, appConfig)
+
+	counterUpdateGroup, err := countersValueObject.NewCounterUpdateGroup(time.Now())
+	s.Require().NoError(err)
+
+	votesCount := 20
+	for range votesCount {
+		repo.IncrYesCounters(ctx, voteId, counterUpdateGroup)
+	}
+
+	// step 1: A single decrement takes the votes of several shards
+	repo.ChangeVoteCounters(ctx, voteId, countersValueObject.NewVoteCountersChange(counterUpdateGroup, int32(1-votesCount), 1))
+
+	countersGroup, err := repo.GetLifetimeCounter(ctx, activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(1), countersGroup.OutgoingYes)
+	s.Require().Equal(uint32(1), countersGroup.OutgoingNo)
+
+	dailyCounters, err := repo.GetPeriodCounters(ctx, activeUserKey, countersValueObject.CountersPeriodDay, 1)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(1), dailyCounters[0].OutgoingYes)
+
+	// step 2: The decrement taking more than the counters hold stops at zero and keeps the increments
+	repo.ChangeVoteCounters(ctx, voteId, countersValueObject.NewVoteCountersChange(counterUpdateGroup, -5, 1))
+
+	countersGroup, err = repo.GetLifetimeCounter(ctx, activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(0), countersGroup.OutgoingYes)
+	s.Require().Equal(uint32(2), countersGroup.OutgoingNo)
+
+	peerCountersGroup, err := repo.GetLifetimeCounter(ctx, peerUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(0), peerCountersGroup.IncomingYes)
+	s.Require().Equal(uint32(2), peerCountersGroup.IncomingNo)
+
+	s.Require().NoError(s.countersTableHelper.DeleteAllUserRecords(activeUserKey))
+	s.Require().NoError(s.countersTableHelper.DeleteAllUserRecords(peerUserKey))
+}
+
+func (s *CountersRepositoryTestSuite) TestHourlyCountersSeries() {
+	ctx := context.Background()
+	repo := newCountersRepository(ddbClient)
+
+	voteId, err := sharedValueObject.NewVoteId(s.activeUserKey.CountryId(), s.activeUserKey.ActiveUserId(), s.activeUserKey.CountryId(), uuid.New())
+	s.Require().NoError(err)
+
+	now := time.Now()
+	for _, countedAt := range []time.Time{now, now.Add(-2 * time.Hour)} {
+		counterUpdateGroup, err := countersValueObject.NewCounterUpdateGroup(countedAt)
+		s.Require().NoError(err)
+		repo.IncrYesCounters(ctx, voteId, counterUpdateGroup)
+	}
+
+	series, err := repo.GetHourlyCountersSeries(ctx, s.activeUserKey, 3)
+	s.Require().NoError(err)
+	s.Require().Len(series, 3)
+
+	// the oldest hour comes first and the hour without votes is filled with zeros
+	for i, expectedOutgoingYes := range []uint32{1, 0, 1} {
+		hourStart := timeutil.HourStart(now.Add(time.Duration(i-2) * time.Hour))
+		s.Require().Equal(int32(hourStart.Unix()), series[i].HourUnixTimestamp)
+		s.Require().Equal(expectedOutgoingYes, series[i].OutgoingYes)
+	}
+
+	peerUserKey, err := sharedValueObject.NewActiveUserKey(voteId.PeerCountryId(), voteId.PeerUserId())
+	s.Require().NoError(err)
+	err = s.countersTableHelper.DeleteAllUserRecords(peerUserKey)
+	s.Require().NoError(err)
+}
+
+func (s *CountersRepositoryTestSuite) TestHourlyCountersAt() {
+	ctx := context.Background()
+	repo := newCountersRepository(ddbClient)
+
+	voteId, err := sharedValueObject.NewVoteId(s.activeUserKey.CountryId(), s.activeUserKey.ActiveUserId(), s.activeUserKey.CountryId(), uuid.New())
+	s.Require().NoError(err)
+
+	now := time.Now()
+	for _, countedAt := range []time.Time{now, now.Add(-3 * time.Hour), now.Add(-5 * time.Hour)} {
+		counterUpdateGroup, err := countersValueObject.NewCounterUpdateGroup(countedAt)
+		s.Require().NoError(err)
+		repo.IncrYesCounters(ctx, voteId, counterUpdateGroup)
+	}
+
+	// the votes counted after the reference time are left out
+	hoursOffsetGroups, err := countersValueObject.NewHoursOffsetGroups([]uint8{1, 3}, now.Add(-3*time.Hour), now)
+	s.Require().NoError(err)
+	hourlyCounters, err := repo.GetHourlyCounters(ctx, s.activeUserKey, hoursOffsetGroups)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(1), hourlyCounters[1].OutgoingYes)
+	s.Require().Equal(uint32(2), hourlyCounters[3].OutgoingYes)
+
+	_, err = countersValueObject.NewHoursOffsetGroups([]uint8{1}, now.Add(time.Hour), now)
+	s.Require().Error(err)
+	_, err = countersValueObject.NewHoursOffsetGroups([]uint8{1}, now.Add(-(config.CountersTtlHours+1)*time.Hour), now)
+	s.Require().Error(err)
+
+	peerUserKey, err := sharedValueObject.NewActiveUserKey(voteId.PeerCountryId(), voteId.PeerUserId())
+	s.Require().NoError(err)
+	err = s.countersTableHelper.DeleteAllUserRecords(peerUserKey)
+	s.Require().NoError(err)
+}
+
+func (s *CountersRepositoryTestSuite) TestHourlyCountersAcrossHourBoundary() {
+	ctx := context.Background()
+	clock := testlib.NewFakeClock(timeutil.HourStart(time.Now()).Add(-time.Second))
+	repo := newCountersRepositoryWithClock(ddbClient, config.Load(), clock)
+
+	voteId, err := sharedValueObject.NewVoteId(s.activeUserKey.CountryId(), s.activeUserKey.ActiveUserId(), s.activeUserKey.CountryId(), uuid.New())
+	s.Require().NoError(err)
+
+	// step 1: A vote counted in the last second of the hour
+	counterUpdateGroup, err := countersValueObject.NewCounterUpdateGroup(clock.Now())
+	s.Require().NoError(err)
+	repo.IncrYesCounters(ctx, voteId, counterUpdateGroup)
+
+	series, err := repo.GetHourlyCountersSeries(ctx, s.activeUserKey, 1)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(1), series[0].OutgoingYes)
+
+	// step 2: The next vote falls into the next hour
+	clock.Advance(2 * time.Second)
+	counterUpdateGroup, err = countersValueObject.NewCounterUpdateGroup(clock.Now())
+	s.Require().NoError(err)
+	repo.IncrYesCounters(ctx, voteId, counterUpdateGroup)
+
+	series, err = repo.GetHourlyCountersSeries(ctx, s.activeUserKey, 2)
+	s.Require().NoError(err)
+	s.Require().Len(series, 2)
+	s.Require().Equal(uint32(1), series[0].OutgoingYes)
+	s.Require().Equal(uint32(1), series[1].OutgoingYes)
+	s.Require().Equal(int32(timeutil.HourStart(clock.Now()).Unix()), series[1].HourUnixTimestamp)
+
+	hoursOffsetGroups, err := countersValueObject.NewHoursOffsetGroups([]uint8{1, 2}, time.Time{}, clock.Now())
+	s.Require().NoError(err)
+	hourlyCounters, err := repo.GetHourlyCounters(ctx, s.activeUserKey, hoursOffsetGroups)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(1), hourlyCounters[1].OutgoingYes)
+	s.Require().Equal(uint32(2), hourlyCounters[2].OutgoingYes)
+
+	peerUserKey, err := sharedValueObject.NewActiveUserKey(voteId.PeerCountryId(), voteId.PeerUserId())
+	s.Require().NoError(err)
+	err = s.countersTableHelper.DeleteAllUserRecords(peerUserKey)
+	s.Require().NoError(err)
+}
+
+func (s *CountersRepositoryTestSuite) TestPeriodCounters() {
+	ctx := context.Background()
+	repo := newCountersRepository(ddbClient)
+
+	// a separate user keeps the rollups of the other tests out of the periods
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(s.activeUserKey.CountryId(), uuid.New())
+	s.Require().NoError(err)
+
+	voteId, err := sharedValueObject.NewVoteId(activeUserKey.CountryId(), activeUserKey.ActiveUserId(), activeUserKey.CountryId(), uuid.New())
+	s.Require().NoError(err)
+
+	now := time.Now()
+	counterUpdateGroup, err := countersValueObject.NewCounterUpdateGroup(now)
+	s.Require().NoError(err)
+
+	repo.IncrYesCounters(ctx, voteId, counterUpdateGroup)
+
+	// step 1: The vote is counted in the current day, the older days are empty
+	dailyCounters, err := repo.GetPeriodCounters(ctx, activeUserKey, countersValueObject.CountersPeriodDay, 30)
+	s.Require().NoError(err)
+	s.Require().Len(dailyCounters, 30)
+	s.Require().Equal(timeutil.DayStart(now).Unix(), dailyCounters[0].PeriodStart.Unix())
+	s.Require().Equal(uint32(1), dailyCounters[0].OutgoingYes)
+	s.Require().Equal(timeutil.DayStart(now).AddDate(0, 0, -1).Unix(), dailyCounters[1].PeriodStart.Unix())
+	s.Require().Equal(uint32(0), dailyCounters[1].OutgoingYes)
+
+	// step 2: The vote is counted in the current week
+	weeklyCounters, err := repo.GetPeriodCounters(ctx, activeUserKey, countersValueObject.CountersPeriodWeek, 4)
+	s.Require().NoError(err)
+	s.Require().Len(weeklyCounters, 4)
+	s.Require().Equal(timeutil.WeekStart(now).Unix(), weeklyCounters[0].PeriodStart.Unix())
+	s.Require().Equal(uint32(1), weeklyCounters[0].OutgoingYes)
+
+	// step 3: The peer sees the vote as incoming
+	peerUserKey, err := sharedValueObject.NewActiveUserKey(voteId.PeerCountryId(), voteId.PeerUserId())
+	s.Require().NoError(err)
+	peerDailyCounters, err := repo.GetPeriodCounters(ctx, peerUserKey, countersValueObject.CountersPeriodDay, 1)
+	s.Require().NoError(err)
+	s.Require().Len(peerDailyCounters, 1)
+	s.Require().Equal(uint32(1), peerDailyCounters[0].IncomingYes)
+
+	err = s.countersTableHelper.DeleteAllUserRecords(activeUserKey)
+	s.Require().NoError(err)
+	err = s.countersTableHelper.DeleteAllUserRecords(peerUserKey)
+	s.Require().NoError(err)
+}
+
+func (s *CountersRepositoryTestSuite) TestVoteTypeCountersAndMatches() {
+	ctx := context.Background()
+	repo := newCountersRepository(ddbClient)
+
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(s.activeUserKey.CountryId(), uuid.New())
+	s.Require().NoError(err)
+	peerUserKey, err := sharedValueObject.NewActiveUserKey(s.activeUserKey.CountryId(), uuid.New())
+	s.Require().NoError(err)
+
+	voteId, err := sharedValueObject.NewVoteId(activeUserKey.CountryId(), activeUserKey.ActiveUserId(), peerUserKey.CountryId(), peerUserKey.ActiveUserId())
+	s.Require().NoError(err)
+
+	counterUpdateGroup, err := countersValueObject.NewCounterUpdateGroup(time.Now())
+	s.Require().NoError(err)
+
+	// step 1: A crush is counted both as a positive vote and as a crush
+	repo.ChangeVoteCounters(ctx, voteId, countersValueObject.NewVoteTypeCountersChange(
+		counterUpdateGroup, romancesValueObject.VoteTypeEmpty, romancesValueObject.VoteTypeCrush, romancesValueObject.VoteTypeEmpty,
+	))
+
+	countersGroup, err := repo.GetLifetimeCounter(ctx, activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(1), countersGroup.OutgoingYes)
+	s.Require().Equal(counterEntity.VoteTypeCounters{Crush: 1}, countersGroup.OutgoingByVoteType)
+	s.Require().Equal(uint32(0), countersGroup.Matches)
+
+	// step 2: The positive vote of the peer makes a match for both users
+	repo.ChangeVoteCounters(ctx, voteId.ToPeerVoteId(), countersValueObject.NewVoteTypeCountersChange(
+		counterUpdateGroup, romancesValueObject.VoteTypeEmpty, romancesValueObject.VoteTypeYes, romancesValueObject.VoteTypeCrush,
+	))
+
+	countersGroup, err = repo.GetLifetimeCounter(ctx, activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(counterEntity.VoteTypeCounters{Yes: 1}, countersGroup.IncomingByVoteType)
+	s.Require().Equal(uint32(1), countersGroup.Matches)
+
+	hourlyCounters, err := repo.GetHourlyCounters(ctx, peerUserKey, s.newHoursOffsetGroups(1))
+	s.Require().NoError(err)
+	s.Require().Equal(counterEntity.VoteTypeCounters{Crush: 1}, hourlyCounters[1].IncomingByVoteType)
+	s.Require().Equal(uint32(1), hourlyCounters[1].Matches)
+
+	// step 3: Changing the crush to a no vote breaks the match
+	repo.ChangeVoteCounters(ctx, voteId, countersValueObject.NewVoteTypeCountersChange(
+		counterUpdateGroup, romancesValueObject.VoteTypeCrush, romancesValueObject.VoteTypeNo, romancesValueObject.VoteTypeYes,
+	))
+
+	countersGroup, err = repo.GetLifetimeCounter(ctx, activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(0), countersGroup.OutgoingYes)
+	s.Require().Equal(uint32(1), countersGroup.OutgoingNo)
+	s.Require().Equal(counterEntity.VoteTypeCounters{No: 1}, countersGroup.OutgoingByVoteType)
+	s.Require().Equal(uint32(0), countersGroup.Matches)
+
+	peerCountersGroup, err := repo.GetLifetimeCounter(ctx, peerUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(counterEntity.VoteTypeCounters{No: 1}, peerCountersGroup.IncomingByVoteType)
+	s.Require().Equal(uint32(0), peerCountersGroup.Matches)
+
+	err = s.countersTableHelper.DeleteAllUserRecords(activeUserKey)
+	s.Require().NoError(err)
+	err = s.countersTableHelper.DeleteAllUserRecords(peerUserKey)
+	s.Require().NoError(err)
+}
+
+func (s *CountersRepositoryTestSuite) TestBufferedCounters() {
+	ctx := context.Background()
+
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(s.activeUserKey.CountryId(), uuid.New())
+	s.Require().NoError(err)
+
+	appConfig := config.Load()
+	appConfig.Counters.Buffer = config.CountersBufferConfig{Enabled: true, FlushInterval: time.Hour, MaxSize: 1000}
+	repo := infraDynamodb.NewBufferedCountersRepository(
+		newCountersRepositoryWithConfig(ddbClient, appConfig).(*infraDynamodb.CountersRepository),
+		appConfig,
+		platform.NewMetrics(),
+	)
+
+	counterUpdateGroup, err := countersValueObject.NewCounterUpdateGroup(time.Now())
+	s.Require().NoError(err)
+
+	voteIds := make([]sharedValueObject.VoteId, 3)
+	for i := range voteIds {
+		voteIds[i], err = sharedValueObject.NewVoteId(activeUserKey.CountryId(), activeUserKey.ActiveUserId(), activeUserKey.CountryId(), uuid.New())
+		s.Require().NoError(err)
+	}
+
+	// step 1: The increments are held back until the flush
+	for _, voteId := range voteIds {
+		repo.IncrYesCounters(ctx, voteId, counterUpdateGroup)
+	}
+
+	countersGroup, err := repo.GetLifetimeCounter(ctx, activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(0), countersGroup.OutgoingYes)
+
+	// step 2: A decrement writes out the buffered increments of the users first
+	repo.DecrYesCounters(ctx, voteIds[0], counterUpdateGroup)
+
+	countersGroup, err = repo.GetLifetimeCounter(ctx, activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(len(voteIds)-1), countersGroup.OutgoingYes)
+
+	// step 3: Close writes out everything left and the later increments go straight to the table
+	repo.IncrNoCounters(ctx, voteIds[1], counterUpdateGroup)
+	s.Require().NoError(repo.Close(ctx))
+	repo.IncrNoCounters(ctx, voteIds[2], counterUpdateGroup)
+
+	countersGroup, err = repo.GetLifetimeCounter(ctx, activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(len(voteIds)-1), countersGroup.OutgoingYes)
+	s.Require().Equal(uint32(2), countersGroup.OutgoingNo)
+
+	peerKey, err := sharedValueObject.NewActiveUserKey(voteIds[1].PeerCountryId(), voteIds[1].PeerUserId())
+	s.Require().NoError(err)
+	countersGroup, err = repo.GetLifetimeCounter(ctx, peerKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(1), countersGroup.IncomingYes)
+	s.Require().Equal(uint32(1), countersGroup.IncomingNo)
+
+	err = s.countersTableHelper.DeleteAllUserRecords(activeUserKey)
+	s.Require().NoError(err)
+	for _, voteId := range voteIds {
+		peerKey, err = sharedValueObject.NewActiveUserKey(voteId.PeerCountryId(), voteId.PeerUserId())
+		s.Require().NoError(err)
+		err = s.countersTableHelper.DeleteAllUserRecords(peerKey)
+		s.Require().NoError(err)
+	}
+}
+
+func (s *CountersRepositoryTestSuite) TestBufferedCountersRequeueFailedItems() {
+	ctx := context.Background()
+
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(s.activeUserKey.CountryId(), uuid.New())
+	s.Require().NoError(err)
+	voteId, err := sharedValueObject.NewVoteId(activeUserKey.CountryId(), activeUserKey.ActiveUserId(), activeUserKey.CountryId(), uuid.New())
+	s.Require().NoError(err)
+
+	ctrl := gomock.NewController(s.T())
+	mock := mocks.NewMockClient(ctrl)
+	failed := false
+	mock.EXPECT().UpdateItem(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(
+			ctx context.Context,
+			input *dynamodb.UpdateItemInput,
+			optFns ...func(*dynamodb.Options),
+		) (*dynamodb.UpdateItemOutput, error) {
+			if !failed {
+				failed = true
+				return nil, errors.New("unavailable")
+			}
+			return ddbClient.UpdateItem(ctx, input, optFns...)
+		},
+	).AnyTimes()
+	mock.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(ddbClient.Query).AnyTimes()
+
+	appConfig := config.Load()
+	appConfig.Counters.Buffer = config.CountersBufferConfig{Enabled: true, FlushInterval: time.Hour, MaxSize: 1000}
+	repo := infraDynamodb.NewBufferedCountersRepository(
+		newCountersRepositoryWithConfig(mock, appConfig).(*infraDynamodb.CountersRepository),
+		appConfig,
+		platform.NewMetrics(),
+	)
+
+	counterUpdateGroup, err := countersValueObject.NewCounterUpdateGroup(time.Now())
+	s.Require().NoError(err)
+
+	// the item failing on the flush is put back into the buffer and written on the next one
+	repo.IncrYesCounters(ctx, voteId, counterUpdateGroup)
+	s.Require().NoError(repo.Close(ctx))
+	s.Require().True(failed)
+
+	countersGroup, err := repo.GetLifetimeCounter(ctx, activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(1), countersGroup.OutgoingYes)
+
+	peerUserKey, err := sharedValueObject.NewActiveUserKey(voteId.PeerCountryId(), voteId.PeerUserId())
+	s.Require().NoError(err)
+	countersGroup, err = repo.GetLifetimeCounter(ctx, peerUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(1), countersGroup.IncomingYes)
+
+	err = s.countersTableHelper.DeleteAllUserRecords(activeUserKey)
+	s.Require().NoError(err)
+	err = s.countersTableHelper.DeleteAllUserRecords(peerUserKey)
+	s.Require().NoError(err)
+}
+
+func (s *CountersRepositoryTestSuite) TestApplyVoteCountersChangeOnce() {
+	ctx := context.Background()
+	repo := newCountersRepository(ddbClient)
+
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(s.activeUserKey.CountryId(), uuid.New())
+	s.Require().NoError(err)
+	voteId, err := sharedValueObject.NewVoteId(activeUserKey.CountryId(), activeUserKey.ActiveUserId(), activeUserKey.CountryId(), uuid.New())
+	s.Require().NoError(err)
+	peerUserKey, err := sharedValueObject.NewActiveUserKey(voteId.PeerCountryId(), voteId.PeerUserId())
+	s.Require().NoError(err)
+
+	counterUpdateGroup, err := countersValueObject.NewCounterUpdateGroup(time.Now())
+	s.Require().NoError(err)
+	countersChange := countersValueObject.NewVoteTypeCountersChange(
+		counterUpdateGroup,
+		romancesValueObject.VoteTypeEmpty,
+		romancesValueObject.VoteTypeCrush,
+		romancesValueObject.VoteTypeEmpty,
+	)
+
+	// step 1: A redelivered change is applied once
+	changeId := uuid.New()
+	for range 2 {
+		err = repo.ApplyVoteCountersChange(ctx, changeId, voteId, []countersValueObject.VoteCountersChange{countersChange})
+		s.Require().NoError(err)
+	}
+
+	countersGroup, err := repo.GetLifetimeCounter(ctx, activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(1), countersGroup.OutgoingYes)
+	s.Require().Equal(uint32(1), countersGroup.OutgoingByVoteType.Crush)
+
+	countersGroup, err = repo.GetLifetimeCounter(ctx, peerUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(1), countersGroup.IncomingYes)
+
+	// step 2: Another change of the same vote is applied again
+	err = repo.ApplyVoteCountersChange(ctx, uuid.New(), voteId, []countersValueObject.VoteCountersChange{countersChange})
+	s.Require().NoError(err)
+
+	countersGroup, err = repo.GetLifetimeCounter(ctx, activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(2), countersGroup.OutgoingYes)
+
+	// step 3: A redelivered change with decrements is applied once as well
+	changeId = uuid.New()
+	for range 2 {
+		err = repo.ApplyVoteCountersChange(
+			ctx, changeId, voteId, []countersValueObject.VoteCountersChange{countersValueObject.NewVoteCountersChange(counterUpdateGroup, -1, 0)},
+		)
+		s.Require().NoError(err)
+	}
+
+	countersGroup, err = repo.GetLifetimeCounter(ctx, activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(1), countersGroup.OutgoingYes)
+
+	// step 4: A change failing to land leaves no marker and is applied on the redelivery
+	ctrl := gomock.NewController(s.T())
+	mock := mocks.NewMockClient(ctrl)
+	mock.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(ddbClient.Query).AnyTimes()
+	gomock.InOrder(
+		mock.EXPECT().TransactWriteItems(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("unavailable")),
+		mock.EXPECT().TransactWriteItems(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(ddbClient.TransactWriteItems),
+	)
+	failingRepo := newCountersRepositoryWithConfig(mock, config.Load())
+
+	changeId = uuid.New()
+	decrements := []countersValueObject.VoteCountersChange{countersValueObject.NewVoteCountersChange(counterUpdateGroup, -1, 0)}
+	s.Require().Error(failingRepo.ApplyVoteCountersChange(ctx, changeId, voteId, decrements))
+	s.Require().NoError(failingRepo.ApplyVoteCountersChange(ctx, changeId, voteId, decrements))
+
+	countersGroup, err = repo.GetLifetimeCounter(ctx, activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(0), countersGroup.OutgoingYes)
+
+	err = s.countersTableHelper.DeleteAllUserRecords(activeUserKey)
+	s.Require().NoError(err)
+	err = s.countersTableHelper.DeleteAllUserRecords(peerUserKey)
+	s.Require().NoError(err)
+}
+
+func (s *CountersRepositoryTestSuite) newHoursOffsetGroups(offsets ...uint8) countersValueObject.HoursOffsetGroups {
+	hoursOffsetGroups, err := countersValueObject.NewHoursOffsetGroups(offsets, time.Time{}, time.Now())
+	s.Require().NoError(err)
+	return hoursOffsetGroups
+}
+
+func newCountersRepository(client platformDynamodb.Client) countersRepository.CountersRepository {
+	return newCountersRepositoryWithConfig(client, config.Load())
+}
+
+func newCountersRepositoryWithConfig(client platformDynamodb.Client, appConfig config.Config) countersRepository.CountersRepository {
+	return newCountersRepositoryWithClock(client, appConfig, platform.NewClock())
+}
+
+func newCountersRepositoryWithClock(client platformDynamodb.Client, appConfig config.Config, clock platform.Clock) countersRepository.CountersRepository {
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
-	return infraDynamodb.NewCountersRepository(client, appConfig, logger)
+	regionRouter, err := platformDynamodb.NewRegionRouter(appConfig)
+	if err != nil {
+		panic(err)
+	}
+	return infraDynamodb.NewCountersRepository(client, regionRouter, appConfig, clock, logger)
 }
 
 func (s *CountersRepositoryTestSuite) assertNilCountersGroup(countersGroup counterEntity.CountersGroup) {
@@ -10,7 +10,9 @@ import (
 )
 
 var (
-	ddbClient platformDynamodb.Client
+	ddbClient        platformDynamodb.Client
+	ddbStreamsClient platformDynamodb.StreamsClient
+	ddbEndpoint      string
 )
 
 func TestMain(m *testing.M) {
@@ -19,6 +21,11 @@ func TestMain(m *testing.M) {
 		log.Fatalf("failed to run dynamodb: %v", err)
 	}
 	ddbClient = dynamoDbLocal.Client
+	ddbStreamsClient = dynamoDbLocal.StreamsClient
+	ddbEndpoint, err = dynamoDbLocal.Container.Endpoint(context.Background(), "http")
+	if err != nil {
+		log.Fatalf("failed to get dynamodb endpoint: %v", err)
+	}
 
 	code := m.Run()
 	os.Exit(code)
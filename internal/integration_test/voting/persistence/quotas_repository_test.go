@@ -0,0 +1,81 @@
+package persistence
+
+import (
+	"context"
+	"github.bumble.dev/shcherbanich/user-votes-storage/config"
+	quotasRepository "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota/repository"
+	quotaValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota/valueobject"
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	infraDynamodb "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/infrastructure/persistence"
+	platformDynamodb "github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform/dynamodb"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/testlib/helper"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/testlib/mocks"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type QuotasRepositoryTestSuite struct {
+	suite.Suite
+	activeUserKey sharedValueObject.ActiveUserKey
+}
+
+func TestMyQuotasRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(QuotasRepositoryTestSuite))
+}
+
+func (s *QuotasRepositoryTestSuite) SetupSuite() {
+	quotasTableHelper, err := helper.NewQuotasTableHelper(ddbClient)
+	s.Require().NoError(err)
+
+	err = quotasTableHelper.CreateQuotasTable()
+	s.Require().NoError(err)
+
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(uint16(11), uuid.New())
+	s.Require().NoError(err)
+	s.activeUserKey = activeUserKey
+}
+
+func (s *QuotasRepositoryTestSuite) TestGetEmptyDailyUsage() {
+	repo := newQuotasRepository(ddbClient)
+
+	quotaDay, err := quotaValueObject.NewQuotaDay(time.Now())
+	s.Require().NoError(err)
+
+	usage, err := repo.GetDailyUsage(context.Background(), s.activeUserKey, quotaDay)
+	s.Require().NoError(err)
+	s.Require().Empty(usage)
+}
+
+func (s *QuotasRepositoryTestSuite) TestGetDailyUsageWithDbException() {
+	ctrl := gomock.NewController(s.T())
+	mock := mocks.NewMockClient(ctrl)
+
+	ctx := context.Background()
+	mock.EXPECT().
+		GetItem(ctx, gomock.Any(), gomock.Any()).
+		Return(nil, &types.InvalidEndpointException{})
+
+	quotaDay, err := quotaValueObject.NewQuotaDay(time.Now())
+	s.Require().NoError(err)
+
+	repo := newQuotasRepository(mock)
+	usage, err := repo.GetDailyUsage(ctx, s.activeUserKey, quotaDay)
+	s.Require().Error(err)
+	s.Require().Nil(usage)
+}
+
+func newQuotasRepository(client platformDynamodb.Client) quotasRepository.QuotasRepository {
+	appConfig := config.Load()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	regionRouter, err := platformDynamodb.NewRegionRouter(appConfig)
+	if err != nil {
+		panic(err)
+	}
+	return infraDynamodb.NewQuotasRepository(client, regionRouter, appConfig, logger)
+}
@@ -2,18 +2,27 @@ package persistence
 
 import (
 	"context"
+	"errors"
 	"github.bumble.dev/shcherbanich/user-votes-storage/config"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/application/operation"
+	counterDomain "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter"
+	counterValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/valueobject"
+	quotaDomain "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota"
+	quotaValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota/valueobject"
 	romanceDomain "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance"
 	romanceEntity "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/entity"
 	romanceRepository "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/repository"
 	rvo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
 	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
 	infraDynamodb "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/infrastructure/persistence"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
 	platformDynamodb "github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform/dynamodb"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/timeutil"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/testlib"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/testlib/helper"
 	"github.bumble.dev/shcherbanich/user-votes-storage/internal/testlib/mocks"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -43,11 +52,23 @@ func (s *RomancesRepositoryTestSuite) SetupSuite() {
 	err = s.romancesTableHelper.CreateRomancesTable()
 	s.Require().NoError(err)
 
+	// votes are written together with the counters
+	countersTableHelper, err := helper.NewCountersTableHelper(ddbClient)
+	s.Require().NoError(err)
+	err = countersTableHelper.CreateCountersTable()
+	s.Require().NoError(err)
+
+	// premium votes take a unit of the daily quota in the same transaction
+	quotasTableHelper, err := helper.NewQuotasTableHelper(ddbClient)
+	s.Require().NoError(err)
+	err = quotasTableHelper.CreateQuotasTable()
+	s.Require().NoError(err)
+
 	activeUserId, _ := uuid.NewUUID()
 	peerUserId, _ := uuid.NewUUID()
 	countryId := uint16(11)
 
-	voteId, err := sharedValueObject.NewVoteId(countryId, activeUserId, peerUserId)
+	voteId, err := sharedValueObject.NewVoteId(countryId, activeUserId, countryId, peerUserId)
 	s.Require().NoError(err)
 	s.voteId = voteId
 }
@@ -91,6 +112,135 @@ func (s *RomancesRepositoryTestSuite) TestGetRomanceWithDbException() {
 	s.assertNilRomance(romance)
 }
 
+func (s *RomancesRepositoryTestSuite) TestGetRomances() {
+	ctx := context.Background()
+	repo := newRomancesRepository(ddbClient)
+
+	activeUserId := uuid.New()
+	countryId := s.voteId.CountryId()
+
+	// step 1: Adding votes from both sides of the romances
+	votedVoteId, err := sharedValueObject.NewVoteId(countryId, activeUserId, countryId, uuid.New())
+	s.Require().NoError(err)
+	votedRomance, err := repo.AddActiveUserVoteToRomance(ctx, romanceEntity.CreateEmptyRomance(votedVoteId), rvo.VoteTypeYes, "", time.Now())
+	s.Require().NoError(err)
+
+	likedVoteId, err := sharedValueObject.NewVoteId(countryId, activeUserId, countryId, uuid.New())
+	s.Require().NoError(err)
+	_, err = repo.AddActiveUserVoteToRomance(ctx, romanceEntity.CreateEmptyRomance(likedVoteId.ToPeerVoteId()), rvo.VoteTypeCrush, "", time.Now())
+	s.Require().NoError(err)
+	likedRomance, err := repo.GetRomance(ctx, likedVoteId)
+	s.Require().NoError(err)
+
+	emptyVoteId, err := sharedValueObject.NewVoteId(countryId, activeUserId, countryId, uuid.New())
+	s.Require().NoError(err)
+
+	// step 2: Reading the romances at once, the order and duplicates are kept
+	romances, err := repo.GetRomances(ctx, []sharedValueObject.VoteId{likedVoteId, emptyVoteId, votedVoteId, likedVoteId})
+	s.Require().NoError(err)
+	s.Require().Equal(
+		[]romanceEntity.Romance{likedRomance, romanceEntity.CreateEmptyRomance(emptyVoteId), votedRomance, likedRomance},
+		romances,
+	)
+
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(countryId, activeUserId)
+	s.Require().NoError(err)
+	err = repo.DeleteUserRomances(ctx, activeUserKey)
+	s.Require().NoError(err)
+}
+
+func (s *RomancesRepositoryTestSuite) TestGetRomancesRetriesUnprocessedKeys() {
+	ctrl := gomock.NewController(s.T())
+	mock := mocks.NewMockClient(ctrl)
+
+	ctx := context.Background()
+
+	romanceKey := infraDynamodb.NewRomancePrimaryKey(s.voteId)
+	key := map[string]types.AttributeValue{
+		infraDynamodb.PkUserIdAttrName: &types.AttributeValueMemberS{Value: romanceKey.Pk.String()},
+		infraDynamodb.SkUserIdAttrName: &types.AttributeValueMemberS{Value: romanceKey.Sk.String()},
+	}
+	unprocessed := map[string]types.KeysAndAttributes{
+		infraDynamodb.RomancesTableName: {Keys: []map[string]types.AttributeValue{key}},
+	}
+
+	gomock.InOrder(
+		mock.EXPECT().
+			BatchGetItem(ctx, gomock.Any(), gomock.Any()).
+			Return(&dynamodb.BatchGetItemOutput{UnprocessedKeys: unprocessed}, nil),
+		mock.EXPECT().
+			BatchGetItem(ctx, &dynamodb.BatchGetItemInput{RequestItems: unprocessed}, gomock.Any()).
+			Return(&dynamodb.BatchGetItemOutput{}, nil),
+	)
+
+	repo := newRomancesRepository(mock)
+
+	romances, err := repo.GetRomances(ctx, []sharedValueObject.VoteId{s.voteId})
+	s.Require().NoError(err)
+	s.Require().Equal([]romanceEntity.Romance{romanceEntity.CreateEmptyRomance(s.voteId)}, romances)
+}
+
+func (s *RomancesRepositoryTestSuite) TestScanRomanceUsers() {
+	ctx := context.Background()
+	repo := newRomancesRepository(ddbClient)
+
+	voteId, err := sharedValueObject.NewVoteId(s.voteId.CountryId(), uuid.New(), s.voteId.CountryId(), uuid.New())
+	s.Require().NoError(err)
+	_, err = repo.AddActiveUserVoteToRomance(ctx, romanceEntity.CreateEmptyRomance(voteId), rvo.VoteTypeYes, "", time.Now())
+	s.Require().NoError(err)
+
+	// the romance within a country keeps the countries of its users as well
+	regionRouter, err := platformDynamodb.NewRegionRouter(config.Load())
+	s.Require().NoError(err)
+	record, err := s.romancesTableHelper.GetRomanceTableRecord(
+		infraDynamodb.NewRomancePrimaryKey(voteId),
+		regionRouter.RegionByCountry(voteId.CountryId()),
+	)
+	s.Require().NoError(err)
+	s.Require().Equal(voteId.CountryId(), record.PkUserCountryId)
+	s.Require().Equal(voteId.CountryId(), record.SkUserCountryId)
+
+	crossCountryVoteId, err := sharedValueObject.NewVoteId(44, uuid.New(), 33, uuid.New())
+	s.Require().NoError(err)
+	_, err = repo.AddActiveUserVoteToRomance(ctx, romanceEntity.CreateEmptyRomance(crossCountryVoteId), rvo.VoteTypeYes, "", time.Now())
+	s.Require().NoError(err)
+
+	// the users of the romances are found in one of the segments with the countries stored in the romances
+	found := map[uuid.UUID]uint16{}
+	totalSegments := int32(2)
+	for segment := int32(0); segment < totalSegments; segment++ {
+		cursor := ""
+		for {
+			pageRequest, err := sharedValueObject.NewPageRequest(2, cursor)
+			s.Require().NoError(err)
+
+			page, err := repo.ScanRomanceUsers(ctx, voteId.CountryId(), segment, totalSegments, pageRequest)
+			s.Require().NoError(err)
+
+			for _, userKey := range page.UserKeys {
+				found[userKey.ActiveUserId()] = userKey.CountryId()
+			}
+
+			if !page.HasMore() {
+				break
+			}
+			cursor = page.NextCursor
+		}
+	}
+	s.Require().Equal(voteId.CountryId(), found[voteId.ActiveUserId()])
+	s.Require().Equal(voteId.CountryId(), found[voteId.PeerUserId()])
+	s.Require().Equal(uint16(44), found[crossCountryVoteId.ActiveUserId()])
+	s.Require().Equal(uint16(33), found[crossCountryVoteId.PeerUserId()])
+
+	_, err = repo.ScanRomanceUsers(ctx, voteId.CountryId(), totalSegments, totalSegments, sharedValueObject.PageRequest{})
+	s.Require().Error(err)
+
+	err = repo.DeleteRomance(ctx, voteId)
+	s.Require().NoError(err)
+	err = repo.DeleteRomance(ctx, crossCountryVoteId)
+	s.Require().NoError(err)
+}
+
 func (s *RomancesRepositoryTestSuite) TestAddVoteToEmptyRomance() {
 	ctx := context.Background()
 	repo := newRomancesRepository(ddbClient)
@@ -98,296 +248,1318 @@ func (s *RomancesRepositoryTestSuite) TestAddVoteToEmptyRomance() {
 	romance := romanceEntity.CreateEmptyRomance(s.voteId)
 
 	// Adding a YES vote for the active user
-	newRomance, err := repo.AddActiveUserVoteToRomance(ctx, romance, rvo.VoteTypeYes, time.Now())
+	newRomance, err := repo.AddActiveUserVoteToRomance(ctx, romance, rvo.VoteTypeYes, "", time.Now())
+	s.Require().NoError(err)
+	s.assertRomanceInDbMatchesExpected(
+		newExpectedRomanceParams(s.voteId, rvo.VoteTypeYes, rvo.VoteTypeEmpty, 1),
+		newRomance,
+	)
+}
+
+func (s *RomancesRepositoryTestSuite) TestGetAndAddRomanceVote() {
+	ctx := context.Background()
+	repo := newRomancesRepository(ddbClient)
+
+	// step 1: Receiving an empty romance object
+	romance, err := repo.GetRomance(ctx, s.voteId)
+	s.Require().NoError(err)
+	s.assertRomanceInDbMatchesExpected(
+		newExpectedRomanceParams(s.voteId, rvo.VoteTypeEmpty, rvo.VoteTypeEmpty, 0),
+		romance,
+	)
+
+	// step 2: Adding a YES vote for the active user
+	newRomance, err := repo.AddActiveUserVoteToRomance(ctx, romance, rvo.VoteTypeYes, "", time.Now())
 	s.Require().NoError(err)
 	s.assertRomanceInDbMatchesExpected(
 		newExpectedRomanceParams(s.voteId, rvo.VoteTypeYes, rvo.VoteTypeEmpty, 1),
 		newRomance,
 	)
+
+	// step 3: Getting a romance from the repo (active user side) and checking if it contains the expected values
+	romance, err = repo.GetRomance(ctx, s.voteId)
+	s.Require().NoError(err)
+	assert.Equal(s.T(), newRomance, romance)
+
+	// step 4: Getting a romance from the repo (peer user side) and checking if it contains the expected values
+	peerVoteId := s.voteId.ToPeerVoteId()
+	peerRomance, err := repo.GetRomance(ctx, peerVoteId)
+	s.Require().NoError(err)
+	s.assertRomanceInDbMatchesExpected(
+		newExpectedRomanceParams(peerVoteId, rvo.VoteTypeEmpty, rvo.VoteTypeYes, 1),
+		peerRomance,
+	)
+
+	// step 5: Adding a new NO vote from the peer side
+	newPeerRomance, err := repo.AddActiveUserVoteToRomance(ctx, peerRomance, rvo.VoteTypeNo, "", time.Now())
+	s.Require().NoError(err)
+	s.assertRomanceInDbMatchesExpected(
+		newExpectedRomanceParams(peerVoteId, rvo.VoteTypeNo, rvo.VoteTypeYes, 2),
+		newPeerRomance,
+	)
+
+	// step 6: Comparison of the romance received after the update and from GetRomance method
+	peerRomance, err = repo.GetRomance(ctx, s.voteId.ToPeerVoteId())
+	s.Require().NoError(err)
+	assert.Equal(s.T(), newPeerRomance, peerRomance)
+}
+
+func (s *RomancesRepositoryTestSuite) TestAddVoteAndRewrite() {
+	ctx := context.Background()
+	repo := newRomancesRepository(ddbClient)
+
+	now := time.Now()
+
+	activeUserVoteId := s.voteId
+	peerVoteId := s.voteId.ToPeerVoteId()
+
+	// step 1: Adding a NO vote for the peer user
+	peerRomance := romanceEntity.CreateEmptyRomance(peerVoteId)
+	newPeerRomance, err := repo.AddActiveUserVoteToRomance(ctx, peerRomance, rvo.VoteTypeYes, "", now)
+	s.Require().NoError(err)
+	s.assertRomanceInDbMatchesExpected(
+		newExpectedRomanceParams(peerVoteId, rvo.VoteTypeYes, rvo.VoteTypeEmpty, 1),
+		newPeerRomance,
+	)
+
+	// step 2: Rewriting peer vote
+	// There is no check at the persistence level for which vote we are inserting,
+	// so there may be a NO after YES
+	newPeerRomance, err = repo.AddActiveUserVoteToRomance(ctx, newPeerRomance, rvo.VoteTypeNo, "", now)
+	s.Require().NoError(err)
+	s.assertRomanceInDbMatchesExpected(
+		newExpectedRomanceParams(peerVoteId, rvo.VoteTypeNo, rvo.VoteTypeEmpty, 2),
+		newPeerRomance,
+	)
+
+	// step 3: Get the same romance from the active user side
+	romance, err := repo.GetRomance(ctx, activeUserVoteId)
+	s.Require().NoError(err)
+	s.assertRomanceInDbMatchesExpected(
+		newExpectedRomanceParams(activeUserVoteId, rvo.VoteTypeEmpty, rvo.VoteTypeNo, 2),
+		romance,
+	)
+
+	// step 4: Adding a YES vote to the active user romance
+	newRomance, err := repo.AddActiveUserVoteToRomance(ctx, romance, rvo.VoteTypeYes, "", now)
+	s.Require().NoError(err)
+	s.assertRomanceInDbMatchesExpected(
+		newExpectedRomanceParams(activeUserVoteId, rvo.VoteTypeYes, rvo.VoteTypeNo, 3),
+		newRomance,
+	)
+
+	// step 5: Adding a NO vote for the active user
+	newRomance, err = repo.AddActiveUserVoteToRomance(ctx, newRomance, rvo.VoteTypeNo, "", now)
+	s.Require().NoError(err)
+	s.assertRomanceInDbMatchesExpected(
+		newExpectedRomanceParams(activeUserVoteId, rvo.VoteTypeNo, rvo.VoteTypeNo, 4),
+		newRomance,
+	)
+}
+
+func (s *RomancesRepositoryTestSuite) TestAddWithVersionConflictError() {
+	ctx := context.Background()
+	repo := newRomancesRepository(ddbClient)
+
+	now := time.Now()
+
+	// step 1: Creating romance with wrong version (the version is not synchronized with the db)
+	romance := romanceEntity.CreateEmptyRomance(s.voteId)
+	romance.Version = 10
+
+	// step 2: Adding vote
+	newRomance, err := repo.AddActiveUserVoteToRomance(ctx, romance, rvo.VoteTypeYes, "", now)
+	s.Require().Error(err)
+	s.Require().ErrorIs(err, romanceDomain.ErrVersionConflict)
+	s.assertNilRomance(newRomance)
+}
+
+func (s *RomancesRepositoryTestSuite) TestAddVoteWithCounters() {
+	ctx := context.Background()
+	repo := newRomancesRepository(ddbClient)
+	countersRepo := newCountersRepository(ddbClient)
+
+	voteId, err := sharedValueObject.NewVoteId(s.voteId.CountryId(), uuid.New(), s.voteId.CountryId(), uuid.New())
+	s.Require().NoError(err)
+
+	counterUpdateGroup, err := counterValueObject.NewCounterUpdateGroup(time.Now())
+	s.Require().NoError(err)
+
+	// step 1: Adding a YES vote together with the counters
+	romance, err := repo.AddActiveUserVoteToRomanceWithCounters(
+		ctx,
+		romanceEntity.CreateEmptyRomance(voteId),
+		rvo.VoteTypeYes,
+		"",
+		time.Now(),
+		uuid.New(),
+		[]counterValueObject.VoteCountersChange{counterValueObject.NewVoteCountersChange(counterUpdateGroup, 1, 0)},
+		nil,
+	)
+	s.Require().NoError(err)
+	s.assertRomanceInDbMatchesExpected(
+		newExpectedRomanceParams(voteId, rvo.VoteTypeYes, rvo.VoteTypeEmpty, 1),
+		romance,
+	)
+
+	// step 2: The returned romance is the stored one
+	storedRomance, err := repo.GetRomance(ctx, voteId)
+	s.Require().NoError(err)
+	s.Require().Equal(storedRomance, romance)
+
+	// step 3: Writing the stale romance fails and leaves the counters untouched
+	_, err = repo.AddActiveUserVoteToRomanceWithCounters(
+		ctx,
+		romanceEntity.CreateEmptyRomance(voteId),
+		rvo.VoteTypeNo,
+		"",
+		time.Now(),
+		uuid.New(),
+		[]counterValueObject.VoteCountersChange{counterValueObject.NewVoteCountersChange(counterUpdateGroup, 0, 1)},
+		nil,
+	)
+	s.Require().ErrorIs(err, romanceDomain.ErrVersionConflict)
+
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(voteId.CountryId(), voteId.ActiveUserId())
+	s.Require().NoError(err)
+	activeUserCounters, err := countersRepo.GetLifetimeCounter(ctx, activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(1), activeUserCounters.OutgoingYes)
+	s.Require().Equal(uint32(0), activeUserCounters.OutgoingNo)
+
+	peerUserKey, err := sharedValueObject.NewActiveUserKey(voteId.CountryId(), voteId.PeerUserId())
+	s.Require().NoError(err)
+	peerUserCounters, err := countersRepo.GetLifetimeCounter(ctx, peerUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(1), peerUserCounters.IncomingYes)
+	s.Require().Equal(uint32(0), peerUserCounters.IncomingNo)
+
+	err = repo.DeleteRomance(ctx, voteId)
+	s.Require().NoError(err)
+}
+
+func (s *RomancesRepositoryTestSuite) TestAddVoteWithCountersWithCanceledTransaction() {
+	ctrl := gomock.NewController(s.T())
+	mock := mocks.NewMockClient(ctrl)
+
+	ctx := context.Background()
+
+	counterUpdateGroup, err := counterValueObject.NewCounterUpdateGroup(time.Now())
+	s.Require().NoError(err)
+
+	mock.EXPECT().
+		TransactWriteItems(ctx, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, in *dynamodb.TransactWriteItemsInput, _ ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+			// the romance update and the hourly, daily, weekly and lifetime counters of both users
+			s.Require().Len(in.TransactItems, 9)
+			cancellationReasons := []types.CancellationReason{{Code: aws.String("ConditionalCheckFailed")}}
+			for range in.TransactItems[1:] {
+				cancellationReasons = append(cancellationReasons, types.CancellationReason{Code: aws.String("None")})
+			}
+			return nil, &types.TransactionCanceledException{CancellationReasons: cancellationReasons}
+		})
+
+	repo := newRomancesRepository(mock)
+
+	romance, err := repo.AddActiveUserVoteToRomanceWithCounters(
+		ctx,
+		romanceEntity.CreateEmptyRomance(s.voteId),
+		rvo.VoteTypeYes,
+		"",
+		time.Now(),
+		uuid.New(),
+		[]counterValueObject.VoteCountersChange{counterValueObject.NewVoteCountersChange(counterUpdateGroup, 1, 0)},
+		nil,
+	)
+	s.Require().ErrorIs(err, romanceDomain.ErrVersionConflict)
+	s.assertNilRomance(romance)
+}
+
+func (s *RomancesRepositoryTestSuite) TestAddVoteWithQuota() {
+	ctx := context.Background()
+	repo := newRomancesRepository(ddbClient)
+	quotasRepo := newQuotasRepository(ddbClient)
+
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(s.voteId.CountryId(), uuid.New())
+	s.Require().NoError(err)
+
+	quotaDay, err := quotaValueObject.NewQuotaDay(time.Now())
+	s.Require().NoError(err)
+	counterUpdateGroup, err := counterValueObject.NewCounterUpdateGroup(time.Now())
+	s.Require().NoError(err)
+
+	consumption := quotaValueObject.NewQuotaConsumption(activeUserKey, quotaDay, rvo.VoteTypeCrush, 2)
+
+	// step 1: Every crush within the limit takes a unit of the quota
+	for i := 0; i < 2; i++ {
+		voteId, err := sharedValueObject.NewVoteId(activeUserKey.CountryId(), activeUserKey.ActiveUserId(), activeUserKey.CountryId(), uuid.New())
+		s.Require().NoError(err)
+
+		_, err = repo.AddActiveUserVoteToRomanceWithCounters(
+			ctx,
+			romanceEntity.CreateEmptyRomance(voteId),
+			rvo.VoteTypeCrush,
+			"",
+			time.Now(),
+			uuid.New(),
+			[]counterValueObject.VoteCountersChange{counterValueObject.NewVoteCountersChange(counterUpdateGroup, 1, 0)},
+			&consumption,
+		)
+		s.Require().NoError(err)
+	}
+
+	usage, err := quotasRepo.GetDailyUsage(ctx, activeUserKey, quotaDay)
+	s.Require().NoError(err)
+	s.Require().Equal(map[rvo.VoteType]uint32{rvo.VoteTypeCrush: 2}, usage)
+
+	// step 2: The crush over the limit is rejected and the romance is not written
+	voteId, err := sharedValueObject.NewVoteId(activeUserKey.CountryId(), activeUserKey.ActiveUserId(), activeUserKey.CountryId(), uuid.New())
+	s.Require().NoError(err)
+
+	romance, err := repo.AddActiveUserVoteToRomanceWithCounters(
+		ctx,
+		romanceEntity.CreateEmptyRomance(voteId),
+		rvo.VoteTypeCrush,
+		"",
+		time.Now(),
+		uuid.New(),
+		[]counterValueObject.VoteCountersChange{counterValueObject.NewVoteCountersChange(counterUpdateGroup, 1, 0)},
+		&consumption,
+	)
+	s.Require().ErrorIs(err, quotaDomain.ErrQuotaExceeded)
+	s.assertNilRomance(romance)
+
+	storedRomance, err := repo.GetRomance(ctx, voteId)
+	s.Require().NoError(err)
+	s.Require().True(storedRomance.IsEmpty())
+
+	usage, err = quotasRepo.GetDailyUsage(ctx, activeUserKey, quotaDay)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(2), usage[rvo.VoteTypeCrush])
+}
+
+func (s *RomancesRepositoryTestSuite) TestChangeVoteWithQuota() {
+	ctx := context.Background()
+	repo := newRomancesRepository(ddbClient)
+	quotasRepo := newQuotasRepository(ddbClient)
+
+	voteId, err := sharedValueObject.NewVoteId(s.voteId.CountryId(), uuid.New(), s.voteId.CountryId(), uuid.New())
+	s.Require().NoError(err)
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(voteId.CountryId(), voteId.ActiveUserId())
+	s.Require().NoError(err)
+
+	quotaDay, err := quotaValueObject.NewQuotaDay(time.Now())
+	s.Require().NoError(err)
+	consumption := quotaValueObject.NewQuotaConsumption(activeUserKey, quotaDay, rvo.VoteTypeCompliment, 1)
+
+	romance, err := repo.AddActiveUserVoteToRomance(ctx, romanceEntity.CreateEmptyRomance(voteId), rvo.VoteTypeYes, "", time.Now())
+	s.Require().NoError(err)
+
+	// step 1: Changing the vote takes a unit of the quota
+	changedRomance, err := repo.ChangeActiveUserVoteTypeInRomanceWithCounters(ctx, romance, rvo.VoteTypeCompliment, "", uuid.New(), nil, &consumption)
+	s.Require().NoError(err)
+	s.Require().Equal(rvo.VoteTypeCompliment, changedRomance.ActiveUserVote.VoteType)
+	s.Require().Equal(romance.Version+1, changedRomance.Version)
+
+	storedRomance, err := repo.GetRomance(ctx, voteId)
+	s.Require().NoError(err)
+	s.Require().Equal(storedRomance, changedRomance)
+
+	usage, err := quotasRepo.GetDailyUsage(ctx, activeUserKey, quotaDay)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(1), usage[rvo.VoteTypeCompliment])
+
+	// step 2: The exhausted quota takes priority over the version conflict of the stale romance
+	_, err = repo.ChangeActiveUserVoteTypeInRomanceWithCounters(ctx, romance, rvo.VoteTypeCompliment, "", uuid.New(), nil, &consumption)
+	s.Require().ErrorIs(err, quotaDomain.ErrQuotaExceeded)
+
+	err = repo.DeleteRomance(ctx, voteId)
+	s.Require().NoError(err)
+}
+
+func (s *RomancesRepositoryTestSuite) TestChangeAndDeleteVoteWithCounters() {
+	ctx := context.Background()
+	repo := newRomancesRepository(ddbClient)
+	countersRepo := newCountersRepository(ddbClient)
+
+	voteId, err := sharedValueObject.NewVoteId(s.voteId.CountryId(), uuid.New(), s.voteId.CountryId(), uuid.New())
+	s.Require().NoError(err)
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(voteId.CountryId(), voteId.ActiveUserId())
+	s.Require().NoError(err)
+	peerUserKey, err := sharedValueObject.NewActiveUserKey(voteId.PeerCountryId(), voteId.PeerUserId())
+	s.Require().NoError(err)
+
+	counterUpdateGroup, err := counterValueObject.NewCounterUpdateGroup(time.Now())
+	s.Require().NoError(err)
+
+	romance, err := repo.AddActiveUserVoteToRomanceWithCounters(
+		ctx,
+		romanceEntity.CreateEmptyRomance(voteId),
+		rvo.VoteTypeYes,
+		"",
+		time.Now(),
+		uuid.New(),
+		[]counterValueObject.VoteCountersChange{counterValueObject.NewVoteTypeCountersChange(counterUpdateGroup, rvo.VoteTypeEmpty, rvo.VoteTypeYes, rvo.VoteTypeEmpty)},
+		nil,
+	)
+	s.Require().NoError(err)
+
+	// step 1: Changing the vote moves it within the counters
+	changedRomance, err := repo.ChangeActiveUserVoteTypeInRomanceWithCounters(
+		ctx,
+		romance,
+		rvo.VoteTypeNo,
+		"",
+		uuid.New(),
+		[]counterValueObject.VoteCountersChange{counterValueObject.NewVoteTypeCountersChange(counterUpdateGroup, rvo.VoteTypeYes, rvo.VoteTypeNo, rvo.VoteTypeEmpty)},
+		nil,
+	)
+	s.Require().NoError(err)
+
+	activeUserCounters, err := countersRepo.GetLifetimeCounter(ctx, activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(0), activeUserCounters.OutgoingYes)
+	s.Require().Equal(uint32(1), activeUserCounters.OutgoingNo)
+
+	// step 2: The stale romance leaves the counters untouched
+	_, err = repo.ChangeActiveUserVoteTypeInRomanceWithCounters(
+		ctx,
+		romance,
+		rvo.VoteTypeNo,
+		"",
+		uuid.New(),
+		[]counterValueObject.VoteCountersChange{counterValueObject.NewVoteTypeCountersChange(counterUpdateGroup, rvo.VoteTypeYes, rvo.VoteTypeNo, rvo.VoteTypeEmpty)},
+		nil,
+	)
+	s.Require().ErrorIs(err, romanceDomain.ErrVersionConflict)
+
+	activeUserCounters, err = countersRepo.GetLifetimeCounter(ctx, activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(1), activeUserCounters.OutgoingNo)
+
+	// step 3: Deleting the vote takes it out of the counters of both users
+	err = repo.DeleteActiveUserVoteFromRomanceWithCounters(
+		ctx,
+		changedRomance,
+		uuid.New(),
+		[]counterValueObject.VoteCountersChange{counterValueObject.NewVoteTypeCountersChange(counterUpdateGroup, rvo.VoteTypeNo, rvo.VoteTypeEmpty, rvo.VoteTypeEmpty)},
+	)
+	s.Require().NoError(err)
+
+	activeUserCounters, err = countersRepo.GetLifetimeCounter(ctx, activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(0), activeUserCounters.OutgoingNo)
+
+	peerUserCounters, err := countersRepo.GetLifetimeCounter(ctx, peerUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(0), peerUserCounters.IncomingYes)
+	s.Require().Equal(uint32(0), peerUserCounters.IncomingNo)
+
+	storedRomance, err := repo.GetRomance(ctx, voteId)
+	s.Require().NoError(err)
+	s.Require().True(storedRomance.ActiveUserVote.VoteType.IsEmpty())
+
+	err = repo.DeleteRomance(ctx, voteId)
+	s.Require().NoError(err)
+}
+
+func (s *RomancesRepositoryTestSuite) TestAddActiveUserVoteWithWrongPeerVotePart() {
+	ctx := context.Background()
+	repo := newRomancesRepository(ddbClient)
+
+	now := time.Now()
+
+	// step 1: Creating romance with wrong peer vote part (the peer vote part is not synchronized with the db)
+	romance := romanceEntity.CreateEmptyRomance(s.voteId)
+	romance.PeerUserVote.VoteType = rvo.VoteTypeNo
+	romance.PeerUserVote.VotedAt = &now
+
+	// step 2: Adding vote
+	newRomance, err := repo.AddActiveUserVoteToRomance(ctx, romance, rvo.VoteTypeYes, "", now)
+	s.Require().NoError(err)
+	// The `AddActiveUserVoteToRomance` method returns a synchronized romance
+	s.assertRomanceInDbMatchesExpected(
+		newExpectedRomanceParams(s.voteId, rvo.VoteTypeYes, rvo.VoteTypeEmpty, 1),
+		newRomance,
+	)
+}
+
+func (s *RomancesRepositoryTestSuite) TestAddVoteWithDbException() {
+	ctx := context.Background()
+
+	romance := romanceEntity.CreateEmptyRomance(s.voteId)
+
+	ctrl := gomock.NewController(s.T())
+	mock := mocks.NewMockClient(ctrl)
+
+	expectedErr := &types.InvalidEndpointException{}
+
+	mock.EXPECT().
+		UpdateItem(ctx, gomock.Any(), gomock.Any()).
+		Return(nil, expectedErr)
+
+	repo := newRomancesRepository(mock)
+
+	// Adding a YES vote for the active user (getting client error)
+	newRomance, err := repo.AddActiveUserVoteToRomance(ctx, romance, rvo.VoteTypeYes, "", time.Now())
+	s.Require().Error(err)
+	s.Require().ErrorIs(err, expectedErr)
+	s.assertNilRomance(newRomance)
+}
+
+func (s *RomancesRepositoryTestSuite) TestDeleteRomance() {
+	ctx := context.Background()
+	repo := newRomancesRepository(ddbClient)
+
+	// step 1: Adding new Romance
+	romance := romanceEntity.CreateEmptyRomance(s.voteId)
+	_, err := repo.AddActiveUserVoteToRomance(ctx, romance, rvo.VoteTypeYes, "", time.Now())
+	s.Require().NoError(err)
+
+	// step 2: Deleting this romance
+	err = repo.DeleteRomance(ctx, s.voteId)
+	s.Require().NoError(err)
+
+	// step 3: Check romance from active user and peer side
+	romance, err = repo.GetRomance(ctx, s.voteId)
+	s.Require().NoError(err)
+	s.assertEmptyRomance(s.voteId, romance)
+
+	romance, err = repo.GetRomance(ctx, s.voteId.ToPeerVoteId())
+	s.Require().NoError(err)
+	s.assertEmptyRomance(s.voteId.ToPeerVoteId(), romance)
+}
+
+func (s *RomancesRepositoryTestSuite) TestDeleteRomanceFromPeerSide() {
+	ctx := context.Background()
+	repo := newRomancesRepository(ddbClient)
+
+	// step 1: Adding new Romance
+	romance := romanceEntity.CreateEmptyRomance(s.voteId)
+	_, err := repo.AddActiveUserVoteToRomance(ctx, romance, rvo.VoteTypeYes, "", time.Now())
+	s.Require().NoError(err)
+
+	// step 2: Deleting this romance from peer side
+	err = repo.DeleteRomance(ctx, s.voteId.ToPeerVoteId())
+	s.Require().NoError(err)
+
+	// step 3: Check romance from active user and peer side
+	romance, err = repo.GetRomance(ctx, s.voteId)
+	s.Require().NoError(err)
+	s.assertEmptyRomance(s.voteId, romance)
+
+	romance, err = repo.GetRomance(ctx, s.voteId.ToPeerVoteId())
+	s.Require().NoError(err)
+	s.assertEmptyRomance(s.voteId.ToPeerVoteId(), romance)
+}
+
+func (s *RomancesRepositoryTestSuite) TestDeleteNotExistsRomance() {
+	ctx := context.Background()
+	repo := newRomancesRepository(ddbClient)
+
+	err := repo.DeleteRomance(ctx, s.voteId)
+	s.Require().NoError(err)
+}
+
+func (s *RomancesRepositoryTestSuite) TestDeleteRomanceWithDbException() {
+	ctrl := gomock.NewController(s.T())
+	mock := mocks.NewMockClient(ctrl)
+
+	ctx := context.Background()
+
+	expectedErr := &types.InvalidEndpointException{}
+
+	mock.EXPECT().
+		DeleteItem(ctx, gomock.Any(), gomock.Any()).
+		Return(nil, expectedErr)
+
+	repo := newRomancesRepository(mock)
+
+	err := repo.DeleteRomance(ctx, s.voteId)
+	s.Require().Error(err)
+	s.Require().ErrorIs(err, expectedErr)
+}
+
+func (s *RomancesRepositoryTestSuite) TestDeleteUserRomances() {
+	ctx := context.Background()
+	repo := newRomancesRepository(ddbClient)
+
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(s.voteId.CountryId(), s.voteId.ActiveUserId())
+	s.Require().NoError(err)
+
+	// step 1: Adding romances with many peers (the active user lands on both the pk and the sk side)
+	voteIds := []sharedValueObject.VoteId{s.voteId}
+	for i := 0; i < 30; i++ {
+		voteId, err := sharedValueObject.NewVoteId(activeUserKey.CountryId(), activeUserKey.ActiveUserId(), activeUserKey.CountryId(), uuid.New())
+		s.Require().NoError(err)
+		voteIds = append(voteIds, voteId)
+	}
+	for i, voteId := range voteIds {
+		if i%2 == 0 {
+			voteId = voteId.ToPeerVoteId()
+		}
+		_, err = repo.AddActiveUserVoteToRomance(ctx, romanceEntity.CreateEmptyRomance(voteId), rvo.VoteTypeYes, "", time.Now())
+		s.Require().NoError(err)
+	}
+
+	// step 2: Adding a romance of two other users which must survive
+	otherVoteId, err := sharedValueObject.NewVoteId(activeUserKey.CountryId(), uuid.New(), activeUserKey.CountryId(), voteIds[1].PeerUserId())
+	s.Require().NoError(err)
+	_, err = repo.AddActiveUserVoteToRomance(ctx, romanceEntity.CreateEmptyRomance(otherVoteId), rvo.VoteTypeNo, "", time.Now())
+	s.Require().NoError(err)
+
+	// step 3: Deleting all active user romances
+	err = repo.DeleteUserRomances(ctx, activeUserKey)
+	s.Require().NoError(err)
+
+	for _, voteId := range voteIds {
+		romance, err := repo.GetRomance(ctx, voteId)
+		s.Require().NoError(err)
+		s.assertEmptyRomance(voteId, romance)
+	}
+
+	otherRomance, err := repo.GetRomance(ctx, otherVoteId)
+	s.Require().NoError(err)
+	s.assertRomanceInDbMatchesExpected(
+		newExpectedRomanceParams(otherVoteId, rvo.VoteTypeNo, rvo.VoteTypeEmpty, 1),
+		otherRomance,
+	)
+
+	err = repo.DeleteRomance(ctx, otherVoteId)
+	s.Require().NoError(err)
+}
+
+func (s *RomancesRepositoryTestSuite) TestDeleteUserRomancesRetriesUnprocessedItems() {
+	ctrl := gomock.NewController(s.T())
+	mock := mocks.NewMockClient(ctrl)
+
+	ctx := context.Background()
+
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(s.voteId.CountryId(), s.voteId.ActiveUserId())
+	s.Require().NoError(err)
+
+	romanceKey := infraDynamodb.NewRomancePrimaryKey(s.voteId)
+	item := map[string]types.AttributeValue{
+		infraDynamodb.PkUserIdAttrName: &types.AttributeValueMemberS{Value: romanceKey.Pk.String()},
+		infraDynamodb.SkUserIdAttrName: &types.AttributeValueMemberS{Value: romanceKey.Sk.String()},
+	}
+	unprocessed := map[string][]types.WriteRequest{
+		infraDynamodb.RomancesTableName: {{DeleteRequest: &types.DeleteRequest{Key: item}}},
+	}
+
+	gomock.InOrder(
+		mock.EXPECT().
+			Query(ctx, gomock.Any(), gomock.Any()).
+			Return(&dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{item}}, nil),
+		mock.EXPECT().
+			BatchWriteItem(ctx, gomock.Any(), gomock.Any()).
+			Return(&dynamodb.BatchWriteItemOutput{UnprocessedItems: unprocessed}, nil),
+		mock.EXPECT().
+			BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{RequestItems: unprocessed}, gomock.Any()).
+			Return(&dynamodb.BatchWriteItemOutput{}, nil),
+		mock.EXPECT().
+			Query(ctx, gomock.Any(), gomock.Any()).
+			Return(&dynamodb.QueryOutput{}, nil),
+	)
+
+	repo := newRomancesRepository(mock)
+
+	err = repo.DeleteUserRomances(ctx, activeUserKey)
+	s.Require().NoError(err)
+}
+
+func (s *RomancesRepositoryTestSuite) TestDeleteUserRomancesWithDbException() {
+	ctrl := gomock.NewController(s.T())
+	mock := mocks.NewMockClient(ctrl)
+
+	ctx := context.Background()
+
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(s.voteId.CountryId(), s.voteId.ActiveUserId())
+	s.Require().NoError(err)
+
+	expectedErr := &types.InvalidEndpointException{}
+
+	mock.EXPECT().
+		Query(ctx, gomock.Any(), gomock.Any()).
+		Return(nil, expectedErr)
+
+	repo := newRomancesRepository(mock)
+
+	err = repo.DeleteUserRomances(ctx, activeUserKey)
+	s.Require().Error(err)
+	s.Require().ErrorIs(err, expectedErr)
+}
+
+func (s *RomancesRepositoryTestSuite) TestListUserRomances() {
+	ctx := context.Background()
+	repo := newRomancesRepository(ddbClient)
+
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(s.voteId.CountryId(), uuid.New())
+	s.Require().NoError(err)
+
+	// step 1: Adding romances with many peers, half of them voted by the peer
+	expectedPeerVotes := map[uuid.UUID]rvo.VoteType{}
+	for i := 0; i < 7; i++ {
+		voteId, err := sharedValueObject.NewVoteId(activeUserKey.CountryId(), activeUserKey.ActiveUserId(), activeUserKey.CountryId(), uuid.New())
+		s.Require().NoError(err)
+
+		if i%2 == 0 {
+			_, err = repo.AddActiveUserVoteToRomance(ctx, romanceEntity.CreateEmptyRomance(voteId.ToPeerVoteId()), rvo.VoteTypeNo, "", time.Now())
+			expectedPeerVotes[voteId.PeerUserId()] = rvo.VoteTypeNo
+		} else {
+			_, err = repo.AddActiveUserVoteToRomance(ctx, romanceEntity.CreateEmptyRomance(voteId), rvo.VoteTypeYes, "", time.Now())
+			expectedPeerVotes[voteId.PeerUserId()] = rvo.VoteTypeEmpty
+		}
+		s.Require().NoError(err)
+	}
+
+	// step 2: Reading all pages
+	actualPeerVotes := map[uuid.UUID]rvo.VoteType{}
+	cursor := ""
+	for pages := 0; ; pages++ {
+		s.Require().Less(pages, 10)
+
+		pageRequest, err := sharedValueObject.NewPageRequest(3, cursor)
+		s.Require().NoError(err)
+
+		page, err := repo.ListUserRomances(ctx, activeUserKey, pageRequest)
+		s.Require().NoError(err)
+		s.Require().LessOrEqual(len(page.Romances), 3)
+
+		for _, romance := range page.Romances {
+			s.Require().Equal(activeUserKey.ActiveUserId(), romance.ActiveUserVote.Id.ActiveUserId())
+			actualPeerVotes[romance.ActiveUserVote.Id.PeerUserId()] = romance.PeerUserVote.VoteType
+		}
+
+		if !page.HasMore() {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	s.Require().Equal(expectedPeerVotes, actualPeerVotes)
+
+	err = repo.DeleteUserRomances(ctx, activeUserKey)
+	s.Require().NoError(err)
+}
+
+func (s *RomancesRepositoryTestSuite) TestListUserRomancesWithInvalidCursor() {
+	ctx := context.Background()
+	repo := newRomancesRepository(ddbClient)
+
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(s.voteId.CountryId(), s.voteId.ActiveUserId())
+	s.Require().NoError(err)
+
+	pageRequest, err := sharedValueObject.NewPageRequest(3, "not a cursor")
+	s.Require().NoError(err)
+
+	_, err = repo.ListUserRomances(ctx, activeUserKey, pageRequest)
+	s.Require().ErrorIs(err, romanceDomain.ErrInvalidCursor)
+}
+
+func (s *RomancesRepositoryTestSuite) TestListUserMatches() {
+	ctx := context.Background()
+	repo := newRomancesRepository(ddbClient)
+
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(s.voteId.CountryId(), uuid.New())
+	s.Require().NoError(err)
+
+	// step 1: Adding mutual romances, the match time grows with every peer
+	baseTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	var expectedPeers []uuid.UUID
+	for i := 0; i < 5; i++ {
+		voteId, err := sharedValueObject.NewVoteId(activeUserKey.CountryId(), activeUserKey.ActiveUserId(), activeUserKey.CountryId(), uuid.New())
+		s.Require().NoError(err)
+
+		romance, err := repo.AddActiveUserVoteToRomance(ctx, romanceEntity.CreateEmptyRomance(voteId), rvo.VoteTypeYes, "", baseTime)
+		s.Require().NoError(err)
+
+		peerRomance, err := repo.GetRomance(ctx, voteId.ToPeerVoteId())
+		s.Require().NoError(err)
+		_, err = repo.AddActiveUserVoteToRomance(ctx, peerRomance, rvo.VoteTypeCrush, "", baseTime.Add(time.Duration(i)*time.Minute))
+		s.Require().NoError(err)
+
+		s.Require().False(romance.IsMutual())
+		expectedPeers = append([]uuid.UUID{voteId.PeerUserId()}, expectedPeers...)
+	}
+
+	// step 2: Adding romances which are not mutual
+	for _, peerVoteType := range []rvo.VoteType{rvo.VoteTypeNo, rvo.VoteTypeEmpty} {
+		voteId, err := sharedValueObject.NewVoteId(activeUserKey.CountryId(), activeUserKey.ActiveUserId(), activeUserKey.CountryId(), uuid.New())
+		s.Require().NoError(err)
+
+		_, err = repo.AddActiveUserVoteToRomance(ctx, romanceEntity.CreateEmptyRomance(voteId), rvo.VoteTypeYes, "", baseTime)
+		s.Require().NoError(err)
+		if !peerVoteType.IsEmpty() {
+			peerRomance, err := repo.GetRomance(ctx, voteId.ToPeerVoteId())
+			s.Require().NoError(err)
+			_, err = repo.AddActiveUserVoteToRomance(ctx, peerRomance, peerVoteType, "", baseTime.Add(time.Hour))
+			s.Require().NoError(err)
+		}
+	}
+
+	// step 3: Removing the most recent match by deleting the peer vote
+	lastMatchVoteId, err := sharedValueObject.NewVoteId(activeUserKey.CountryId(), expectedPeers[0], activeUserKey.CountryId(), activeUserKey.ActiveUserId())
+	s.Require().NoError(err)
+	lastMatch, err := repo.GetRomance(ctx, lastMatchVoteId)
+	s.Require().NoError(err)
+	s.Require().NoError(repo.DeleteActiveUserVoteFromRomance(ctx, lastMatch))
+	expectedPeers = expectedPeers[1:]
+
+	// step 4: Reading all pages
+	var actualPeers []uuid.UUID
+	var lastMatchedAt *time.Time
+	cursor := ""
+	for pages := 0; ; pages++ {
+		s.Require().Less(pages, 10)
+
+		pageRequest, err := sharedValueObject.NewPageRequest(2, cursor)
+		s.Require().NoError(err)
+
+		page, err := repo.ListUserMatches(ctx, activeUserKey, pageRequest)
+		s.Require().NoError(err)
+		s.Require().LessOrEqual(len(page.Romances), 2)
+
+		for _, romance := range page.Romances {
+			s.Require().True(romance.IsMutual())
+			if lastMatchedAt != nil {
+				s.Require().False(romance.MatchedAt().After(*lastMatchedAt))
+			}
+			lastMatchedAt = romance.MatchedAt()
+			actualPeers = append(actualPeers, romance.ActiveUserVote.Id.PeerUserId())
+		}
+
+		if !page.HasMore() {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	s.Require().Equal(expectedPeers, actualPeers)
+
+	err = repo.DeleteUserRomances(ctx, activeUserKey)
+	s.Require().NoError(err)
+}
+
+func (s *RomancesRepositoryTestSuite) TestListUserLikes() {
+	ctx := context.Background()
+	repo := newRomancesRepository(ddbClient)
+
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(s.voteId.CountryId(), uuid.New())
+	s.Require().NoError(err)
+
+	baseTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	addVote := func(voteId sharedValueObject.VoteId, voteType rvo.VoteType, votedAt time.Time) {
+		romance, err := repo.GetRomance(ctx, voteId)
+		s.Require().NoError(err)
+		_, err = repo.AddActiveUserVoteToRomance(ctx, romance, voteType, "", votedAt)
+		s.Require().NoError(err)
+	}
+	newPeerVoteId := func() sharedValueObject.VoteId {
+		voteId, err := sharedValueObject.NewVoteId(activeUserKey.CountryId(), uuid.New(), activeUserKey.CountryId(), activeUserKey.ActiveUserId())
+		s.Require().NoError(err)
+		return voteId
+	}
+
+	// step 1: Peers like the active user, the most recent vote last
+	peerVoteTypes := []rvo.VoteType{rvo.VoteTypeYes, rvo.VoteTypeCrush, rvo.VoteTypeCompliment, rvo.VoteTypeCrush, rvo.VoteTypeYes}
+	var expectedPeers, expectedCrushPeers []uuid.UUID
+	for i, voteType := range peerVoteTypes {
+		peerVoteId := newPeerVoteId()
+		addVote(peerVoteId, voteType, baseTime.Add(time.Duration(i)*time.Minute))
+
+		expectedPeers = append([]uuid.UUID{peerVoteId.ActiveUserId()}, expectedPeers...)
+		if voteType == rvo.VoteTypeCrush {
+			expectedCrushPeers = append([]uuid.UUID{peerVoteId.ActiveUserId()}, expectedCrushPeers...)
+		}
+	}
+
+	// step 2: Romances which are not incoming likes
+	dislikeVoteId := newPeerVoteId()
+	addVote(dislikeVoteId, rvo.VoteTypeNo, baseTime.Add(time.Hour))
+
+	answeredVoteId := newPeerVoteId()
+	addVote(answeredVoteId, rvo.VoteTypeCrush, baseTime.Add(time.Hour))
+	addVote(answeredVoteId.ToPeerVoteId(), rvo.VoteTypeNo, baseTime.Add(time.Hour))
+
+	outgoingVoteId, err := sharedValueObject.NewVoteId(activeUserKey.CountryId(), activeUserKey.ActiveUserId(), activeUserKey.CountryId(), uuid.New())
+	s.Require().NoError(err)
+	addVote(outgoingVoteId, rvo.VoteTypeYes, baseTime.Add(time.Hour))
+
+	// step 3: The active user matches a peer and then takes the vote back, so the like is pending again
+	rematchVoteId := newPeerVoteId()
+	addVote(rematchVoteId, rvo.VoteTypeYes, baseTime.Add(-time.Minute))
+	addVote(rematchVoteId.ToPeerVoteId(), rvo.VoteTypeYes, baseTime.Add(time.Hour))
+
+	firstPage, err := sharedValueObject.NewPageRequest(0, "")
+	s.Require().NoError(err)
+	matches, err := repo.ListUserMatches(ctx, activeUserKey, firstPage)
+	s.Require().NoError(err)
+	s.Require().Len(matches.Romances, 1)
+
+	rematch, err := repo.GetRomance(ctx, rematchVoteId.ToPeerVoteId())
+	s.Require().NoError(err)
+	s.Require().NoError(repo.DeleteActiveUserVoteFromRomance(ctx, rematch))
+	expectedPeers = append(expectedPeers, rematchVoteId.ActiveUserId())
+
+	// step 4: Reading all pages with and without the vote types filter
+	listPeers := func(voteTypes []rvo.VoteType) []uuid.UUID {
+		var peers []uuid.UUID
+		cursor := ""
+		for pages := 0; ; pages++ {
+			s.Require().Less(pages, 10)
+
+			pageRequest, err := sharedValueObject.NewPageRequest(2, cursor)
+			s.Require().NoError(err)
+
+			page, err := repo.ListUserLikes(ctx, activeUserKey, voteTypes, pageRequest)
+			s.Require().NoError(err)
+			s.Require().LessOrEqual(len(page.Romances), 2)
+
+			for _, romance := range page.Romances {
+				s.Require().True(romance.IsIncomingLike())
+				peers = append(peers, romance.ActiveUserVote.Id.PeerUserId())
+			}
+
+			if !page.HasMore() {
+				return peers
+			}
+			cursor = page.NextCursor
+		}
+	}
+
+	s.Require().Equal(expectedPeers, listPeers(nil))
+	s.Require().Equal(expectedCrushPeers, listPeers([]rvo.VoteType{rvo.VoteTypeCrush}))
+
+	err = repo.DeleteUserRomances(ctx, activeUserKey)
+	s.Require().NoError(err)
 }
 
-func (s *RomancesRepositoryTestSuite) TestGetAndAddRomanceVote() {
+func (s *RomancesRepositoryTestSuite) TestDeleteActiveUserVoteFromRomance() {
 	ctx := context.Background()
 	repo := newRomancesRepository(ddbClient)
 
-	// step 1: Receiving an empty romance object
-	romance, err := repo.GetRomance(ctx, s.voteId)
+	activeUserVoteId := s.voteId
+	peerUserVoteId := s.voteId.ToPeerVoteId()
+
+	// step 1: Adding new Romance and active user vote
+	emptyActiveUserRomance := romanceEntity.CreateEmptyRomance(activeUserVoteId)
+	_, err := repo.AddActiveUserVoteToRomance(ctx, emptyActiveUserRomance, rvo.VoteTypeYes, "", time.Now())
 	s.Require().NoError(err)
-	s.assertRomanceInDbMatchesExpected(
-		newExpectedRomanceParams(s.voteId, rvo.VoteTypeEmpty, rvo.VoteTypeEmpty, 0),
-		romance,
-	)
 
-	// step 2: Adding a YES vote for the active user
-	newRomance, err := repo.AddActiveUserVoteToRomance(ctx, romance, rvo.VoteTypeYes, time.Now())
+	// step 2: Add vote to peer user side
+	peerRomance, err := repo.GetRomance(ctx, peerUserVoteId)
+	s.Require().NoError(err)
+	peerRomance, err = repo.AddActiveUserVoteToRomance(ctx, peerRomance, rvo.VoteTypeNo, "", time.Now())
 	s.Require().NoError(err)
+
 	s.assertRomanceInDbMatchesExpected(
-		newExpectedRomanceParams(s.voteId, rvo.VoteTypeYes, rvo.VoteTypeEmpty, 1),
-		newRomance,
+		newExpectedRomanceParams(peerUserVoteId, rvo.VoteTypeNo, rvo.VoteTypeYes, 2),
+		peerRomance,
 	)
 
-	// step 3: Getting a romance from the repo (active user side) and checking if it contains the expected values
-	romance, err = repo.GetRomance(ctx, s.voteId)
+	// step 3: Deleting vote from peer user side
+	err = repo.DeleteActiveUserVoteFromRomance(ctx, peerRomance)
 	s.Require().NoError(err)
-	assert.Equal(s.T(), newRomance, romance)
 
-	// step 4: Getting a romance from the repo (peer user side) and checking if it contains the expected values
-	peerVoteId := s.voteId.ToPeerVoteId()
-	peerRomance, err := repo.GetRomance(ctx, peerVoteId)
+	peerRomance, err = repo.GetRomance(ctx, peerUserVoteId)
 	s.Require().NoError(err)
 	s.assertRomanceInDbMatchesExpected(
-		newExpectedRomanceParams(peerVoteId, rvo.VoteTypeEmpty, rvo.VoteTypeYes, 1),
+		newExpectedRomanceParams(peerUserVoteId, rvo.VoteTypeEmpty, rvo.VoteTypeYes, 3),
 		peerRomance,
 	)
 
-	// step 5: Adding a new NO vote from the peer side
-	newPeerRomance, err := repo.AddActiveUserVoteToRomance(ctx, peerRomance, rvo.VoteTypeNo, time.Now())
+	// step 4: Deleting vote from active user side
+	activeUserRomance, err := repo.GetRomance(ctx, activeUserVoteId)
 	s.Require().NoError(err)
-	s.assertRomanceInDbMatchesExpected(
-		newExpectedRomanceParams(peerVoteId, rvo.VoteTypeNo, rvo.VoteTypeYes, 2),
-		newPeerRomance,
-	)
 
-	// step 6: Comparison of the romance received after the update and from GetRomance method
-	peerRomance, err = repo.GetRomance(ctx, s.voteId.ToPeerVoteId())
+	err = repo.DeleteActiveUserVoteFromRomance(ctx, activeUserRomance)
 	s.Require().NoError(err)
-	assert.Equal(s.T(), newPeerRomance, peerRomance)
+
+	activeUserRomance, err = repo.GetRomance(ctx, activeUserVoteId)
+	s.Require().NoError(err)
+	s.assertRomanceInDbMatchesExpected(
+		newExpectedRomanceParams(activeUserVoteId, rvo.VoteTypeEmpty, rvo.VoteTypeEmpty, 4),
+		activeUserRomance,
+	)
 }
 
-func (s *RomancesRepositoryTestSuite) TestAddVoteAndRewrite() {
+func (s *RomancesRepositoryTestSuite) TestComplimentMessage() {
 	ctx := context.Background()
 	repo := newRomancesRepository(ddbClient)
 
-	now := time.Now()
+	voteId, err := sharedValueObject.NewVoteId(s.voteId.CountryId(), uuid.New(), s.voteId.CountryId(), uuid.New())
+	s.Require().NoError(err)
+	peerVoteId := voteId.ToPeerVoteId()
 
-	activeUserVoteId := s.voteId
-	peerVoteId := s.voteId.ToPeerVoteId()
+	message, err := rvo.NewComplimentMessage("Nice smile 🙂")
+	s.Require().NoError(err)
 
-	// step 1: Adding a NO vote for the peer user
-	peerRomance := romanceEntity.CreateEmptyRomance(peerVoteId)
-	newPeerRomance, err := repo.AddActiveUserVoteToRomance(ctx, peerRomance, rvo.VoteTypeYes, now)
+	// step 1: Both users send compliments, each message is stored on the voter's side
+	romance, err := repo.AddActiveUserVoteToRomance(ctx, romanceEntity.CreateEmptyRomance(voteId), rvo.VoteTypeCompliment, message, time.Now())
 	s.Require().NoError(err)
-	s.assertRomanceInDbMatchesExpected(
-		newExpectedRomanceParams(peerVoteId, rvo.VoteTypeYes, rvo.VoteTypeEmpty, 1),
-		newPeerRomance,
-	)
+	s.Require().Equal(message, romance.ActiveUserVote.Message)
 
-	// step 2: Rewriting peer vote
-	// There is no check at the persistence level for which vote we are inserting,
-	// so there may be a NO after YES
-	newPeerRomance, err = repo.AddActiveUserVoteToRomance(ctx, newPeerRomance, rvo.VoteTypeNo, now)
+	peerRomance, err := repo.GetRomance(ctx, peerVoteId)
 	s.Require().NoError(err)
+	peerRomance, err = repo.AddActiveUserVoteToRomance(ctx, peerRomance, rvo.VoteTypeCompliment, "Thanks!", time.Now())
+	s.Require().NoError(err)
+	s.Require().Equal(rvo.ComplimentMessage("Thanks!"), peerRomance.ActiveUserVote.Message)
+	s.Require().Equal(message, peerRomance.PeerUserVote.Message)
 	s.assertRomanceInDbMatchesExpected(
-		newExpectedRomanceParams(peerVoteId, rvo.VoteTypeNo, rvo.VoteTypeEmpty, 2),
-		newPeerRomance,
+		newExpectedRomanceParams(peerVoteId, rvo.VoteTypeCompliment, rvo.VoteTypeCompliment, 2),
+		peerRomance,
 	)
 
-	// step 3: Get the same romance from the active user side
-	romance, err := repo.GetRomance(ctx, activeUserVoteId)
+	// step 2: Changing the vote to a vote without a message removes the message
+	romance, err = repo.GetRomance(ctx, voteId)
 	s.Require().NoError(err)
+	romance, err = repo.ChangeActiveUserVoteTypeInRomance(ctx, romance, rvo.VoteTypeYes, "")
+	s.Require().NoError(err)
+	s.Require().True(romance.ActiveUserVote.Message.IsEmpty())
+	s.Require().Equal(rvo.ComplimentMessage("Thanks!"), romance.PeerUserVote.Message)
 	s.assertRomanceInDbMatchesExpected(
-		newExpectedRomanceParams(activeUserVoteId, rvo.VoteTypeEmpty, rvo.VoteTypeNo, 2),
+		newExpectedRomanceParams(voteId, rvo.VoteTypeYes, rvo.VoteTypeCompliment, 3),
 		romance,
 	)
 
-	// step 4: Adding a YES vote to the active user romance
-	newRomance, err := repo.AddActiveUserVoteToRomance(ctx, romance, rvo.VoteTypeYes, now)
+	// step 3: Deleting the vote removes its message
+	peerRomance, err = repo.GetRomance(ctx, peerVoteId)
+	s.Require().NoError(err)
+	err = repo.DeleteActiveUserVoteFromRomance(ctx, peerRomance)
 	s.Require().NoError(err)
-	s.assertRomanceInDbMatchesExpected(
-		newExpectedRomanceParams(activeUserVoteId, rvo.VoteTypeYes, rvo.VoteTypeNo, 3),
-		newRomance,
-	)
 
-	// step 5: Adding a NO vote for the active user
-	newRomance, err = repo.AddActiveUserVoteToRomance(ctx, newRomance, rvo.VoteTypeNo, now)
+	peerRomance, err = repo.GetRomance(ctx, peerVoteId)
 	s.Require().NoError(err)
+	s.Require().True(peerRomance.ActiveUserVote.Message.IsEmpty())
 	s.assertRomanceInDbMatchesExpected(
-		newExpectedRomanceParams(activeUserVoteId, rvo.VoteTypeNo, rvo.VoteTypeNo, 4),
-		newRomance,
+		newExpectedRomanceParams(peerVoteId, rvo.VoteTypeEmpty, rvo.VoteTypeYes, 4),
+		peerRomance,
 	)
+
+	err = repo.DeleteRomance(ctx, voteId)
+	s.Require().NoError(err)
 }
 
-func (s *RomancesRepositoryTestSuite) TestAddWithVersionConflictError() {
+func (s *RomancesRepositoryTestSuite) TestBlockAndUnblockRomance() {
 	ctx := context.Background()
 	repo := newRomancesRepository(ddbClient)
 
-	now := time.Now()
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(s.voteId.CountryId(), uuid.New())
+	s.Require().NoError(err)
+	voteId, err := sharedValueObject.NewVoteId(activeUserKey.CountryId(), activeUserKey.ActiveUserId(), activeUserKey.CountryId(), uuid.New())
+	s.Require().NoError(err)
 
-	// step 1: Creating romance with wrong version (the version is not synchronized with the db)
-	romance := romanceEntity.CreateEmptyRomance(s.voteId)
-	romance.Version = 10
+	firstPage, err := sharedValueObject.NewPageRequest(0, "")
+	s.Require().NoError(err)
 
-	// step 2: Adding vote
-	newRomance, err := repo.AddActiveUserVoteToRomance(ctx, romance, rvo.VoteTypeYes, now)
-	s.Require().Error(err)
+	// step 1: Both users like each other
+	_, err = repo.AddActiveUserVoteToRomance(ctx, romanceEntity.CreateEmptyRomance(voteId), rvo.VoteTypeYes, "", time.Now())
+	s.Require().NoError(err)
+	peerRomance, err := repo.GetRomance(ctx, voteId.ToPeerVoteId())
+	s.Require().NoError(err)
+	_, err = repo.AddActiveUserVoteToRomance(ctx, peerRomance, rvo.VoteTypeYes, "", time.Now())
+	s.Require().NoError(err)
+
+	matches, err := repo.ListUserMatches(ctx, activeUserKey, firstPage)
+	s.Require().NoError(err)
+	s.Require().Len(matches.Romances, 1)
+
+	// step 2: Blocking keeps the votes but removes the pair from the matches
+	romance, err := repo.GetRomance(ctx, voteId)
+	s.Require().NoError(err)
+	blockedRomance, err := repo.BlockRomance(ctx, romance)
+	s.Require().NoError(err)
+	s.Require().True(blockedRomance.IsBlockedByActiveUser())
+	s.Require().Equal(romance.Version+1, blockedRomance.Version)
+	s.Require().Equal(rvo.VoteTypeYes, blockedRomance.PeerUserVote.VoteType)
+
+	peerRomance, err = repo.GetRomance(ctx, voteId.ToPeerVoteId())
+	s.Require().NoError(err)
+	s.Require().True(peerRomance.IsBlocked())
+	s.Require().False(peerRomance.IsBlockedByActiveUser())
+
+	matches, err = repo.ListUserMatches(ctx, activeUserKey, firstPage)
+	s.Require().NoError(err)
+	s.Require().Empty(matches.Romances)
+
+	// step 3: Blocking the stale romance fails
+	_, err = repo.BlockRomance(ctx, romance)
 	s.Require().ErrorIs(err, romanceDomain.ErrVersionConflict)
-	s.assertNilRomance(newRomance)
+
+	// step 4: Unblocking brings the pair back to the matches
+	unblockedRomance, err := repo.UnblockRomance(ctx, blockedRomance)
+	s.Require().NoError(err)
+	s.Require().False(unblockedRomance.IsBlocked())
+
+	matches, err = repo.ListUserMatches(ctx, activeUserKey, firstPage)
+	s.Require().NoError(err)
+	s.Require().Len(matches.Romances, 1)
+
+	err = repo.DeleteRomance(ctx, voteId)
+	s.Require().NoError(err)
 }
 
-func (s *RomancesRepositoryTestSuite) TestAddActiveUserVoteWithWrongPeerVotePart() {
+func (s *RomancesRepositoryTestSuite) TestBackfillRomanceIndexes() {
 	ctx := context.Background()
 	repo := newRomancesRepository(ddbClient)
 
-	now := time.Now()
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(s.voteId.CountryId(), uuid.New())
+	s.Require().NoError(err)
+	voteId, err := sharedValueObject.NewVoteId(activeUserKey.CountryId(), activeUserKey.ActiveUserId(), activeUserKey.CountryId(), uuid.New())
+	s.Require().NoError(err)
 
-	// step 1: Creating romance with wrong peer vote part (the peer vote part is not synchronized with the db)
-	romance := romanceEntity.CreateEmptyRomance(s.voteId)
-	romance.PeerUserVote.VoteType = rvo.VoteTypeNo
-	romance.PeerUserVote.VotedAt = &now
+	firstPage, err := sharedValueObject.NewPageRequest(0, "")
+	s.Require().NoError(err)
 
-	// step 2: Adding vote
-	newRomance, err := repo.AddActiveUserVoteToRomance(ctx, romance, rvo.VoteTypeYes, now)
+	// step 1: A match written before the indexes were added is missing from the matches and likes
+	_, err = repo.AddActiveUserVoteToRomance(ctx, romanceEntity.CreateEmptyRomance(voteId), rvo.VoteTypeYes, "", time.Now())
+	s.Require().NoError(err)
+	peerRomance, err := repo.GetRomance(ctx, voteId.ToPeerVoteId())
+	s.Require().NoError(err)
+	_, err = repo.AddActiveUserVoteToRomance(ctx, peerRomance, rvo.VoteTypeYes, "", time.Now())
 	s.Require().NoError(err)
-	// The `AddActiveUserVoteToRomance` method returns a synchronized romance
-	s.assertRomanceInDbMatchesExpected(
-		newExpectedRomanceParams(s.voteId, rvo.VoteTypeYes, rvo.VoteTypeEmpty, 1),
-		newRomance,
-	)
-}
 
-func (s *RomancesRepositoryTestSuite) TestAddVoteWithDbException() {
-	ctx := context.Background()
+	regionRouter, err := platformDynamodb.NewRegionRouter(config.Load())
+	s.Require().NoError(err)
+	region := regionRouter.RegionByCountry(voteId.CountryId())
+	err = s.romancesTableHelper.RemoveRomanceIndexedAttrs(infraDynamodb.NewRomancePrimaryKey(voteId), region)
+	s.Require().NoError(err)
 
-	romance := romanceEntity.CreateEmptyRomance(s.voteId)
+	matches, err := repo.ListUserMatches(ctx, activeUserKey, firstPage)
+	s.Require().NoError(err)
+	s.Require().Empty(matches.Romances)
+	likes, err := repo.ListUserLikes(ctx, activeUserKey, nil, firstPage)
+	s.Require().NoError(err)
+	s.Require().Empty(likes.Romances)
 
-	ctrl := gomock.NewController(s.T())
-	mock := mocks.NewMockClient(ctrl)
+	romance, err := repo.GetRomance(ctx, voteId)
+	s.Require().NoError(err)
 
-	expectedErr := &types.InvalidEndpointException{}
+	// step 2: The backfill of all segments updates the match once and keeps its version
+	backfill := func() int {
+		updated := 0
+		totalSegments := int32(2)
+		for segment := int32(0); segment < totalSegments; segment++ {
+			cursor := ""
+			for {
+				pageRequest, err := sharedValueObject.NewPageRequest(2, cursor)
+				s.Require().NoError(err)
+
+				page, err := repo.BackfillRomanceIndexes(ctx, voteId.CountryId(), segment, totalSegments, pageRequest)
+				s.Require().NoError(err)
+				updated += page.Updated
+
+				if !page.HasMore() {
+					break
+				}
+				cursor = page.NextCursor
+			}
+		}
+		return updated
+	}
+	s.Require().Equal(1, backfill())
 
-	mock.EXPECT().
-		UpdateItem(ctx, gomock.Any(), gomock.Any()).
-		Return(nil, expectedErr)
+	matches, err = repo.ListUserMatches(ctx, activeUserKey, firstPage)
+	s.Require().NoError(err)
+	s.Require().Len(matches.Romances, 1)
+	likes, err = repo.ListUserLikes(ctx, activeUserKey, nil, firstPage)
+	s.Require().NoError(err)
+	s.Require().Len(likes.Romances, 1)
 
-	repo := newRomancesRepository(mock)
+	backfilledRomance, err := repo.GetRomance(ctx, voteId)
+	s.Require().NoError(err)
+	s.Require().Equal(romance.Version, backfilledRomance.Version)
 
-	// Adding a YES vote for the active user (getting client error)
-	newRomance, err := repo.AddActiveUserVoteToRomance(ctx, romance, rvo.VoteTypeYes, time.Now())
+	// step 3: The indexed romances are skipped
+	s.Require().Zero(backfill())
+
+	_, err = repo.BackfillRomanceIndexes(ctx, voteId.CountryId(), 2, 2, sharedValueObject.PageRequest{})
 	s.Require().Error(err)
-	s.Require().ErrorIs(err, expectedErr)
-	s.assertNilRomance(newRomance)
+
+	err = repo.DeleteRomance(ctx, voteId)
+	s.Require().NoError(err)
 }
 
-func (s *RomancesRepositoryTestSuite) TestDeleteRomance() {
+func (s *RomancesRepositoryTestSuite) TestListRomancesSkipsBlockedRomances() {
 	ctx := context.Background()
 	repo := newRomancesRepository(ddbClient)
+	listRomancesOperation := operation.NewListRomancesOperation(repo)
 
-	// step 1: Adding new Romance
-	romance := romanceEntity.CreateEmptyRomance(s.voteId)
-	_, err := repo.AddActiveUserVoteToRomance(ctx, romance, rvo.VoteTypeYes, time.Now())
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(s.voteId.CountryId(), uuid.New())
 	s.Require().NoError(err)
 
-	// step 2: Deleting this romance
-	err = repo.DeleteRomance(ctx, s.voteId)
-	s.Require().NoError(err)
+	voteIds := make([]sharedValueObject.VoteId, 3)
+	for i := range voteIds {
+		voteIds[i], err = sharedValueObject.NewVoteId(activeUserKey.CountryId(), activeUserKey.ActiveUserId(), activeUserKey.CountryId(), uuid.New())
+		s.Require().NoError(err)
+		romance, err := repo.AddActiveUserVoteToRomance(ctx, romanceEntity.CreateEmptyRomance(voteIds[i]), rvo.VoteTypeYes, "", time.Now())
+		s.Require().NoError(err)
+		if i > 0 {
+			_, err = repo.BlockRomance(ctx, romance)
+			s.Require().NoError(err)
+		}
+	}
 
-	// step 3: Check romance from active user and peer side
-	romance, err = repo.GetRomance(ctx, s.voteId)
+	// the page is filled with the romance which is not blocked wherever it is stored
+	pageRequest, err := sharedValueObject.NewPageRequest(1, "")
 	s.Require().NoError(err)
-	s.assertEmptyRomance(s.voteId, romance)
-
-	romance, err = repo.GetRomance(ctx, s.voteId.ToPeerVoteId())
+	page, err := listRomancesOperation.Run(ctx, activeUserKey, pageRequest)
 	s.Require().NoError(err)
-	s.assertEmptyRomance(s.voteId.ToPeerVoteId(), romance)
+	s.Require().Len(page.Romances, 1)
+	s.Require().Equal(voteIds[0].PeerUserId(), page.Romances[0].ActiveUserVote.Id.PeerUserId())
+
+	for _, voteId := range voteIds {
+		err = repo.DeleteRomance(ctx, voteId)
+		s.Require().NoError(err)
+	}
 }
 
-func (s *RomancesRepositoryTestSuite) TestDeleteRomanceFromPeerSide() {
+func (s *RomancesRepositoryTestSuite) TestBlockRomanceWithoutVotes() {
 	ctx := context.Background()
 	repo := newRomancesRepository(ddbClient)
 
-	// step 1: Adding new Romance
-	romance := romanceEntity.CreateEmptyRomance(s.voteId)
-	_, err := repo.AddActiveUserVoteToRomance(ctx, romance, rvo.VoteTypeYes, time.Now())
+	voteId, err := sharedValueObject.NewVoteId(s.voteId.CountryId(), uuid.New(), s.voteId.CountryId(), uuid.New())
 	s.Require().NoError(err)
 
-	// step 2: Deleting this romance from peer side
-	err = repo.DeleteRomance(ctx, s.voteId.ToPeerVoteId())
+	blockedRomance, err := repo.BlockRomance(ctx, romanceEntity.CreateEmptyRomance(voteId))
 	s.Require().NoError(err)
+	s.Require().True(blockedRomance.IsBlockedByActiveUser())
+	s.Require().True(blockedRomance.IsEmpty())
 
-	// step 3: Check romance from active user and peer side
-	romance, err = repo.GetRomance(ctx, s.voteId)
+	storedRomance, err := repo.GetRomance(ctx, voteId)
 	s.Require().NoError(err)
-	s.assertEmptyRomance(s.voteId, romance)
+	s.Require().Equal(blockedRomance, storedRomance)
 
-	romance, err = repo.GetRomance(ctx, s.voteId.ToPeerVoteId())
+	err = repo.DeleteRomance(ctx, voteId)
 	s.Require().NoError(err)
-	s.assertEmptyRomance(s.voteId.ToPeerVoteId(), romance)
 }
 
-func (s *RomancesRepositoryTestSuite) TestDeleteNotExistsRomance() {
+func (s *RomancesRepositoryTestSuite) TestCrossRegionCountersNotApplied() {
 	ctx := context.Background()
-	repo := newRomancesRepository(ddbClient)
 
-	err := repo.DeleteRomance(ctx, s.voteId)
+	appConfig := config.Load()
+	appConfig.DynamoDbRouting.Countries = map[uint16]string{33: "eu-west-1"}
+	appConfig.DynamoDbRouting.Regions["eu-west-1"] = config.DynamoDbRegionConfig{}
+	regionRouter, err := platformDynamodb.NewRegionRouter(appConfig)
 	s.Require().NoError(err)
-}
 
-func (s *RomancesRepositoryTestSuite) TestDeleteRomanceWithDbException() {
+	// the romance is kept in eu-west-1, so the counters of the voter from us-east-2 are written after the transaction
+	voteId, err := sharedValueObject.NewVoteId(44, uuid.New(), 33, uuid.New())
+	s.Require().NoError(err)
+	s.Require().Equal("eu-west-1", regionRouter.RomanceRegion(voteId.CountryId(), voteId.PeerCountryId()))
+
 	ctrl := gomock.NewController(s.T())
 	mock := mocks.NewMockClient(ctrl)
+	mock.EXPECT().TransactWriteItems(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(
+			ctx context.Context,
+			input *dynamodb.TransactWriteItemsInput,
+			optFns ...func(*dynamodb.Options),
+		) (*dynamodb.TransactWriteItemsOutput, error) {
+			options := dynamodb.Options{}
+			for _, fn := range optFns {
+				fn(&options)
+			}
+			if options.Region == "us-east-2" {
+				return nil, errors.New("unavailable")
+			}
+			return ddbClient.TransactWriteItems(ctx, input, optFns...)
+		},
+	).Times(2)
+
+	repo := infraDynamodb.NewRomancesRepository(
+		mock, regionRouter, appConfig, platform.NewClock(), slog.New(slog.NewTextHandler(io.Discard, nil)),
+	)
+	countersRepo := newCountersRepositoryWithConfig(ddbClient, appConfig)
 
-	ctx := context.Background()
+	counterUpdateGroup, err := counterValueObject.NewCounterUpdateGroup(time.Now())
+	s.Require().NoError(err)
+	countersChanges := []counterValueObject.VoteCountersChange{
+		counterValueObject.NewVoteTypeCountersChange(counterUpdateGroup, rvo.VoteTypeEmpty, rvo.VoteTypeYes, rvo.VoteTypeEmpty),
+	}
 
-	expectedErr := &types.InvalidEndpointException{}
+	// step 1: The vote is written and the failure of the counters of the other region is returned
+	changeId := uuid.New()
+	romance, err := repo.AddActiveUserVoteToRomanceWithCounters(
+		ctx,
+		romanceEntity.CreateEmptyRomance(voteId),
+		rvo.VoteTypeYes,
+		"",
+		time.Now(),
+		changeId,
+		countersChanges,
+		nil,
+	)
+	s.Require().ErrorIs(err, counterDomain.ErrCountersNotApplied)
+	s.Require().Equal(rvo.VoteTypeYes, romance.ActiveUserVote.VoteType)
 
-	mock.EXPECT().
-		DeleteItem(ctx, gomock.Any(), gomock.Any()).
-		Return(nil, expectedErr)
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(voteId.CountryId(), voteId.ActiveUserId())
+	s.Require().NoError(err)
+	peerUserKey, err := sharedValueObject.NewActiveUserKey(voteId.PeerCountryId(), voteId.PeerUserId())
+	s.Require().NoError(err)
 
-	repo := newRomancesRepository(mock)
+	activeUserCounters, err := countersRepo.GetLifetimeCounter(ctx, activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(0), activeUserCounters.OutgoingYes)
+	peerUserCounters, err := countersRepo.GetLifetimeCounter(ctx, peerUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(1), peerUserCounters.IncomingYes)
 
-	err := repo.DeleteRomance(ctx, s.voteId)
-	s.Require().Error(err)
-	s.Require().ErrorIs(err, expectedErr)
+	// step 2: Applying the change under the same ID only lands it in the region it failed in
+	err = countersRepo.ApplyVoteCountersChange(ctx, changeId, voteId, countersChanges)
+	s.Require().NoError(err)
+
+	activeUserCounters, err = countersRepo.GetLifetimeCounter(ctx, activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(1), activeUserCounters.OutgoingYes)
+	peerUserCounters, err = countersRepo.GetLifetimeCounter(ctx, peerUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(1), peerUserCounters.IncomingYes)
 }
 
-func (s *RomancesRepositoryTestSuite) TestDeleteActiveUserVoteFromRomance() {
+func (s *RomancesRepositoryTestSuite) TestCrossCountryRomance() {
 	ctx := context.Background()
 	repo := newRomancesRepository(ddbClient)
 
-	activeUserVoteId := s.voteId
-	peerUserVoteId := s.voteId.ToPeerVoteId()
-
-	// step 1: Adding new Romance and active user vote
-	emptyActiveUserRomance := romanceEntity.CreateEmptyRomance(activeUserVoteId)
-	_, err := repo.AddActiveUserVoteToRomance(ctx, emptyActiveUserRomance, rvo.VoteTypeYes, time.Now())
+	voteId, err := sharedValueObject.NewVoteId(44, uuid.New(), 33, uuid.New())
 	s.Require().NoError(err)
 
-	// step 2: Add vote to peer user side
-	peerRomance, err := repo.GetRomance(ctx, peerUserVoteId)
-	s.Require().NoError(err)
-	peerRomance, err = repo.AddActiveUserVoteToRomance(ctx, peerRomance, rvo.VoteTypeNo, time.Now())
+	votedAt := time.Now()
+	romance, err := repo.AddActiveUserVoteToRomance(ctx, romanceEntity.CreateEmptyRomance(voteId), rvo.VoteTypeYes, "", votedAt)
 	s.Require().NoError(err)
+	s.Require().Equal(voteId, romance.ActiveUserVote.Id)
 
-	s.assertRomanceInDbMatchesExpected(
-		newExpectedRomanceParams(peerUserVoteId, rvo.VoteTypeNo, rvo.VoteTypeYes, 2),
-		peerRomance,
-	)
-
-	// step 3: Deleting vote from peer user side
-	err = repo.DeleteActiveUserVoteFromRomance(ctx, peerRomance)
+	// the peer reads the same record from the other side
+	peerRomance, err := repo.GetRomance(ctx, voteId.ToPeerVoteId())
 	s.Require().NoError(err)
+	s.Require().Equal(romance.ToPeerRomance(), peerRomance)
+	s.Require().Equal(uint16(44), peerRomance.PeerUserVote.Id.CountryId())
 
-	peerRomance, err = repo.GetRomance(ctx, peerUserVoteId)
+	romanceKey := infraDynamodb.NewRomancePrimaryKey(voteId)
+	regionRouter, err := platformDynamodb.NewRegionRouter(config.Load())
 	s.Require().NoError(err)
-	s.assertRomanceInDbMatchesExpected(
-		newExpectedRomanceParams(peerUserVoteId, rvo.VoteTypeEmpty, rvo.VoteTypeYes, 3),
-		peerRomance,
-	)
-
-	// step 4: Deleting vote from active user side
-	activeUserRomance, err := repo.GetRomance(ctx, activeUserVoteId)
+	record, err := s.romancesTableHelper.GetRomanceTableRecord(romanceKey, regionRouter.RomanceRegion(voteId.CountryId(), voteId.PeerCountryId()))
 	s.Require().NoError(err)
+	if romanceKey.Pk == voteId.ActiveUserId() {
+		s.Require().Equal(uint16(44), record.PkUserCountryId)
+		s.Require().Equal(uint16(33), record.SkUserCountryId)
+	} else {
+		s.Require().Equal(uint16(33), record.PkUserCountryId)
+		s.Require().Equal(uint16(44), record.SkUserCountryId)
+	}
 
-	err = repo.DeleteActiveUserVoteFromRomance(ctx, activeUserRomance)
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(voteId.CountryId(), voteId.ActiveUserId())
+	s.Require().NoError(err)
+	pageRequest, err := sharedValueObject.NewPageRequest(10, "")
 	s.Require().NoError(err)
+	page, err := repo.ListUserRomances(ctx, activeUserKey, pageRequest)
+	s.Require().NoError(err)
+	s.Require().Len(page.Romances, 1)
+	s.Require().Equal(voteId, page.Romances[0].ActiveUserVote.Id)
 
-	activeUserRomance, err = repo.GetRomance(ctx, activeUserVoteId)
+	err = repo.DeleteRomance(ctx, voteId)
 	s.Require().NoError(err)
-	s.assertRomanceInDbMatchesExpected(
-		newExpectedRomanceParams(activeUserVoteId, rvo.VoteTypeEmpty, rvo.VoteTypeEmpty, 4),
-		activeUserRomance,
-	)
 }
 
 func (s *RomancesRepositoryTestSuite) TestDeleteActiveUserVoteFromEmptyRomance() {
@@ -416,7 +1588,7 @@ func (s *RomancesRepositoryTestSuite) TestDeleteActiveUserVoteWithDbException()
 
 	repo := newRomancesRepository(ddbClient)
 	emptyActiveUserRomance := romanceEntity.CreateEmptyRomance(s.voteId)
-	activeUserRomance, err := repo.AddActiveUserVoteToRomance(ctx, emptyActiveUserRomance, rvo.VoteTypeYes, time.Now())
+	activeUserRomance, err := repo.AddActiveUserVoteToRomance(ctx, emptyActiveUserRomance, rvo.VoteTypeYes, "", time.Now())
 	s.Require().NoError(err)
 
 	expectedErr := &types.InvalidEndpointException{}
@@ -441,11 +1613,11 @@ func (s *RomancesRepositoryTestSuite) TestChangeActiveUserVoteTypeInRomance() {
 
 	// step 1: Adding new Romance
 	romance := romanceEntity.CreateEmptyRomance(activeUserVoteId)
-	romance, err := repo.AddActiveUserVoteToRomance(ctx, romance, rvo.VoteTypeNo, time.Now())
+	romance, err := repo.AddActiveUserVoteToRomance(ctx, romance, rvo.VoteTypeNo, "", time.Now())
 	s.Require().NoError(err)
 
 	// step 2: Changing active user vote type
-	newRomance, err := repo.ChangeActiveUserVoteTypeInRomance(ctx, romance, rvo.VoteTypeYes)
+	newRomance, err := repo.ChangeActiveUserVoteTypeInRomance(ctx, romance, rvo.VoteTypeYes, "")
 	s.Require().NoError(err)
 	s.assertRomanceInDbMatchesExpected(
 		newExpectedRomanceParams(activeUserVoteId, rvo.VoteTypeYes, rvo.VoteTypeEmpty, 2),
@@ -455,11 +1627,11 @@ func (s *RomancesRepositoryTestSuite) TestChangeActiveUserVoteTypeInRomance() {
 	// step 3: Adding peer vote
 	peerRomance, err := repo.GetRomance(ctx, peerUserVoteId)
 	s.Require().NoError(err)
-	peerRomance, err = repo.AddActiveUserVoteToRomance(ctx, peerRomance, rvo.VoteTypeYes, time.Now())
+	peerRomance, err = repo.AddActiveUserVoteToRomance(ctx, peerRomance, rvo.VoteTypeYes, "", time.Now())
 	s.Require().NoError(err)
 
 	// step 4: Changing peer user vote type
-	newPeerRomance, err := repo.ChangeActiveUserVoteTypeInRomance(ctx, peerRomance, rvo.VoteTypeCrush)
+	newPeerRomance, err := repo.ChangeActiveUserVoteTypeInRomance(ctx, peerRomance, rvo.VoteTypeCrush, "")
 	s.Require().NoError(err)
 	s.assertRomanceInDbMatchesExpected(
 		newExpectedRomanceParams(peerUserVoteId, rvo.VoteTypeCrush, rvo.VoteTypeYes, 4),
@@ -472,7 +1644,7 @@ func (s *RomancesRepositoryTestSuite) TestChangeActiveUserVoteTypeInEmptyRomance
 	repo := newRomancesRepository(ddbClient)
 
 	romance := romanceEntity.CreateEmptyRomance(s.voteId)
-	newRomance, err := repo.ChangeActiveUserVoteTypeInRomance(ctx, romance, rvo.VoteTypeNo)
+	newRomance, err := repo.ChangeActiveUserVoteTypeInRomance(ctx, romance, rvo.VoteTypeNo, "")
 	s.Require().Error(err)
 	s.Require().ErrorIs(err, romanceDomain.ErrVoteNotFound)
 	s.assertNilRomance(newRomance)
@@ -503,11 +1675,11 @@ func (s *RomancesRepositoryTestSuite) TestChangeActiveUserVoteTypeInRomanceVaria
 		err := repo.DeleteRomance(ctx, activeUserVoteId)
 		s.Require().NoError(err)
 		romance := romanceEntity.CreateEmptyRomance(activeUserVoteId)
-		romance, err = repo.AddActiveUserVoteToRomance(ctx, romance, c.fromVote, time.Now())
+		romance, err = repo.AddActiveUserVoteToRomance(ctx, romance, c.fromVote, "", time.Now())
 		s.Require().NoError(err)
 
 		// step 2: Changing active user vote type
-		newRomance, err := repo.ChangeActiveUserVoteTypeInRomance(ctx, romance, c.toVote)
+		newRomance, err := repo.ChangeActiveUserVoteTypeInRomance(ctx, romance, c.toVote, "")
 		s.Require().NoError(err)
 		s.assertRomanceInDbMatchesExpected(
 			newExpectedRomanceParams(activeUserVoteId, c.toVote, rvo.VoteTypeEmpty, 2),
@@ -526,11 +1698,11 @@ func (s *RomancesRepositoryTestSuite) TestChangeActiveUserVoteTypeInRomanceToEmp
 	err := repo.DeleteRomance(ctx, activeUserVoteId)
 	s.Require().NoError(err)
 	romance := romanceEntity.CreateEmptyRomance(activeUserVoteId)
-	romance, err = repo.AddActiveUserVoteToRomance(ctx, romance, rvo.VoteTypeYes, time.Now())
+	romance, err = repo.AddActiveUserVoteToRomance(ctx, romance, rvo.VoteTypeYes, "", time.Now())
 	s.Require().NoError(err)
 
 	// step 2: Changing active user vote type to empty
-	newRomance, err := repo.ChangeActiveUserVoteTypeInRomance(ctx, romance, rvo.VoteTypeEmpty)
+	newRomance, err := repo.ChangeActiveUserVoteTypeInRomance(ctx, romance, rvo.VoteTypeEmpty, "")
 	s.Require().Error(err)
 	s.Require().ErrorIs(err, romanceDomain.ErrWrongVote)
 	s.assertNilRomance(newRomance)
@@ -547,7 +1719,7 @@ func (s *RomancesRepositoryTestSuite) TestChangeActiveUserVoteTypeInRomanceWithD
 
 	// step 1: Adding new Romance
 	romance := romanceEntity.CreateEmptyRomance(activeUserVoteId)
-	romance, err := repo.AddActiveUserVoteToRomance(ctx, romance, rvo.VoteTypeNo, time.Now())
+	romance, err := repo.AddActiveUserVoteToRomance(ctx, romance, rvo.VoteTypeNo, "", time.Now())
 	s.Require().NoError(err)
 
 	expectedErr := &types.InvalidEndpointException{}
@@ -559,7 +1731,7 @@ func (s *RomancesRepositoryTestSuite) TestChangeActiveUserVoteTypeInRomanceWithD
 	repo = newRomancesRepository(mock)
 
 	// step 2: Changing active user vote type with db error
-	newRomance, err := repo.ChangeActiveUserVoteTypeInRomance(ctx, romance, rvo.VoteTypeYes)
+	newRomance, err := repo.ChangeActiveUserVoteTypeInRomance(ctx, romance, rvo.VoteTypeYes, "")
 	s.Require().Error(err)
 	s.Require().ErrorIs(err, expectedErr)
 	s.assertNilRomance(newRomance)
@@ -643,7 +1815,9 @@ func (s *RomancesRepositoryTestSuite) assertRomanceInDbMatchesExpected(
 
 	voteId := romanceToCheck.ActiveUserVote.Id
 	romanceKey := infraDynamodb.NewRomancePrimaryKey(voteId)
-	dynamodbRegion := platformDynamodb.GetDynamodbRegionByCountry(voteId.CountryId())
+	regionRouter, err := platformDynamodb.NewRegionRouter(config.Load())
+	s.Require().NoError(err)
+	dynamodbRegion := regionRouter.RegionByCountry(voteId.CountryId())
 	record, err := s.romancesTableHelper.GetRomanceTableRecord(romanceKey, dynamodbRegion)
 	s.Require().NoError(err)
 	assertRomanceDbRecord(s.T(), record, romanceToCheck)
@@ -652,7 +1826,11 @@ func (s *RomancesRepositoryTestSuite) assertRomanceInDbMatchesExpected(
 func newRomancesRepository(client platformDynamodb.Client) romanceRepository.RomancesRepository {
 	appConfig := config.Load()
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
-	return infraDynamodb.NewRomancesRepository(client, appConfig, logger)
+	regionRouter, err := platformDynamodb.NewRegionRouter(appConfig)
+	if err != nil {
+		panic(err)
+	}
+	return infraDynamodb.NewRomancesRepository(client, regionRouter, appConfig, platform.NewClock(), logger)
 }
 
 func assertRomanceDbRecord(
@@ -678,11 +1856,13 @@ func assertRomanceDbRecord(
 
 	expected := map[string]any{
 		"pkUserVoteType":      record.PkUserVoteType,
+		"pkUserMessage":       record.PkUserMessage,
 		"pkUserVotedAt":       timeutil.UnixToTimePtr(record.PkUserVotedAt),
 		"pkUserVoteCreatedAt": timeutil.UnixToTimePtr(record.PkUserVoteCreatedAt),
 		"pkUserVoteUpdatedAt": timeutil.UnixToTimePtr(record.PkUserVoteUpdatedAt),
 		"skUserId":            record.SkUserId,
 		"skUserVoteType":      record.SkUserVoteType,
+		"skUserMessage":       record.SkUserMessage,
 		"skUserVotedAt":       timeutil.UnixToTimePtr(record.SkUserVotedAt),
 		"skUserVoteCreatedAt": timeutil.UnixToTimePtr(record.SkUserVoteCreatedAt),
 		"skUserVoteUpdatedAt": timeutil.UnixToTimePtr(record.SkUserVoteUpdatedAt),
@@ -691,11 +1871,13 @@ func assertRomanceDbRecord(
 
 	actual := map[string]any{
 		"pkUserVoteType":      pkUserVote.VoteType,
+		"pkUserMessage":       string(pkUserVote.Message),
 		"pkUserVotedAt":       pkUserVote.VotedAt,
 		"pkUserVoteCreatedAt": pkUserVote.CreatedAt,
 		"pkUserVoteUpdatedAt": pkUserVote.UpdatedAt,
 		"skUserId":            skUserVote.Id.ActiveUserId().String(),
 		"skUserVoteType":      skUserVote.VoteType,
+		"skUserMessage":       string(skUserVote.Message),
 		"skUserVotedAt":       skUserVote.VotedAt,
 		"skUserVoteCreatedAt": skUserVote.CreatedAt,
 		"skUserVoteUpdatedAt": skUserVote.UpdatedAt,
@@ -0,0 +1,138 @@
+package persistence
+
+import (
+	"context"
+	"github.bumble.dev/shcherbanich/user-votes-storage/config"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/application/operation"
+	counterEntity "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/entity"
+	rvo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
+	sharedValueObject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	infraDynamodb "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/infrastructure/persistence"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
+	platformDynamodb "github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform/dynamodb"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/testlib/helper"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type RomancesStreamReaderTestSuite struct {
+	suite.Suite
+	countersTableHelper *helper.CountersTableHelper
+	appConfig           config.Config
+}
+
+func TestRomancesStreamReaderTestSuite(t *testing.T) {
+	suite.Run(t, new(RomancesStreamReaderTestSuite))
+}
+
+func (s *RomancesStreamReaderTestSuite) SetupSuite() {
+	romancesTableHelper, err := helper.NewRomancesTableHelper(ddbClient)
+	s.Require().NoError(err)
+	err = romancesTableHelper.CreateRomancesTable()
+	s.Require().NoError(err)
+
+	countersTableHelper, err := helper.NewCountersTableHelper(ddbClient)
+	s.Require().NoError(err)
+	err = countersTableHelper.CreateCountersTable()
+	s.Require().NoError(err)
+	s.countersTableHelper = countersTableHelper
+
+	s.appConfig = config.Load()
+	s.appConfig.Counters.UpdateMode = config.CountersUpdateModeStream
+	s.appConfig.Counters.Stream.PollInterval = 100 * time.Millisecond
+}
+
+func (s *RomancesStreamReaderTestSuite) TestCountersProjection() {
+	ctx := context.Background()
+	romancesRepo := s.newRomancesRepository()
+
+	voteId, err := sharedValueObject.NewVoteId(11, uuid.New(), 11, uuid.New())
+	s.Require().NoError(err)
+	activeUserKey, err := sharedValueObject.NewActiveUserKey(voteId.CountryId(), voteId.ActiveUserId())
+	s.Require().NoError(err)
+	peerUserKey, err := sharedValueObject.NewActiveUserKey(voteId.PeerCountryId(), voteId.PeerUserId())
+	s.Require().NoError(err)
+
+	// step 1: The votes written without counters are counted from the stream, the match is counted once per user
+	romance, err := romancesRepo.GetRomance(ctx, voteId)
+	s.Require().NoError(err)
+	_, err = romancesRepo.AddActiveUserVoteToRomanceWithCounters(ctx, romance, rvo.VoteTypeYes, "", time.Now(), uuid.New(), nil, nil)
+	s.Require().NoError(err)
+
+	peerRomance, err := romancesRepo.GetRomance(ctx, voteId.ToPeerVoteId())
+	s.Require().NoError(err)
+	_, err = romancesRepo.AddActiveUserVoteToRomanceWithCounters(ctx, peerRomance, rvo.VoteTypeYes, "", time.Now(), uuid.New(), nil, nil)
+	s.Require().NoError(err)
+
+	stopReader := s.startReader(romancesRepo)
+	s.eventuallyCounters(activeUserKey, func(countersGroup counterEntity.CountersGroup) bool {
+		return countersGroup.OutgoingYes == 1 && countersGroup.IncomingYes == 1 && countersGroup.Matches == 1
+	})
+	s.eventuallyCounters(peerUserKey, func(countersGroup counterEntity.CountersGroup) bool {
+		return countersGroup.OutgoingYes == 1 && countersGroup.IncomingYes == 1 && countersGroup.Matches == 1
+	})
+	stopReader()
+
+	// step 2: A restarted reader goes on after the checkpoint and counts the change of the vote type in place
+	romance, err = romancesRepo.GetRomance(ctx, voteId)
+	s.Require().NoError(err)
+	_, err = romancesRepo.ChangeActiveUserVoteTypeInRomance(ctx, romance, rvo.VoteTypeCrush, "")
+	s.Require().NoError(err)
+
+	stopReader = s.startReader(romancesRepo)
+	s.eventuallyCounters(activeUserKey, func(countersGroup counterEntity.CountersGroup) bool {
+		return countersGroup.OutgoingByVoteType.Crush == 1 && countersGroup.OutgoingByVoteType.Yes == 0
+	})
+	stopReader()
+
+	countersGroup, err := newCountersRepository(ddbClient).GetLifetimeCounter(ctx, activeUserKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint32(1), countersGroup.OutgoingYes)
+	s.Require().Equal(uint32(1), countersGroup.Matches)
+
+	err = s.countersTableHelper.DeleteAllUserRecords(activeUserKey)
+	s.Require().NoError(err)
+	err = s.countersTableHelper.DeleteAllUserRecords(peerUserKey)
+	s.Require().NoError(err)
+	err = romancesRepo.DeleteRomance(ctx, voteId)
+	s.Require().NoError(err)
+}
+
+func (s *RomancesStreamReaderTestSuite) startReader(romancesRepo *infraDynamodb.RomancesRepository) (stop func()) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	reader := infraDynamodb.NewRomancesStreamReader(ddbClient, ddbStreamsClient, romancesRepo, s.appConfig, platform.NewClock(), logger)
+	projectRomanceCountersOperation := operation.NewProjectRomanceCountersOperation(newCountersRepositoryWithConfig(ddbClient, s.appConfig), platform.NewClock())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- reader.Read(ctx, projectRomanceCountersOperation.Run)
+	}()
+
+	return func() {
+		cancel()
+		s.Require().ErrorIs(<-done, context.Canceled)
+	}
+}
+
+func (s *RomancesStreamReaderTestSuite) eventuallyCounters(
+	activeUserKey sharedValueObject.ActiveUserKey,
+	condition func(countersGroup counterEntity.CountersGroup) bool,
+) {
+	countersRepo := newCountersRepository(ddbClient)
+	s.Require().Eventually(func() bool {
+		countersGroup, err := countersRepo.GetLifetimeCounter(context.Background(), activeUserKey)
+		return err == nil && condition(countersGroup)
+	}, 30*time.Second, 200*time.Millisecond)
+}
+
+func (s *RomancesStreamReaderTestSuite) newRomancesRepository() *infraDynamodb.RomancesRepository {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	regionRouter, err := platformDynamodb.NewRegionRouter(s.appConfig)
+	s.Require().NoError(err)
+	return infraDynamodb.NewRomancesRepository(ddbClient, regionRouter, s.appConfig, platform.NewClock(), logger)
+}
@@ -0,0 +1,109 @@
+package policy
+
+import (
+	"github.bumble.dev/shcherbanich/user-votes-storage/config"
+	rvo "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/interface/api/rest/v1/contract"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type VoteTransitionPolicyTestSuite struct {
+	suite.Suite
+}
+
+func TestVoteTransitionPolicyTestSuite(t *testing.T) {
+	suite.Run(t, new(VoteTransitionPolicyTestSuite))
+}
+
+func (s *VoteTransitionPolicyTestSuite) TestDefaultTransitions() {
+	policy, err := rvo.NewVoteTransitionPolicy(config.Load())
+	s.Require().NoError(err)
+
+	s.Require().True(policy.IsAllowed(11, rvo.VoteTypeEmpty, rvo.VoteTypeYes))
+	s.Require().True(policy.IsAllowed(11, rvo.VoteTypeNo, rvo.VoteTypeYes))
+	s.Require().True(policy.IsAllowed(11, rvo.VoteTypeCrush, rvo.VoteTypeEmpty))
+	s.Require().False(policy.IsAllowed(11, rvo.VoteTypeCrush, rvo.VoteTypeYes))
+	s.Require().False(policy.IsAllowed(11, rvo.VoteTypeYes, rvo.VoteTypeNo))
+
+	s.Require().Equal(
+		[]rvo.VoteType{rvo.VoteTypeYes, rvo.VoteTypeNo, rvo.VoteTypeCrush, rvo.VoteTypeCompliment},
+		policy.AddableVoteTypes(),
+	)
+	s.Require().Equal(
+		[]rvo.VoteType{rvo.VoteTypeYes, rvo.VoteTypeCrush, rvo.VoteTypeCompliment},
+		policy.ChangeableVoteTypes(),
+	)
+}
+
+func (s *VoteTransitionPolicyTestSuite) TestEnvironmentIsMergedIntoDefaults() {
+	s.T().Setenv("VOTE_TRANSITIONS", `{"default":{"crush":["yes","empty"]},"countries":{"44":{"no":["yes"]}}}`)
+
+	policy, err := rvo.NewVoteTransitionPolicy(config.Load())
+	s.Require().NoError(err)
+
+	// the listed default is replaced, the rest of the defaults is kept
+	s.Require().True(policy.IsAllowed(11, rvo.VoteTypeCrush, rvo.VoteTypeYes))
+	s.Require().True(policy.IsAllowed(11, rvo.VoteTypeYes, rvo.VoteTypeCrush))
+	s.Require().True(policy.IsAllowed(11, rvo.VoteTypeNo, rvo.VoteTypeCrush))
+
+	// the country override replaces the targets of the vote types it lists only
+	s.Require().True(policy.IsAllowed(44, rvo.VoteTypeNo, rvo.VoteTypeYes))
+	s.Require().False(policy.IsAllowed(44, rvo.VoteTypeNo, rvo.VoteTypeCrush))
+	s.Require().True(policy.IsAllowed(44, rvo.VoteTypeCrush, rvo.VoteTypeYes))
+	s.Require().True(policy.IsAllowed(44, rvo.VoteTypeYes, rvo.VoteTypeCompliment))
+}
+
+func (s *VoteTransitionPolicyTestSuite) TestMalformedEnvironmentIsRejected() {
+	s.T().Setenv("VOTE_TRANSITIONS", `{"default":`)
+
+	s.Require().Panics(func() {
+		config.Load()
+	})
+}
+
+func (s *VoteTransitionPolicyTestSuite) TestVoteTypeAllowedInOneCountryOnly() {
+	conf := config.Load()
+	conf.VoteTransitions.Default = config.VoteTransitionRules{
+		"empty": {"yes", "no"},
+		"no":    {"yes", "empty"},
+		"yes":   {"empty"},
+	}
+	conf.VoteTransitions.Countries = map[uint16]config.VoteTransitionRules{
+		44: {"empty": {"yes", "no", "crush"}, "crush": {"empty"}},
+	}
+
+	policy, err := rvo.NewVoteTransitionPolicy(conf)
+	s.Require().NoError(err)
+
+	s.Require().False(policy.IsAllowed(11, rvo.VoteTypeEmpty, rvo.VoteTypeCrush))
+	s.Require().True(policy.IsAllowed(44, rvo.VoteTypeEmpty, rvo.VoteTypeCrush))
+	s.Require().Equal([]rvo.VoteType{rvo.VoteTypeYes, rvo.VoteTypeNo, rvo.VoteTypeCrush}, policy.AddableVoteTypes())
+	s.Require().Equal([]rvo.VoteType{rvo.VoteTypeYes}, policy.ChangeableVoteTypes())
+
+	// the contracts accept the vote types allowed in at least one country
+	s.Require().NoError(contract.ValidateAddUserVoteType(policy, contract.AddUserVoteType(rvo.VoteTypeCrush), "body.vote_type"))
+	s.Require().Error(contract.ValidateAddUserVoteType(policy, contract.AddUserVoteType(rvo.VoteTypeCompliment), "body.vote_type"))
+	s.Require().NoError(contract.ValidateChangeUserVoteType(policy, contract.ChangeUserVoteType(rvo.VoteTypeYes), "body.new_vote_type"))
+	s.Require().Error(contract.ValidateChangeUserVoteType(policy, contract.ChangeUserVoteType(rvo.VoteTypeCrush), "body.new_vote_type"))
+}
+
+func (s *VoteTransitionPolicyTestSuite) TestInvalidRulesAreRejected() {
+	for name, rules := range map[string]config.VoteTransitionRules{
+		`unknown vote type "maybe" in vote transitions`:          {"maybe": {"yes"}},
+		`unknown vote type "maybe" in vote transitions of "yes"`: {"yes": {"maybe"}},
+		`vote type "crush" can not be changed to itself`:         {"crush": {"crush"}},
+	} {
+		conf := config.Load()
+		conf.VoteTransitions.Countries = map[uint16]config.VoteTransitionRules{44: rules}
+
+		_, err := rvo.NewVoteTransitionPolicy(conf)
+		s.Require().ErrorContains(err, "country 44: "+name)
+
+		conf = config.Load()
+		conf.VoteTransitions.Default = rules
+
+		_, err = rvo.NewVoteTransitionPolicy(conf)
+		s.Require().ErrorContains(err, name)
+	}
+}
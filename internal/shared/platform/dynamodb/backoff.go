@@ -0,0 +1,18 @@
+package dynamodb
+
+import "time"
+
+const (
+	unprocessedItemsBaseBackoff = 50 * time.Millisecond
+	unprocessedItemsMaxBackoff  = 2 * time.Second
+)
+
+func UnprocessedItemsBackoff(attempt int) time.Duration {
+	if attempt < 0 {
+		attempt = 0
+	}
+	if attempt > 10 {
+		return unprocessedItemsMaxBackoff
+	}
+	return min(unprocessedItemsBaseBackoff<<attempt, unprocessedItemsMaxBackoff)
+}
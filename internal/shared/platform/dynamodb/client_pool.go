@@ -0,0 +1,143 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+
+	appConfig "github.bumble.dev/shcherbanich/user-votes-storage/config"
+	"github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// ClientPool sends every call to the client of the region set by the call options.
+type ClientPool struct {
+	clients       map[string]Client
+	defaultRegion string
+}
+
+func NewClientPool(conf appConfig.Config, logger platform.Logger) (*ClientPool, error) {
+	clients := make(map[string]Client, len(conf.DynamoDbRouting.Regions))
+	for region, regionConf := range conf.DynamoDbRouting.Regions {
+		client, err := newRegionClient(conf, region, regionConf)
+		if err != nil {
+			logger.Error(err.Error())
+			return nil, err
+		}
+		clients[region] = client
+	}
+
+	if _, ok := clients[conf.DynamoDbRouting.DefaultRegion]; !ok {
+		return nil, fmt.Errorf("default dynamodb region %q is not configured", conf.DynamoDbRouting.DefaultRegion)
+	}
+
+	return &ClientPool{
+		clients:       clients,
+		defaultRegion: conf.DynamoDbRouting.DefaultRegion,
+	}, nil
+}
+
+func (p *ClientPool) getClient(optFns []func(*dynamodb.Options)) (Client, error) {
+	var options dynamodb.Options
+	for _, optFn := range optFns {
+		optFn(&options)
+	}
+
+	region := options.Region
+	if region == "" {
+		region = p.defaultRegion
+	}
+
+	client, ok := p.clients[region]
+	if !ok {
+		return nil, fmt.Errorf("no dynamodb client for region %q", region)
+	}
+	return client, nil
+}
+
+func (p *ClientPool) CreateTable(ctx context.Context, params *dynamodb.CreateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error) {
+	client, err := p.getClient(optFns)
+	if err != nil {
+		return nil, err
+	}
+	return client.CreateTable(ctx, params, optFns...)
+}
+
+func (p *ClientPool) DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	client, err := p.getClient(optFns)
+	if err != nil {
+		return nil, err
+	}
+	return client.DescribeTable(ctx, params, optFns...)
+}
+
+func (p *ClientPool) PutItem(ctx context.Context, in *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	client, err := p.getClient(optFns)
+	if err != nil {
+		return nil, err
+	}
+	return client.PutItem(ctx, in, optFns...)
+}
+
+func (p *ClientPool) GetItem(ctx context.Context, in *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	client, err := p.getClient(optFns)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetItem(ctx, in, optFns...)
+}
+
+func (p *ClientPool) UpdateItem(ctx context.Context, in *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	client, err := p.getClient(optFns)
+	if err != nil {
+		return nil, err
+	}
+	return client.UpdateItem(ctx, in, optFns...)
+}
+
+func (p *ClientPool) DeleteItem(ctx context.Context, in *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	client, err := p.getClient(optFns)
+	if err != nil {
+		return nil, err
+	}
+	return client.DeleteItem(ctx, in, optFns...)
+}
+
+func (p *ClientPool) Query(ctx context.Context, in *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	client, err := p.getClient(optFns)
+	if err != nil {
+		return nil, err
+	}
+	return client.Query(ctx, in, optFns...)
+}
+
+func (p *ClientPool) Scan(ctx context.Context, in *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	client, err := p.getClient(optFns)
+	if err != nil {
+		return nil, err
+	}
+	return client.Scan(ctx, in, optFns...)
+}
+
+func (p *ClientPool) TransactWriteItems(ctx context.Context, in *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	client, err := p.getClient(optFns)
+	if err != nil {
+		return nil, err
+	}
+	return client.TransactWriteItems(ctx, in, optFns...)
+}
+
+func (p *ClientPool) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(options *dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	client, err := p.getClient(optFns)
+	if err != nil {
+		return nil, err
+	}
+	return client.BatchWriteItem(ctx, params, optFns...)
+}
+
+func (p *ClientPool) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(options *dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	client, err := p.getClient(optFns)
+	if err != nil {
+		return nil, err
+	}
+	return client.BatchGetItem(ctx, params, optFns...)
+}
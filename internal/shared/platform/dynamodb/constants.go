@@ -1,3 +1,7 @@
 package dynamodb
 
-const TtlAttrName = "ttl"
+const (
+	TtlAttrName          = "ttl"
+	BatchWriteItemsLimit = 25
+	BatchGetItemsLimit   = 100
+)
@@ -0,0 +1,80 @@
+package dynamodb
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	appConfig "github.bumble.dev/shcherbanich/user-votes-storage/config"
+)
+
+// RegionRouter resolves the DynamoDB region which holds the data of a country.
+type RegionRouter struct {
+	defaultRegion string
+	countries     map[uint16]string
+	// every configured region ordered by the priority of keeping the romances of users from different regions
+	regionsByPriority []string
+}
+
+// NewRegionRouter fails when the default region or a region a country is mapped to is not configured.
+func NewRegionRouter(conf appConfig.Config) (RegionRouter, error) {
+	routing := conf.DynamoDbRouting
+	if _, ok := routing.Regions[routing.DefaultRegion]; !ok {
+		return RegionRouter{}, fmt.Errorf("default dynamodb region %q is not configured", routing.DefaultRegion)
+	}
+
+	for countryId, region := range routing.Countries {
+		if _, ok := routing.Regions[region]; !ok {
+			return RegionRouter{}, fmt.Errorf("dynamodb region %q of country %d is not configured", region, countryId)
+		}
+	}
+
+	regionsByPriority := make([]string, 0, len(routing.Regions))
+	for _, region := range routing.RegionPriority {
+		if _, ok := routing.Regions[region]; !ok {
+			return RegionRouter{}, fmt.Errorf("prioritized dynamodb region %q is not configured", region)
+		}
+		if slices.Contains(regionsByPriority, region) {
+			return RegionRouter{}, fmt.Errorf("dynamodb region %q is prioritized twice", region)
+		}
+		regionsByPriority = append(regionsByPriority, region)
+	}
+	for _, region := range slices.Sorted(maps.Keys(routing.Regions)) {
+		if !slices.Contains(regionsByPriority, region) {
+			regionsByPriority = append(regionsByPriority, region)
+		}
+	}
+
+	return RegionRouter{
+		defaultRegion:     routing.DefaultRegion,
+		countries:         routing.Countries,
+		regionsByPriority: regionsByPriority,
+	}, nil
+}
+
+func (r RegionRouter) RegionByCountry(countryId uint16) string {
+	if region, ok := r.countries[countryId]; ok {
+		return region
+	}
+	return r.defaultRegion
+}
+
+// RomanceRegion returns the region keeping the romance of the users of both countries.
+func (r RegionRouter) RomanceRegion(countryId uint16, peerCountryId uint16) string {
+	region := r.RegionByCountry(countryId)
+	peerRegion := r.RegionByCountry(peerCountryId)
+	if r.priority(peerRegion) < r.priority(region) {
+		return peerRegion
+	}
+	return region
+}
+
+// UserRomanceRegions returns every region which may keep a romance of a user of the country.
+func (r RegionRouter) UserRomanceRegions(countryId uint16) []string {
+	region := r.RegionByCountry(countryId)
+	return append([]string{region}, r.regionsByPriority[:r.priority(region)]...)
+}
+
+func (r RegionRouter) priority(region string) int {
+	return slices.Index(r.regionsByPriority, region)
+}
@@ -0,0 +1,33 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+
+	appConfig "github.bumble.dev/shcherbanich/user-votes-storage/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+)
+
+//go:generate mockgen -destination=../../../testlib/mocks/dynamodb_streams_client_mock.go -package=mocks github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform/dynamodb StreamsClient
+type StreamsClient interface {
+	DescribeStream(ctx context.Context, params *dynamodbstreams.DescribeStreamInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.DescribeStreamOutput, error)
+	GetShardIterator(ctx context.Context, params *dynamodbstreams.GetShardIteratorInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetShardIteratorOutput, error)
+	GetRecords(ctx context.Context, params *dynamodbstreams.GetRecordsInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetRecordsOutput, error)
+}
+
+// NewStreamsClient returns the client of the streams of the region the counters stream is read in.
+func NewStreamsClient(conf appConfig.Config) (StreamsClient, error) {
+	region := conf.Counters.Stream.RegionOrDefault(conf.DynamoDbRouting.DefaultRegion)
+
+	regionConf, ok := conf.DynamoDbRouting.Regions[region]
+	if !ok {
+		return nil, fmt.Errorf("dynamodb region %q of the counters stream is not configured", region)
+	}
+
+	cfg, err := loadRegionAwsConfig(conf, region, regionConf)
+	if err != nil {
+		return nil, err
+	}
+
+	return dynamodbstreams.NewFromConfig(cfg), nil
+}
@@ -0,0 +1,51 @@
+package platform
+
+import (
+	"expvar"
+	"time"
+)
+
+var metricsRoot = expvar.NewMap("metrics")
+
+//go:generate mockgen -destination=../../testlib/mocks/metrics_mock.go -package=mocks github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform Metrics
+type Metrics interface {
+	SetGauge(name string, value float64)
+	ObserveDuration(name string, duration time.Duration)
+}
+
+type expvarMetrics struct {
+	root *expvar.Map
+}
+
+func NewMetrics() Metrics {
+	return expvarMetrics{root: metricsRoot}
+}
+
+func (m expvarMetrics) SetGauge(name string, value float64) {
+	gauge, ok := m.root.Get(name).(*expvar.Float)
+	if !ok {
+		gauge = new(expvar.Float)
+		m.root.Set(name, gauge)
+	}
+	gauge.Set(value)
+}
+
+// ObserveDuration keeps the count, the total and the last of the observed durations.
+func (m expvarMetrics) ObserveDuration(name string, duration time.Duration) {
+	summary, ok := m.root.Get(name).(*expvar.Map)
+	if !ok {
+		summary = new(expvar.Map)
+		m.root.Set(name, summary)
+	}
+
+	seconds := duration.Seconds()
+	summary.Add("count", 1)
+	summary.AddFloat("sum_seconds", seconds)
+
+	last, ok := summary.Get("last_seconds").(*expvar.Float)
+	if !ok {
+		last = new(expvar.Float)
+		summary.Set("last_seconds", last)
+	}
+	last.Set(seconds)
+}
@@ -1,16 +1,32 @@
 package timeutil
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 const (
 	HourSeconds = 3600
 	DaySeconds  = 24 * HourSeconds
+	WeekSeconds = 7 * DaySeconds
 )
 
 func HourStart(t time.Time) time.Time {
 	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, time.UTC)
 }
 
+func DayStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// WeekStart returns the Monday starting the ISO week of t in UTC.
+func WeekStart(t time.Time) time.Time {
+	dayStart := DayStart(t)
+	daysSinceMonday := (int(dayStart.Weekday()) + 6) % 7
+	return dayStart.AddDate(0, 0, -daysSinceMonday)
+}
+
 func UnixToTimePtr(from *int32) *time.Time {
 	if from == nil {
 		return nil
@@ -19,3 +35,15 @@ func UnixToTimePtr(from *int32) *time.Time {
 	to := time.Unix(int64(*from), 0).UTC()
 	return &to
 }
+
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
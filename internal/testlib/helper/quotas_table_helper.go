@@ -0,0 +1,56 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	infraDynamodb "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/infrastructure/persistence"
+	platformDynamodb "github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"time"
+)
+
+type QuotasTableHelper struct {
+	ddbClient platformDynamodb.Client
+}
+
+func NewQuotasTableHelper(client platformDynamodb.Client) (*QuotasTableHelper, error) {
+	return &QuotasTableHelper{
+		ddbClient: client,
+	}, nil
+}
+
+func (q *QuotasTableHelper) CreateQuotasTable() error {
+	ctx := context.Background()
+	table := aws.String(infraDynamodb.QuotasTableName)
+
+	_, err := q.ddbClient.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName: table,
+		AttributeDefinitions: []ddbtypes.AttributeDefinition{
+			{AttributeName: aws.String(infraDynamodb.QuotaUserIdAttrName), AttributeType: ddbtypes.ScalarAttributeTypeS},
+			{AttributeName: aws.String(infraDynamodb.QuotaDayUnixTimestampAttrName), AttributeType: ddbtypes.ScalarAttributeTypeN},
+		},
+		KeySchema: []ddbtypes.KeySchemaElement{
+			{AttributeName: aws.String(infraDynamodb.QuotaUserIdAttrName), KeyType: ddbtypes.KeyTypeHash},
+			{AttributeName: aws.String(infraDynamodb.QuotaDayUnixTimestampAttrName), KeyType: ddbtypes.KeyTypeRange},
+		},
+		BillingMode: ddbtypes.BillingModePayPerRequest,
+	})
+
+	var condCheckErr *ddbtypes.ResourceInUseException
+	if err != nil && !errors.As(err, &condCheckErr) {
+		return err
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		out, err := q.ddbClient.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: table})
+		if err == nil && out.Table != nil && out.Table.TableStatus == ddbtypes.TableStatusActive {
+			return nil
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	return fmt.Errorf("table %s not ACTIVE in time", *table)
+}
@@ -32,12 +32,62 @@ func (c *RomancesTableHelper) CreateRomancesTable() error {
 		AttributeDefinitions: []ddbtypes.AttributeDefinition{
 			{AttributeName: aws.String(infraDynamodb.PkUserIdAttrName), AttributeType: ddbtypes.ScalarAttributeTypeS},
 			{AttributeName: aws.String(infraDynamodb.SkUserIdAttrName), AttributeType: ddbtypes.ScalarAttributeTypeS},
+			{AttributeName: aws.String(infraDynamodb.MatchedAtAttrName), AttributeType: ddbtypes.ScalarAttributeTypeN},
+			{AttributeName: aws.String(infraDynamodb.PkUserLikedAtAttrName), AttributeType: ddbtypes.ScalarAttributeTypeN},
+			{AttributeName: aws.String(infraDynamodb.SkUserLikedAtAttrName), AttributeType: ddbtypes.ScalarAttributeTypeN},
 		},
 		KeySchema: []ddbtypes.KeySchemaElement{
 			{AttributeName: aws.String(infraDynamodb.PkUserIdAttrName), KeyType: ddbtypes.KeyTypeHash},
 			{AttributeName: aws.String(infraDynamodb.SkUserIdAttrName), KeyType: ddbtypes.KeyTypeRange},
 		},
+		GlobalSecondaryIndexes: []ddbtypes.GlobalSecondaryIndex{
+			{
+				IndexName: aws.String(infraDynamodb.RomancesByMaxMinUserIndexName),
+				KeySchema: []ddbtypes.KeySchemaElement{
+					{AttributeName: aws.String(infraDynamodb.SkUserIdAttrName), KeyType: ddbtypes.KeyTypeHash},
+					{AttributeName: aws.String(infraDynamodb.PkUserIdAttrName), KeyType: ddbtypes.KeyTypeRange},
+				},
+				Projection: &ddbtypes.Projection{ProjectionType: ddbtypes.ProjectionTypeKeysOnly},
+			},
+			{
+				IndexName: aws.String(infraDynamodb.RomancesMatchesByPkUserIndexName),
+				KeySchema: []ddbtypes.KeySchemaElement{
+					{AttributeName: aws.String(infraDynamodb.PkUserIdAttrName), KeyType: ddbtypes.KeyTypeHash},
+					{AttributeName: aws.String(infraDynamodb.MatchedAtAttrName), KeyType: ddbtypes.KeyTypeRange},
+				},
+				Projection: &ddbtypes.Projection{ProjectionType: ddbtypes.ProjectionTypeAll},
+			},
+			{
+				IndexName: aws.String(infraDynamodb.RomancesMatchesBySkUserIndexName),
+				KeySchema: []ddbtypes.KeySchemaElement{
+					{AttributeName: aws.String(infraDynamodb.SkUserIdAttrName), KeyType: ddbtypes.KeyTypeHash},
+					{AttributeName: aws.String(infraDynamodb.MatchedAtAttrName), KeyType: ddbtypes.KeyTypeRange},
+				},
+				Projection: &ddbtypes.Projection{ProjectionType: ddbtypes.ProjectionTypeAll},
+			},
+			{
+				IndexName: aws.String(infraDynamodb.RomancesLikesByPkUserIndexName),
+				KeySchema: []ddbtypes.KeySchemaElement{
+					{AttributeName: aws.String(infraDynamodb.PkUserIdAttrName), KeyType: ddbtypes.KeyTypeHash},
+					{AttributeName: aws.String(infraDynamodb.PkUserLikedAtAttrName), KeyType: ddbtypes.KeyTypeRange},
+				},
+				Projection: &ddbtypes.Projection{ProjectionType: ddbtypes.ProjectionTypeAll},
+			},
+			{
+				IndexName: aws.String(infraDynamodb.RomancesLikesBySkUserIndexName),
+				KeySchema: []ddbtypes.KeySchemaElement{
+					{AttributeName: aws.String(infraDynamodb.SkUserIdAttrName), KeyType: ddbtypes.KeyTypeHash},
+					{AttributeName: aws.String(infraDynamodb.SkUserLikedAtAttrName), KeyType: ddbtypes.KeyTypeRange},
+				},
+				Projection: &ddbtypes.Projection{ProjectionType: ddbtypes.ProjectionTypeAll},
+			},
+		},
 		BillingMode: ddbtypes.BillingModePayPerRequest,
+		// the counters can be derived from the stream of the romances
+		StreamSpecification: &ddbtypes.StreamSpecification{
+			StreamEnabled:  aws.Bool(true),
+			StreamViewType: ddbtypes.StreamViewTypeNewAndOldImages,
+		},
 	})
 
 	var condCheckErr *ddbtypes.ResourceInUseException
@@ -85,3 +135,26 @@ func (c *RomancesTableHelper) GetRomanceTableRecord(
 	}
 	return *romanceItem, nil
 }
+
+// RemoveRomanceIndexedAttrs makes the record look like one written before the matches and likes indexes were added.
+func (c *RomancesTableHelper) RemoveRomanceIndexedAttrs(
+	romanceKey infraDynamodb.RomancePrimaryKey,
+	dynamoDbRegion string,
+) error {
+	_, err := c.ddbClient.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		Key: map[string]ddbtypes.AttributeValue{
+			infraDynamodb.PkUserIdAttrName: &ddbtypes.AttributeValueMemberS{Value: romanceKey.Pk.String()},
+			infraDynamodb.SkUserIdAttrName: &ddbtypes.AttributeValueMemberS{Value: romanceKey.Sk.String()},
+		},
+		TableName:        aws.String(infraDynamodb.RomancesTableName),
+		UpdateExpression: aws.String("REMOVE #matchedAt, #pkUserLikedAt, #skUserLikedAt"),
+		ExpressionAttributeNames: map[string]string{
+			"#matchedAt":     infraDynamodb.MatchedAtAttrName,
+			"#pkUserLikedAt": infraDynamodb.PkUserLikedAtAttrName,
+			"#skUserLikedAt": infraDynamodb.SkUserLikedAtAttrName,
+		},
+	}, func(o *dynamodb.Options) {
+		o.Region = dynamoDbRegion
+	})
+	return err
+}
@@ -16,6 +16,7 @@ import (
 	entity "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/entity"
 	valueobject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/valueobject"
 	valueobject0 "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	uuid "github.com/google/uuid"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -43,6 +44,56 @@ func (m *MockCountersRepository) EXPECT() *MockCountersRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ApplyVoteCountersChange mocks base method.
+func (m *MockCountersRepository) ApplyVoteCountersChange(ctx context.Context, changeId uuid.UUID, voteId valueobject0.VoteId, countersChanges []valueobject.VoteCountersChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyVoteCountersChange", ctx, changeId, voteId, countersChanges)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApplyVoteCountersChange indicates an expected call of ApplyVoteCountersChange.
+func (mr *MockCountersRepositoryMockRecorder) ApplyVoteCountersChange(ctx, changeId, voteId, countersChanges any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyVoteCountersChange", reflect.TypeOf((*MockCountersRepository)(nil).ApplyVoteCountersChange), ctx, changeId, voteId, countersChanges)
+}
+
+// ChangeVoteCounters mocks base method.
+func (m *MockCountersRepository) ChangeVoteCounters(ctx context.Context, voteId valueobject0.VoteId, countersChange valueobject.VoteCountersChange) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ChangeVoteCounters", ctx, voteId, countersChange)
+}
+
+// ChangeVoteCounters indicates an expected call of ChangeVoteCounters.
+func (mr *MockCountersRepositoryMockRecorder) ChangeVoteCounters(ctx, voteId, countersChange any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeVoteCounters", reflect.TypeOf((*MockCountersRepository)(nil).ChangeVoteCounters), ctx, voteId, countersChange)
+}
+
+// DecrNoCounters mocks base method.
+func (m *MockCountersRepository) DecrNoCounters(ctx context.Context, voteId valueobject0.VoteId, counterGroup valueobject.CounterUpdateGroup) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DecrNoCounters", ctx, voteId, counterGroup)
+}
+
+// DecrNoCounters indicates an expected call of DecrNoCounters.
+func (mr *MockCountersRepositoryMockRecorder) DecrNoCounters(ctx, voteId, counterGroup any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecrNoCounters", reflect.TypeOf((*MockCountersRepository)(nil).DecrNoCounters), ctx, voteId, counterGroup)
+}
+
+// DecrYesCounters mocks base method.
+func (m *MockCountersRepository) DecrYesCounters(ctx context.Context, voteId valueobject0.VoteId, counterGroup valueobject.CounterUpdateGroup) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DecrYesCounters", ctx, voteId, counterGroup)
+}
+
+// DecrYesCounters indicates an expected call of DecrYesCounters.
+func (mr *MockCountersRepositoryMockRecorder) DecrYesCounters(ctx, voteId, counterGroup any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecrYesCounters", reflect.TypeOf((*MockCountersRepository)(nil).DecrYesCounters), ctx, voteId, counterGroup)
+}
+
 // GetHourlyCounters mocks base method.
 func (m *MockCountersRepository) GetHourlyCounters(ctx context.Context, activeUserKey valueobject0.ActiveUserKey, hoursOffsetGroups valueobject.HoursOffsetGroups) (map[uint8]*entity.CountersGroup, error) {
 	m.ctrl.T.Helper()
@@ -58,6 +109,21 @@ func (mr *MockCountersRepositoryMockRecorder) GetHourlyCounters(ctx, activeUserK
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHourlyCounters", reflect.TypeOf((*MockCountersRepository)(nil).GetHourlyCounters), ctx, activeUserKey, hoursOffsetGroups)
 }
 
+// GetHourlyCountersSeries mocks base method.
+func (m *MockCountersRepository) GetHourlyCountersSeries(ctx context.Context, activeUserKey valueobject0.ActiveUserKey, hoursCount uint8) ([]entity.CountersGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHourlyCountersSeries", ctx, activeUserKey, hoursCount)
+	ret0, _ := ret[0].([]entity.CountersGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHourlyCountersSeries indicates an expected call of GetHourlyCountersSeries.
+func (mr *MockCountersRepositoryMockRecorder) GetHourlyCountersSeries(ctx, activeUserKey, hoursCount any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHourlyCountersSeries", reflect.TypeOf((*MockCountersRepository)(nil).GetHourlyCountersSeries), ctx, activeUserKey, hoursCount)
+}
+
 // GetLifetimeCounter mocks base method.
 func (m *MockCountersRepository) GetLifetimeCounter(ctx context.Context, activeUserKey valueobject0.ActiveUserKey) (entity.CountersGroup, error) {
 	m.ctrl.T.Helper()
@@ -73,6 +139,21 @@ func (mr *MockCountersRepositoryMockRecorder) GetLifetimeCounter(ctx, activeUser
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLifetimeCounter", reflect.TypeOf((*MockCountersRepository)(nil).GetLifetimeCounter), ctx, activeUserKey)
 }
 
+// GetPeriodCounters mocks base method.
+func (m *MockCountersRepository) GetPeriodCounters(ctx context.Context, activeUserKey valueobject0.ActiveUserKey, period valueobject.CountersPeriod, periodsCount uint16) ([]entity.PeriodCounters, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPeriodCounters", ctx, activeUserKey, period, periodsCount)
+	ret0, _ := ret[0].([]entity.PeriodCounters)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPeriodCounters indicates an expected call of GetPeriodCounters.
+func (mr *MockCountersRepositoryMockRecorder) GetPeriodCounters(ctx, activeUserKey, period, periodsCount any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPeriodCounters", reflect.TypeOf((*MockCountersRepository)(nil).GetPeriodCounters), ctx, activeUserKey, period, periodsCount)
+}
+
 // IncrNoCounters mocks base method.
 func (m *MockCountersRepository) IncrNoCounters(ctx context.Context, voteId valueobject0.VoteId, counterGroup valueobject.CounterUpdateGroup) {
 	m.ctrl.T.Helper()
@@ -96,3 +177,41 @@ func (mr *MockCountersRepositoryMockRecorder) IncrYesCounters(ctx, voteId, count
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrYesCounters", reflect.TypeOf((*MockCountersRepository)(nil).IncrYesCounters), ctx, voteId, counterGroup)
 }
+
+// ReplaceLifetimeCounter mocks base method.
+func (m *MockCountersRepository) ReplaceLifetimeCounter(ctx context.Context, current, reconciled entity.CountersGroup) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceLifetimeCounter", ctx, current, reconciled)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceLifetimeCounter indicates an expected call of ReplaceLifetimeCounter.
+func (mr *MockCountersRepositoryMockRecorder) ReplaceLifetimeCounter(ctx, current, reconciled any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceLifetimeCounter", reflect.TypeOf((*MockCountersRepository)(nil).ReplaceLifetimeCounter), ctx, current, reconciled)
+}
+
+// TransferNoToYesCounters mocks base method.
+func (m *MockCountersRepository) TransferNoToYesCounters(ctx context.Context, voteId valueobject0.VoteId, counterGroup valueobject.CounterUpdateGroup) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "TransferNoToYesCounters", ctx, voteId, counterGroup)
+}
+
+// TransferNoToYesCounters indicates an expected call of TransferNoToYesCounters.
+func (mr *MockCountersRepositoryMockRecorder) TransferNoToYesCounters(ctx, voteId, counterGroup any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferNoToYesCounters", reflect.TypeOf((*MockCountersRepository)(nil).TransferNoToYesCounters), ctx, voteId, counterGroup)
+}
+
+// TransferYesToNoCounters mocks base method.
+func (m *MockCountersRepository) TransferYesToNoCounters(ctx context.Context, voteId valueobject0.VoteId, counterGroup valueobject.CounterUpdateGroup) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "TransferYesToNoCounters", ctx, voteId, counterGroup)
+}
+
+// TransferYesToNoCounters indicates an expected call of TransferYesToNoCounters.
+func (mr *MockCountersRepositoryMockRecorder) TransferYesToNoCounters(ctx, voteId, counterGroup any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferYesToNoCounters", reflect.TypeOf((*MockCountersRepository)(nil).TransferYesToNoCounters), ctx, voteId, counterGroup)
+}
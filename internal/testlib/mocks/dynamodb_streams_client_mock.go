@@ -0,0 +1,102 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform/dynamodb (interfaces: StreamsClient)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../../../testlib/mocks/dynamodb_streams_client_mock.go -package=mocks github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform/dynamodb StreamsClient
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dynamodbstreams "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockStreamsClient is a mock of StreamsClient interface.
+type MockStreamsClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockStreamsClientMockRecorder
+	isgomock struct{}
+}
+
+// MockStreamsClientMockRecorder is the mock recorder for MockStreamsClient.
+type MockStreamsClientMockRecorder struct {
+	mock *MockStreamsClient
+}
+
+// NewMockStreamsClient creates a new mock instance.
+func NewMockStreamsClient(ctrl *gomock.Controller) *MockStreamsClient {
+	mock := &MockStreamsClient{ctrl: ctrl}
+	mock.recorder = &MockStreamsClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStreamsClient) EXPECT() *MockStreamsClientMockRecorder {
+	return m.recorder
+}
+
+// DescribeStream mocks base method.
+func (m *MockStreamsClient) DescribeStream(ctx context.Context, params *dynamodbstreams.DescribeStreamInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.DescribeStreamOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DescribeStream", varargs...)
+	ret0, _ := ret[0].(*dynamodbstreams.DescribeStreamOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeStream indicates an expected call of DescribeStream.
+func (mr *MockStreamsClientMockRecorder) DescribeStream(ctx, params any, optFns ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeStream", reflect.TypeOf((*MockStreamsClient)(nil).DescribeStream), varargs...)
+}
+
+// GetRecords mocks base method.
+func (m *MockStreamsClient) GetRecords(ctx context.Context, params *dynamodbstreams.GetRecordsInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetRecordsOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetRecords", varargs...)
+	ret0, _ := ret[0].(*dynamodbstreams.GetRecordsOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecords indicates an expected call of GetRecords.
+func (mr *MockStreamsClientMockRecorder) GetRecords(ctx, params any, optFns ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecords", reflect.TypeOf((*MockStreamsClient)(nil).GetRecords), varargs...)
+}
+
+// GetShardIterator mocks base method.
+func (m *MockStreamsClient) GetShardIterator(ctx context.Context, params *dynamodbstreams.GetShardIteratorInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetShardIteratorOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetShardIterator", varargs...)
+	ret0, _ := ret[0].(*dynamodbstreams.GetShardIteratorOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShardIterator indicates an expected call of GetShardIterator.
+func (mr *MockStreamsClientMockRecorder) GetShardIterator(ctx, params any, optFns ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShardIterator", reflect.TypeOf((*MockStreamsClient)(nil).GetShardIterator), varargs...)
+}
@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform (interfaces: Metrics)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../../testlib/mocks/metrics_mock.go -package=mocks github.bumble.dev/shcherbanich/user-votes-storage/internal/shared/platform Metrics
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockMetrics is a mock of Metrics interface.
+type MockMetrics struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricsMockRecorder
+	isgomock struct{}
+}
+
+// MockMetricsMockRecorder is the mock recorder for MockMetrics.
+type MockMetricsMockRecorder struct {
+	mock *MockMetrics
+}
+
+// NewMockMetrics creates a new mock instance.
+func NewMockMetrics(ctrl *gomock.Controller) *MockMetrics {
+	mock := &MockMetrics{ctrl: ctrl}
+	mock.recorder = &MockMetricsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetrics) EXPECT() *MockMetricsMockRecorder {
+	return m.recorder
+}
+
+// ObserveDuration mocks base method.
+func (m *MockMetrics) ObserveDuration(name string, duration time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ObserveDuration", name, duration)
+}
+
+// ObserveDuration indicates an expected call of ObserveDuration.
+func (mr *MockMetricsMockRecorder) ObserveDuration(name, duration any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ObserveDuration", reflect.TypeOf((*MockMetrics)(nil).ObserveDuration), name, duration)
+}
+
+// SetGauge mocks base method.
+func (m *MockMetrics) SetGauge(name string, value float64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetGauge", name, value)
+}
+
+// SetGauge indicates an expected call of SetGauge.
+func (mr *MockMetricsMockRecorder) SetGauge(name, value any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetGauge", reflect.TypeOf((*MockMetrics)(nil).SetGauge), name, value)
+}
@@ -0,0 +1,59 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota/repository (interfaces: QuotasRepository)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../../../../../testlib/mocks/quotas_repository_mock.go -package=mocks github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota/repository QuotasRepository
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	valueobject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota/valueobject"
+	valueobject0 "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
+	valueobject1 "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockQuotasRepository is a mock of QuotasRepository interface.
+type MockQuotasRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockQuotasRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockQuotasRepositoryMockRecorder is the mock recorder for MockQuotasRepository.
+type MockQuotasRepositoryMockRecorder struct {
+	mock *MockQuotasRepository
+}
+
+// NewMockQuotasRepository creates a new mock instance.
+func NewMockQuotasRepository(ctrl *gomock.Controller) *MockQuotasRepository {
+	mock := &MockQuotasRepository{ctrl: ctrl}
+	mock.recorder = &MockQuotasRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockQuotasRepository) EXPECT() *MockQuotasRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetDailyUsage mocks base method.
+func (m *MockQuotasRepository) GetDailyUsage(ctx context.Context, activeUserKey valueobject1.ActiveUserKey, day valueobject.QuotaDay) (map[valueobject0.VoteType]uint32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDailyUsage", ctx, activeUserKey, day)
+	ret0, _ := ret[0].(map[valueobject0.VoteType]uint32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDailyUsage indicates an expected call of GetDailyUsage.
+func (mr *MockQuotasRepositoryMockRecorder) GetDailyUsage(ctx, activeUserKey, day any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDailyUsage", reflect.TypeOf((*MockQuotasRepository)(nil).GetDailyUsage), ctx, activeUserKey, day)
+}
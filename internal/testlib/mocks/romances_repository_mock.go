@@ -14,9 +14,12 @@ import (
 	reflect "reflect"
 	time "time"
 
+	valueobject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/counter/valueobject"
+	valueobject0 "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/quota/valueobject"
 	entity "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/entity"
-	valueobject "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
-	valueobject0 "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	valueobject1 "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/romance/valueobject"
+	valueobject2 "github.bumble.dev/shcherbanich/user-votes-storage/internal/context/voting/domain/sharedkernel/valueobject"
+	uuid "github.com/google/uuid"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -45,33 +48,93 @@ func (m *MockRomancesRepository) EXPECT() *MockRomancesRepositoryMockRecorder {
 }
 
 // AddActiveUserVoteToRomance mocks base method.
-func (m *MockRomancesRepository) AddActiveUserVoteToRomance(ctx context.Context, romance entity.Romance, voteType valueobject.VoteType, votedAt time.Time) (entity.Romance, error) {
+func (m *MockRomancesRepository) AddActiveUserVoteToRomance(ctx context.Context, romance entity.Romance, voteType valueobject1.VoteType, message valueobject1.ComplimentMessage, votedAt time.Time) (entity.Romance, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddActiveUserVoteToRomance", ctx, romance, voteType, votedAt)
+	ret := m.ctrl.Call(m, "AddActiveUserVoteToRomance", ctx, romance, voteType, message, votedAt)
 	ret0, _ := ret[0].(entity.Romance)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AddActiveUserVoteToRomance indicates an expected call of AddActiveUserVoteToRomance.
-func (mr *MockRomancesRepositoryMockRecorder) AddActiveUserVoteToRomance(ctx, romance, voteType, votedAt any) *gomock.Call {
+func (mr *MockRomancesRepositoryMockRecorder) AddActiveUserVoteToRomance(ctx, romance, voteType, message, votedAt any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddActiveUserVoteToRomance", reflect.TypeOf((*MockRomancesRepository)(nil).AddActiveUserVoteToRomance), ctx, romance, voteType, votedAt)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddActiveUserVoteToRomance", reflect.TypeOf((*MockRomancesRepository)(nil).AddActiveUserVoteToRomance), ctx, romance, voteType, message, votedAt)
+}
+
+// AddActiveUserVoteToRomanceWithCounters mocks base method.
+func (m *MockRomancesRepository) AddActiveUserVoteToRomanceWithCounters(ctx context.Context, romance entity.Romance, voteType valueobject1.VoteType, message valueobject1.ComplimentMessage, votedAt time.Time, countersChangeId uuid.UUID, countersChanges []valueobject.VoteCountersChange, quotaConsumption *valueobject0.QuotaConsumption) (entity.Romance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddActiveUserVoteToRomanceWithCounters", ctx, romance, voteType, message, votedAt, countersChangeId, countersChanges, quotaConsumption)
+	ret0, _ := ret[0].(entity.Romance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddActiveUserVoteToRomanceWithCounters indicates an expected call of AddActiveUserVoteToRomanceWithCounters.
+func (mr *MockRomancesRepositoryMockRecorder) AddActiveUserVoteToRomanceWithCounters(ctx, romance, voteType, message, votedAt, countersChangeId, countersChanges, quotaConsumption any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddActiveUserVoteToRomanceWithCounters", reflect.TypeOf((*MockRomancesRepository)(nil).AddActiveUserVoteToRomanceWithCounters), ctx, romance, voteType, message, votedAt, countersChangeId, countersChanges, quotaConsumption)
+}
+
+// BackfillRomanceIndexes mocks base method.
+func (m *MockRomancesRepository) BackfillRomanceIndexes(ctx context.Context, countryId uint16, segment, totalSegments int32, pageRequest valueobject2.PageRequest) (entity.RomancesBackfillPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BackfillRomanceIndexes", ctx, countryId, segment, totalSegments, pageRequest)
+	ret0, _ := ret[0].(entity.RomancesBackfillPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BackfillRomanceIndexes indicates an expected call of BackfillRomanceIndexes.
+func (mr *MockRomancesRepositoryMockRecorder) BackfillRomanceIndexes(ctx, countryId, segment, totalSegments, pageRequest any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BackfillRomanceIndexes", reflect.TypeOf((*MockRomancesRepository)(nil).BackfillRomanceIndexes), ctx, countryId, segment, totalSegments, pageRequest)
+}
+
+// BlockRomance mocks base method.
+func (m *MockRomancesRepository) BlockRomance(ctx context.Context, romance entity.Romance) (entity.Romance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockRomance", ctx, romance)
+	ret0, _ := ret[0].(entity.Romance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockRomance indicates an expected call of BlockRomance.
+func (mr *MockRomancesRepositoryMockRecorder) BlockRomance(ctx, romance any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockRomance", reflect.TypeOf((*MockRomancesRepository)(nil).BlockRomance), ctx, romance)
 }
 
 // ChangeActiveUserVoteTypeInRomance mocks base method.
-func (m *MockRomancesRepository) ChangeActiveUserVoteTypeInRomance(ctx context.Context, romance entity.Romance, newVoteType valueobject.VoteType) (entity.Romance, error) {
+func (m *MockRomancesRepository) ChangeActiveUserVoteTypeInRomance(ctx context.Context, romance entity.Romance, newVoteType valueobject1.VoteType, message valueobject1.ComplimentMessage) (entity.Romance, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ChangeActiveUserVoteTypeInRomance", ctx, romance, newVoteType)
+	ret := m.ctrl.Call(m, "ChangeActiveUserVoteTypeInRomance", ctx, romance, newVoteType, message)
 	ret0, _ := ret[0].(entity.Romance)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ChangeActiveUserVoteTypeInRomance indicates an expected call of ChangeActiveUserVoteTypeInRomance.
-func (mr *MockRomancesRepositoryMockRecorder) ChangeActiveUserVoteTypeInRomance(ctx, romance, newVoteType any) *gomock.Call {
+func (mr *MockRomancesRepositoryMockRecorder) ChangeActiveUserVoteTypeInRomance(ctx, romance, newVoteType, message any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeActiveUserVoteTypeInRomance", reflect.TypeOf((*MockRomancesRepository)(nil).ChangeActiveUserVoteTypeInRomance), ctx, romance, newVoteType)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeActiveUserVoteTypeInRomance", reflect.TypeOf((*MockRomancesRepository)(nil).ChangeActiveUserVoteTypeInRomance), ctx, romance, newVoteType, message)
+}
+
+// ChangeActiveUserVoteTypeInRomanceWithCounters mocks base method.
+func (m *MockRomancesRepository) ChangeActiveUserVoteTypeInRomanceWithCounters(ctx context.Context, romance entity.Romance, newVoteType valueobject1.VoteType, message valueobject1.ComplimentMessage, countersChangeId uuid.UUID, countersChanges []valueobject.VoteCountersChange, quotaConsumption *valueobject0.QuotaConsumption) (entity.Romance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeActiveUserVoteTypeInRomanceWithCounters", ctx, romance, newVoteType, message, countersChangeId, countersChanges, quotaConsumption)
+	ret0, _ := ret[0].(entity.Romance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeActiveUserVoteTypeInRomanceWithCounters indicates an expected call of ChangeActiveUserVoteTypeInRomanceWithCounters.
+func (mr *MockRomancesRepositoryMockRecorder) ChangeActiveUserVoteTypeInRomanceWithCounters(ctx, romance, newVoteType, message, countersChangeId, countersChanges, quotaConsumption any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeActiveUserVoteTypeInRomanceWithCounters", reflect.TypeOf((*MockRomancesRepository)(nil).ChangeActiveUserVoteTypeInRomanceWithCounters), ctx, romance, newVoteType, message, countersChangeId, countersChanges, quotaConsumption)
 }
 
 // DeleteActiveUserVoteFromRomance mocks base method.
@@ -88,8 +151,22 @@ func (mr *MockRomancesRepositoryMockRecorder) DeleteActiveUserVoteFromRomance(ct
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteActiveUserVoteFromRomance", reflect.TypeOf((*MockRomancesRepository)(nil).DeleteActiveUserVoteFromRomance), ctx, romance)
 }
 
+// DeleteActiveUserVoteFromRomanceWithCounters mocks base method.
+func (m *MockRomancesRepository) DeleteActiveUserVoteFromRomanceWithCounters(ctx context.Context, romance entity.Romance, countersChangeId uuid.UUID, countersChanges []valueobject.VoteCountersChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteActiveUserVoteFromRomanceWithCounters", ctx, romance, countersChangeId, countersChanges)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteActiveUserVoteFromRomanceWithCounters indicates an expected call of DeleteActiveUserVoteFromRomanceWithCounters.
+func (mr *MockRomancesRepositoryMockRecorder) DeleteActiveUserVoteFromRomanceWithCounters(ctx, romance, countersChangeId, countersChanges any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteActiveUserVoteFromRomanceWithCounters", reflect.TypeOf((*MockRomancesRepository)(nil).DeleteActiveUserVoteFromRomanceWithCounters), ctx, romance, countersChangeId, countersChanges)
+}
+
 // DeleteRomance mocks base method.
-func (m *MockRomancesRepository) DeleteRomance(ctx context.Context, voteId valueobject0.VoteId) error {
+func (m *MockRomancesRepository) DeleteRomance(ctx context.Context, voteId valueobject2.VoteId) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DeleteRomance", ctx, voteId)
 	ret0, _ := ret[0].(error)
@@ -102,8 +179,22 @@ func (mr *MockRomancesRepositoryMockRecorder) DeleteRomance(ctx, voteId any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRomance", reflect.TypeOf((*MockRomancesRepository)(nil).DeleteRomance), ctx, voteId)
 }
 
+// DeleteUserRomances mocks base method.
+func (m *MockRomancesRepository) DeleteUserRomances(ctx context.Context, activeUserKey valueobject2.ActiveUserKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserRomances", ctx, activeUserKey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserRomances indicates an expected call of DeleteUserRomances.
+func (mr *MockRomancesRepositoryMockRecorder) DeleteUserRomances(ctx, activeUserKey any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserRomances", reflect.TypeOf((*MockRomancesRepository)(nil).DeleteUserRomances), ctx, activeUserKey)
+}
+
 // GetRomance mocks base method.
-func (m *MockRomancesRepository) GetRomance(ctx context.Context, voteId valueobject0.VoteId) (entity.Romance, error) {
+func (m *MockRomancesRepository) GetRomance(ctx context.Context, voteId valueobject2.VoteId) (entity.Romance, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetRomance", ctx, voteId)
 	ret0, _ := ret[0].(entity.Romance)
@@ -116,3 +207,93 @@ func (mr *MockRomancesRepositoryMockRecorder) GetRomance(ctx, voteId any) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRomance", reflect.TypeOf((*MockRomancesRepository)(nil).GetRomance), ctx, voteId)
 }
+
+// GetRomances mocks base method.
+func (m *MockRomancesRepository) GetRomances(ctx context.Context, voteIds []valueobject2.VoteId) ([]entity.Romance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRomances", ctx, voteIds)
+	ret0, _ := ret[0].([]entity.Romance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRomances indicates an expected call of GetRomances.
+func (mr *MockRomancesRepositoryMockRecorder) GetRomances(ctx, voteIds any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRomances", reflect.TypeOf((*MockRomancesRepository)(nil).GetRomances), ctx, voteIds)
+}
+
+// ListUserLikes mocks base method.
+func (m *MockRomancesRepository) ListUserLikes(ctx context.Context, activeUserKey valueobject2.ActiveUserKey, peerVoteTypes []valueobject1.VoteType, pageRequest valueobject2.PageRequest) (entity.RomancesPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserLikes", ctx, activeUserKey, peerVoteTypes, pageRequest)
+	ret0, _ := ret[0].(entity.RomancesPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserLikes indicates an expected call of ListUserLikes.
+func (mr *MockRomancesRepositoryMockRecorder) ListUserLikes(ctx, activeUserKey, peerVoteTypes, pageRequest any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserLikes", reflect.TypeOf((*MockRomancesRepository)(nil).ListUserLikes), ctx, activeUserKey, peerVoteTypes, pageRequest)
+}
+
+// ListUserMatches mocks base method.
+func (m *MockRomancesRepository) ListUserMatches(ctx context.Context, activeUserKey valueobject2.ActiveUserKey, pageRequest valueobject2.PageRequest) (entity.RomancesPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserMatches", ctx, activeUserKey, pageRequest)
+	ret0, _ := ret[0].(entity.RomancesPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserMatches indicates an expected call of ListUserMatches.
+func (mr *MockRomancesRepositoryMockRecorder) ListUserMatches(ctx, activeUserKey, pageRequest any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserMatches", reflect.TypeOf((*MockRomancesRepository)(nil).ListUserMatches), ctx, activeUserKey, pageRequest)
+}
+
+// ListUserRomances mocks base method.
+func (m *MockRomancesRepository) ListUserRomances(ctx context.Context, activeUserKey valueobject2.ActiveUserKey, pageRequest valueobject2.PageRequest) (entity.RomancesPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserRomances", ctx, activeUserKey, pageRequest)
+	ret0, _ := ret[0].(entity.RomancesPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserRomances indicates an expected call of ListUserRomances.
+func (mr *MockRomancesRepositoryMockRecorder) ListUserRomances(ctx, activeUserKey, pageRequest any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserRomances", reflect.TypeOf((*MockRomancesRepository)(nil).ListUserRomances), ctx, activeUserKey, pageRequest)
+}
+
+// ScanRomanceUsers mocks base method.
+func (m *MockRomancesRepository) ScanRomanceUsers(ctx context.Context, countryId uint16, segment, totalSegments int32, pageRequest valueobject2.PageRequest) (entity.RomanceUsersPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanRomanceUsers", ctx, countryId, segment, totalSegments, pageRequest)
+	ret0, _ := ret[0].(entity.RomanceUsersPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScanRomanceUsers indicates an expected call of ScanRomanceUsers.
+func (mr *MockRomancesRepositoryMockRecorder) ScanRomanceUsers(ctx, countryId, segment, totalSegments, pageRequest any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanRomanceUsers", reflect.TypeOf((*MockRomancesRepository)(nil).ScanRomanceUsers), ctx, countryId, segment, totalSegments, pageRequest)
+}
+
+// UnblockRomance mocks base method.
+func (m *MockRomancesRepository) UnblockRomance(ctx context.Context, romance entity.Romance) (entity.Romance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnblockRomance", ctx, romance)
+	ret0, _ := ret[0].(entity.Romance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnblockRomance indicates an expected call of UnblockRomance.
+func (mr *MockRomancesRepositoryMockRecorder) UnblockRomance(ctx, romance any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnblockRomance", reflect.TypeOf((*MockRomancesRepository)(nil).UnblockRomance), ctx, romance)
+}
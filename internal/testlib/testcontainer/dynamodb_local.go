@@ -8,14 +8,16 @@ import (
 
 	awsConfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
 	"github.com/testcontainers/testcontainers-go"
 	dynamodbTestcontainer "github.com/testcontainers/testcontainers-go/modules/dynamodb"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
 type DynamoDbLocal struct {
-	Container *dynamodbTestcontainer.DynamoDBContainer
-	Client    *dynamodb.Client
+	Container     *dynamodbTestcontainer.DynamoDBContainer
+	Client        *dynamodb.Client
+	StreamsClient *dynamodbstreams.Client
 }
 
 func SetupDynamoDbLocal(ctx context.Context, region string) (*DynamoDbLocal, error) {
@@ -65,8 +67,9 @@ func SetupDynamoDbLocal(ctx context.Context, region string) (*DynamoDbLocal, err
 		}
 
 		dynamoDbLocal = &DynamoDbLocal{
-			Container: ddbContainer,
-			Client:    dynamodb.NewFromConfig(cfg),
+			Container:     ddbContainer,
+			Client:        dynamodb.NewFromConfig(cfg),
+			StreamsClient: dynamodbstreams.NewFromConfig(cfg),
 		}
 	})
 
@@ -53,6 +53,7 @@ cf_output_any() {
 # 1) Read outputs from DataStack (primary stream)
 COUNTERS_NAME="$(cf_output_any "$STACK_DATA" CountersTableName)"
 ROMANCES_NAME="$(cf_output_any "$STACK_DATA" RomancesTableName)"
+QUOTAS_NAME="$(cf_output_any "$STACK_DATA" QuotasTableName)"
 
 TOPIC_ARN="$(cf_output_any "$STACK_DATA" DeleteRomancesFifoTopicArn DeleteRomancesTopicArn)"
 QUEUE_ARN="$(cf_output_any "$STACK_DATA" DeleteRomancesFifoQueueArn DeleteRomancesQueueArn)"
@@ -68,6 +69,7 @@ PREFIX="/${APP}/${ENV}"
 
 [[ -n "$COUNTERS_NAME" ]]      && put_param "$PREFIX/ddb/counters/name" "$COUNTERS_NAME"
 [[ -n "$ROMANCES_NAME" ]]      && put_param "$PREFIX/ddb/romances/name" "$ROMANCES_NAME"
+[[ -n "$QUOTAS_NAME" ]]        && put_param "$PREFIX/ddb/quotas/name" "$QUOTAS_NAME"
 
 [[ -n "$TOPIC_ARN" ]]          && put_param "$PREFIX/sns/delete-romances/arn" "$TOPIC_ARN"
 [[ -n "$QUEUE_ARN" ]]          && put_param "$PREFIX/sqs/delete-romances/arn" "$QUEUE_ARN"